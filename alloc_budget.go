@@ -0,0 +1,65 @@
+package fiberhandler
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"runtime/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const heapAllocsMetric = "/gc/heap/allocs:bytes"
+
+const defaultAllocationSampleRate = 0.01
+
+// AllocationBudget configures WithAllocationBudget.
+type AllocationBudget struct {
+	// Bytes is the heap allocation allowed per doFunc call.
+	Bytes uint64
+
+	// SampleRate is the fraction of requests measured, default 0.01.
+	SampleRate float64
+
+	// OnExceeded is called for measured requests above the budget, e.g. to count them in metrics.
+	// Requests above the budget are always logged.
+	OnExceeded func(c *fiber.Ctx, allocated uint64)
+}
+
+// WithAllocationBudget is experimental. It samples the heap allocation of the process around doFunc and
+// reports routes allocating more than the budget, to find the endpoints responsible for GC pressure. The
+// counter is process wide, so allocations of concurrent requests are included and a single report is
+// an upper bound; look for routes that exceed the budget repeatedly.
+func WithAllocationBudget(budget AllocationBudget) Option {
+	if budget.SampleRate <= 0 {
+		budget.SampleRate = defaultAllocationSampleRate
+	}
+	return func(o *options) {
+		o.allocationBudget = &budget
+	}
+}
+
+// measure runs fn and reports it when the sampled allocation exceeds the budget.
+func (b *AllocationBudget) measure(c *fiber.Ctx, fn func()) {
+	if b.SampleRate < 1 && rand.Float64() >= b.SampleRate {
+		fn()
+		return
+	}
+
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	before := sample[0].Value.Uint64()
+
+	fn()
+
+	metrics.Read(sample)
+	allocated := sample[0].Value.Uint64() - before
+	if allocated <= b.Bytes {
+		return
+	}
+
+	path := c.Route().Path
+	slog.Warn("Request exceeded the allocation budget", slog.String("method", c.Method()), slog.String("path", path), slog.Uint64("allocated", allocated), slog.Uint64("budget", b.Bytes))
+	if b.OnExceeded != nil {
+		b.OnExceeded(c, allocated)
+	}
+}
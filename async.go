@@ -0,0 +1,298 @@
+package fiberhandler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/goerror"
+)
+
+const (
+	jobPrefix             = "fiberhandler:job:"
+	defaultJobWorkers     = 4
+	defaultJobQueueSize   = 100
+	defaultJobTTL         = 24 * time.Hour
+	defaultJobStatusPath  = "/jobs"
+	defaultJobRetryAfter  = time.Second
+	defaultJobPollSeconds = "1"
+)
+
+// JobStatus is the state of an async job.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is the record of a doFunc run by DoAsync, returned by the status handler.
+type Job struct {
+	ID        string          `json:"id"`
+	Status    JobStatus       `json:"status"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *JobError       `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// JobError is the error of a failed job.
+type JobError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+// JobQueueConfig configures NewJobQueue.
+type JobQueueConfig struct {
+	// Workers is the number of jobs running concurrently, default 4.
+	Workers int
+
+	// QueueSize is the number of jobs waiting for a worker, default 100. DoAsync answers 503 with
+	// Retry-After once the queue is full.
+	QueueSize int
+
+	// Store keeps the job records, default an in-memory store. Records expire after TTL, default 24h.
+	Store Store
+	TTL   time.Duration
+
+	// StatusPath is the route of the status handler, the Location of a job is StatusPath/ID. Default /jobs.
+	StatusPath string
+}
+
+// JobQueue runs the doFuncs of DoAsync on a pool of workers and records their outcome.
+type JobQueue struct {
+	config JobQueueConfig
+	jobs   chan func()
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewJobQueue starts the workers of a JobQueue, stop them with Close.
+func NewJobQueue(config ...JobQueueConfig) *JobQueue {
+	cfg := JobQueueConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultJobWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultJobQueueSize
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultJobTTL
+	}
+	if cfg.StatusPath == "" {
+		cfg.StatusPath = defaultJobStatusPath
+	}
+
+	queue := &JobQueue{config: cfg, jobs: make(chan func(), cfg.QueueSize)}
+	for range cfg.Workers {
+		queue.wg.Add(1)
+		go func() {
+			defer queue.wg.Done()
+			for run := range queue.jobs {
+				run()
+			}
+		}()
+	}
+	return queue
+}
+
+// WithJobQueue runs the doFuncs of DoAsync on queue, it panics on a nil queue.
+func WithJobQueue(queue *JobQueue) Option {
+	if queue == nil {
+		panic("fiberhandler: WithJobQueue requires a queue, see NewJobQueue")
+	}
+	return func(o *options) {
+		o.jobQueue = queue
+	}
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish.
+func (q *JobQueue) Close() {
+	q.once.Do(func() {
+		close(q.jobs)
+	})
+	q.wg.Wait()
+}
+
+// Get returns the job with id, ErrKeyNotFound when it does not exist or has expired.
+func (q *JobQueue) Get(ctx context.Context, id string) (*Job, error) {
+	value, err := q.config.Store.Get(ctx, jobPrefix+id)
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{}
+	if err := json.Unmarshal(value, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// StatusHandler returns the handler polled by clients for the job identified by the route parameter
+// param, default "id". Pending and running jobs are sent with Retry-After, finished jobs with their
+// result or error:
+//
+//	app.Get("/jobs/:id", queue.StatusHandler(handle))
+func (q *JobQueue) StatusHandler(h ApiHandler, param ...string) fiber.Handler {
+	name := "id"
+	if len(param) > 0 {
+		name = param[0]
+	}
+	return func(c *fiber.Ctx) error {
+		id := c.Params(name)
+		return h.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			job, err := q.Get(ctx, id)
+			if errors.Is(err, ErrKeyNotFound) {
+				return nil, &ResponseError{Body: goerror.Body{Message: "Job not found"}, Status: http.StatusNotFound}
+			}
+			if err != nil {
+				slog.Error("Failed to read job", slog.String("job", id), slog.String("error", err.Error()))
+				return nil, NewInternalError()
+			}
+			if job.Status == JobPending || job.Status == JobRunning {
+				return OK(job).WithHeader(fiber.HeaderRetryAfter, defaultJobPollSeconds), nil
+			}
+			return job, nil
+		})
+	}
+}
+
+func (q *JobQueue) save(ctx context.Context, job *Job) error {
+	job.UpdatedAt = time.Now()
+	value, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return q.config.Store.Set(ctx, jobPrefix+job.ID, value, q.config.TTL)
+}
+
+// submit records a pending job and queues doFunc, it fails with an UnavailableError when the queue is
+// full.
+func (q *JobQueue) submit(ctx context.Context, doFunc DoFunc) (job *Job, err error) {
+	id, err := newJobID()
+	if err != nil {
+		slog.Error("Failed to submit job", slog.String("error", err.Error()))
+		return nil, NewInternalError()
+	}
+	now := time.Now()
+	job = &Job{ID: id, Status: JobPending, CreatedAt: now}
+	if err := q.save(ctx, job); err != nil {
+		slog.Error("Failed to save job", slog.String("job", id), slog.String("error", err.Error()))
+		return nil, NewInternalError()
+	}
+
+	// The job outlives the request, it keeps the claims and request values of the context
+	jobCtx := context.WithoutCancel(ctx)
+	defer func() {
+		// Sending on a closed queue panics
+		if recover() != nil {
+			_ = q.config.Store.Delete(ctx, jobPrefix+id)
+			job, err = nil, NewUnavailableError(defaultJobRetryAfter)
+		}
+	}()
+	select {
+	case q.jobs <- func() { q.run(jobCtx, *job, doFunc) }:
+		return job, nil
+	default:
+		_ = q.config.Store.Delete(ctx, jobPrefix+id)
+		return nil, NewUnavailableError(defaultJobRetryAfter)
+	}
+}
+
+func (q *JobQueue) run(ctx context.Context, job Job, doFunc DoFunc) {
+	job.Status = JobRunning
+	if err := q.save(ctx, &job); err != nil {
+		slog.Error("Failed to save job", slog.String("job", job.ID), slog.String("error", err.Error()))
+	}
+
+	data, err := func() (data any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Recovered from panic in job", slog.String("job", job.ID), slog.Any("panic", r), slog.String("stack", string(debug.Stack())))
+				err = NewInternalError()
+			}
+		}()
+		return doFunc(ctx)
+	}()
+
+	if err == nil {
+		job.Result, err = json.Marshal(data)
+	}
+	if err != nil {
+		job.Status = JobFailed
+		job.Result = nil
+		job.Error = newJobError(err)
+	} else {
+		job.Status = JobSucceeded
+	}
+	if err := q.save(ctx, &job); err != nil {
+		slog.Error("Failed to save job", slog.String("job", job.ID), slog.String("error", err.Error()))
+	}
+}
+
+// newJobError keeps the code and message of errors with a status, other errors are logged and recorded
+// as internal errors.
+func newJobError(err error) *JobError {
+	err = translateDownstreamError(err)
+	var statusErr StatusCoder
+	if !errors.As(err, &statusErr) {
+		slog.Error("Job failed", slog.String("error", err.Error()))
+		statusErr = NewInternalError().(StatusCoder)
+	}
+
+	var body goerror.Body
+	if encoded, marshalErr := json.Marshal(statusErr); marshalErr == nil {
+		_ = json.Unmarshal(encoded, &body)
+	}
+	return &JobError{Code: body.Code, Message: body.Message, Status: statusErr.StatusCode()}
+}
+
+func newJobID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// DoAsync runs the pipeline of Do, then queues doFunc on the JobQueue of WithJobQueue and answers 202
+// Accepted with the pending job and its Location. doFunc runs with a context keeping the claims of the
+// request but not its cancellation, it must not use the *fiber.Ctx which is recycled once the
+// response is sent. Clients poll the status handler for its result. Job IDs are random, the status
+// handler does not check the caller. A handler without WithJobQueue answers 500, the misconfiguration
+// is logged.
+func (h *apiHandler[T]) DoAsync(c *fiber.Ctx, requestPtr any, validateRequest bool, doFunc DoFunc) error {
+	queue := h.options.jobQueue
+	if queue == nil {
+		slog.Error("DoAsync: no job queue, use WithJobQueue")
+		return h.SendError(c, NewInternalError())
+	}
+
+	return h.Do(c, requestPtr, validateRequest, func(ctx context.Context) (any, error) {
+		job, err := queue.submit(ctx, doFunc)
+		if err != nil {
+			return nil, err
+		}
+		location := joinPath(queue.config.StatusPath, job.ID)
+		return Accepted(job).
+			WithHeader(fiber.HeaderLocation, location).
+			WithHeader(fiber.HeaderRetryAfter, defaultJobPollSeconds), nil
+	})
+}
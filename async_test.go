@@ -0,0 +1,62 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+func TestDoAsync(t *testing.T) {
+	queue := fiberhandler.NewJobQueue()
+	defer queue.Close()
+	handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{}, fiberhandler.WithJobQueue(queue))
+
+	app := fiber.New()
+	app.Post("/reports", func(c *fiber.Ctx) error {
+		return handle.DoAsync(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			return profile{Sub: "42"}, nil
+		})
+	})
+	app.Get("/jobs/:id", queue.StatusHandler(handle))
+
+	response := fiberhandlertest.Post("/reports").JSON(struct{}{}).Do(t, app).Status(http.StatusAccepted)
+	location := response.Header.Get(fiber.HeaderLocation)
+	if location == "" {
+		t.Fatal("missing Location")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var job fiberhandler.Job
+		fiberhandlertest.Get(location).Do(t, app).Status(http.StatusOK).Data(&job)
+		if job.Status == fiberhandler.JobSucceeded {
+			if string(job.Result) != `{"sub":"42"}` {
+				t.Errorf("result = %s", job.Result)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job still %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	fiberhandlertest.Get("/jobs/unknown").Do(t, app).Status(http.StatusNotFound)
+}
+
+func TestDoAsyncWithoutQueue(t *testing.T) {
+	handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{})
+	fiberhandlertest.Post("/reports").JSON(struct{}{}).
+		Run(t, func(c *fiber.Ctx) error {
+			return handle.DoAsync(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+				t.Error("doFunc ran without a queue")
+				return nil, nil
+			})
+		}).
+		Status(http.StatusInternalServerError)
+}
@@ -0,0 +1,129 @@
+package fiberhandler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Outcomes reported by AuditEntry.
+const (
+	AuditSuccess = "success"
+	AuditDenied  = "denied"
+	AuditFailure = "failure"
+)
+
+// AuditEntry records a handled mutating request for the audit trail.
+type AuditEntry struct {
+	// Actor names the caller, empty for anonymous requests.
+	Actor string `json:"actor,omitempty"`
+
+	// Action is the operation of the route, see Emits, e.g. created, updated or deleted.
+	Action string `json:"action"`
+
+	// Resource is the entity and ID of routes declaring Emits, the request path otherwise.
+	Resource string `json:"resource"`
+
+	// Outcome is AuditSuccess, AuditDenied for 401 and 403, or AuditFailure.
+	Outcome string `json:"outcome"`
+
+	Method     string        `json:"method"`
+	Route      string        `json:"route"`
+	Status     int           `json:"status"`
+	RequestID  string        `json:"request_id,omitempty"`
+	Latency    time.Duration `json:"latency"`
+	OccurredAt time.Time     `json:"occurred_at"`
+
+	// Err is the error sent to the client, nil on success.
+	Err error `json:"-"`
+}
+
+// AuditHook receives an entry after every mutating request, e.g. to write a compliance audit trail.
+// It is called before the handler returns, slow sinks should queue the entries.
+type AuditHook interface {
+	Audit(ctx context.Context, entry AuditEntry)
+}
+
+// AuditHookFunc adapts a function to AuditHook.
+type AuditHookFunc func(ctx context.Context, entry AuditEntry)
+
+// Audit implements AuditHook.
+func (f AuditHookFunc) Audit(ctx context.Context, entry AuditEntry) {
+	f(ctx, entry)
+}
+
+type auditor struct {
+	hook  AuditHook
+	actor func(ctx context.Context) string
+}
+
+// WithAuditHook reports every POST, PUT, PATCH and DELETE request to hook. actor names the caller from
+// its claims, it is not called for anonymous requests.
+func WithAuditHook[T any](hook AuditHook, actor func(claims *T) string) Option {
+	audit := &auditor{
+		hook: hook,
+		actor: func(ctx context.Context) string {
+			claims, ok := ClaimsFromContext[T](ctx)
+			if !ok || actor == nil {
+				return ""
+			}
+			return actor(claims)
+		},
+	}
+
+	return func(o *options) {
+		o.auditor = audit
+	}
+}
+
+// audit starts timing a mutating request and returns the function reporting it.
+func (h *apiHandler[T]) audit(c *fiber.Ctx) func() {
+	audit := h.options.auditor
+	if audit == nil {
+		return func() {}
+	}
+	switch c.Method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		ctx := c.UserContext()
+		status := c.Response().StatusCode()
+		entry := AuditEntry{
+			Actor:      audit.actor(ctx),
+			Action:     operationOf(c.Method()),
+			Resource:   c.Path(),
+			Outcome:    AuditSuccess,
+			Method:     c.Method(),
+			Route:      c.Route().Path,
+			Status:     status,
+			Latency:    time.Since(start),
+			OccurredAt: time.Now().UTC(),
+		}
+		entry.RequestID, _ = RequestIDFromContext(ctx)
+		entry.Err, _ = c.Locals(sentErrorKey{}).(error)
+
+		if route := CurrentRoute(c); route != nil && route.Event != nil {
+			entry.Action = route.Event.Operation
+			entry.Resource = route.Event.Entity
+			if route.Event.IDParam != "" {
+				if id := c.Params(route.Event.IDParam); id != "" {
+					entry.Resource += "/" + id
+				}
+			}
+		}
+
+		switch {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			entry.Outcome = AuditDenied
+		case status >= http.StatusBadRequest || entry.Err != nil:
+			entry.Outcome = AuditFailure
+		}
+
+		audit.hook.Audit(ctx, entry)
+	}
+}
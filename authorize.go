@@ -0,0 +1,69 @@
+package fiberhandler
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WithAuthorizer enforces the scopes, roles and policies declared on Registry routes. authorize reports
+// whether claims satisfy route; protected routes reject requests without claims with 401 and denied
+// requests with 403.
+func WithAuthorizer[T any](authorize func(claims *T, route *Route) bool) Option {
+	return func(o *options) {
+		o.authorizer = func(claims any, route *Route) bool {
+			c, _ := claims.(*T)
+			return authorize(c, route)
+		}
+	}
+}
+
+// WithRequireAuth rejects anonymous requests with 401 before doFunc runs, unless their registry route
+// is declared Anonymous. Derive a handler with it for the route groups requiring a caller:
+//
+//	private := handle.With(fiberhandler.WithRequireAuth())
+//	fiberhandler.Get(app, "/me", private, users.Me)
+func WithRequireAuth() Option {
+	return func(o *options) {
+		o.requireAuth = true
+	}
+}
+
+// requiresAuth reports whether route rejects anonymous requests.
+func (h *apiHandler[T]) requiresAuth(route *Route) bool {
+	if route == nil {
+		return h.options.requireAuth
+	}
+	switch route.Auth {
+	case AuthRequired:
+		return true
+	case AuthAnonymous:
+		return false
+	}
+	return h.options.requireAuth
+}
+
+func (h *apiHandler[T]) authorize(c *fiber.Ctx, claims *T) error {
+	route := CurrentRoute(c)
+	if claims == nil && h.requiresAuth(route) {
+		return NewUnauthorizedError()
+	}
+	if route == nil || !route.Protected() {
+		return nil
+	}
+
+	if claims == nil {
+		return NewUnauthorizedError()
+	}
+
+	// Fail closed when requirements are declared but nothing enforces them
+	if h.options.authorizer == nil {
+		slog.Error("Route declares authorization requirements without an authorizer", slog.String("method", route.Method), slog.String("path", route.Path))
+		return NewForbiddenError()
+	}
+
+	if !h.options.authorizer(claims, route) {
+		return NewForbiddenError()
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package fiberhandler
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// minThrottleChunk is the smallest read of a throttled stream, small rates are paced per chunk.
+const minThrottleChunk = 1024
+
+// BandwidthConfig configures WithBandwidthLimit, in bytes per second.
+type BandwidthConfig struct {
+	// PerConnection limits every streamed response.
+	PerConnection int64
+
+	// Total limits the streamed responses of the handler together, unlimited when zero.
+	Total int64
+}
+
+type bandwidth struct {
+	config BandwidthConfig
+	total  *byteLimiter
+}
+
+// WithBandwidthLimit paces the files and exports sent by sendStream, so large downloads cannot saturate
+// the egress of the service or starve the other requests:
+//
+//	fiberhandler.WithBandwidthLimit(fiberhandler.BandwidthConfig{PerConnection: 2 << 20, Total: 50 << 20})
+//
+// Compressed streams are paced before compression. Other responses are not limited.
+func WithBandwidthLimit(config BandwidthConfig) Option {
+	limit := &bandwidth{config: config}
+	if config.Total > 0 {
+		limit.total = newByteLimiter(config.Total)
+	}
+
+	return func(o *options) {
+		o.bandwidth = limit
+	}
+}
+
+// throttle returns the result with its stream paced, keeping its io.Seeker and io.Closer.
+func (b *bandwidth) throttle(result *StreamResult) *StreamResult {
+	var limiters []*byteLimiter
+	if b.config.PerConnection > 0 {
+		limiters = append(limiters, newByteLimiter(b.config.PerConnection))
+	}
+	if b.total != nil {
+		limiters = append(limiters, b.total)
+	}
+	if len(limiters) == 0 {
+		return result
+	}
+
+	reader := &throttledReader{Reader: result.Data, limiters: limiters}
+	stream := *result.Stream
+	stream.Data = reader
+	if seeker, ok := result.Data.(io.ReadSeeker); ok {
+		stream.Data = &throttledReadSeeker{throttledReader: reader, seeker: seeker}
+	}
+	throttled := *result
+	throttled.Stream = &stream
+	return &throttled
+}
+
+// byteLimiter is a token bucket of bytes holding up to one second of its rate.
+type byteLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newByteLimiter(bytesPerSecond int64) *byteLimiter {
+	return &byteLimiter{rate: float64(bytesPerSecond), tokens: float64(bytesPerSecond), last: time.Now()}
+}
+
+// chunk is the largest read paced at once, a twentieth of a second.
+func (l *byteLimiter) chunk() int {
+	return max(int(l.rate/20), minThrottleChunk)
+}
+
+// wait takes n bytes from the bucket, sleeping until they are available. Readers sharing the bucket
+// queue behind the bytes already taken.
+func (l *byteLimiter) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+type throttledReader struct {
+	io.Reader
+	limiters []*byteLimiter
+}
+
+// Read implements io.Reader.
+func (r *throttledReader) Read(p []byte) (int, error) {
+	size := len(p)
+	for _, limiter := range r.limiters {
+		size = min(size, limiter.chunk())
+	}
+	n, err := r.Reader.Read(p[:size])
+	if n > 0 {
+		for _, limiter := range r.limiters {
+			limiter.wait(n)
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer, closing the stream when it is one.
+func (r *throttledReader) Close() error {
+	if closer, ok := r.Reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+type throttledReadSeeker struct {
+	*throttledReader
+	seeker io.Seeker
+}
+
+// Seek implements io.Seeker.
+func (r *throttledReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.seeker.Seek(offset, whence)
+}
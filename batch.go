@@ -0,0 +1,127 @@
+package fiberhandler
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultBatchLimit is the maximum number of sub-requests of a batch, see WithBatchLimit.
+const DefaultBatchLimit = 20
+
+// BatchRequest is the body of a batch, {"requests": [...]}.
+type BatchRequest struct {
+	Requests []BatchItem `json:"requests" validate:"required,min=1,dive"`
+}
+
+// BatchItem is a sub-request of a batch. It carries the headers of the batch request, such as the
+// Authorization header, overridden by Headers.
+type BatchItem struct {
+	// ID is echoed in the result of the sub-request, default its index.
+	ID      string            `json:"id,omitempty"`
+	Method  string            `json:"method" validate:"required"`
+	Path    string            `json:"path" validate:"required,startswith=/"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResult is the response to a sub-request, its body is the JSON sent by the route, e.g. the error
+// envelope of a failed sub-request.
+type BatchResult struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// batchItemKey marks the fasthttp context of a sub-request, so a batch cannot dispatch another one.
+type batchItemKey struct{}
+
+// WithBatchLimit caps the number of sub-requests of a batch, DefaultBatchLimit by default.
+func WithBatchLimit(limit int) Option {
+	return func(o *options) {
+		o.batchLimit = limit
+	}
+}
+
+// DoBatch runs the batch request through the pipeline of Do, then dispatches each sub-request in order
+// through the routes of the app and sends their results in one response, so clients save round trips:
+//
+//	app.Post("/batch", handle.DoBatch)
+//
+// Sub-requests are authenticated and authorized by their own route, a failed sub-request does not stop
+// the others. Sub-requests may not be batches themselves.
+func (h *apiHandler[T]) DoBatch(c *fiber.Ctx) error {
+	if c.Context().UserValue(batchItemKey{}) != nil {
+		return h.SendError(c, NewBadRequestError("Nested batch requests are not allowed"))
+	}
+
+	batch := &BatchRequest{}
+	return h.Do(c, batch, true, func(ctx context.Context) (any, error) {
+		limit := h.options.batchLimit
+		if limit <= 0 {
+			limit = DefaultBatchLimit
+		}
+		if len(batch.Requests) > limit {
+			return nil, NewBadRequestError("Too many requests in batch, the limit is " + strconv.Itoa(limit))
+		}
+
+		serve := c.App().Handler()
+		results := make([]BatchResult, len(batch.Requests))
+		for i, item := range batch.Requests {
+			if item.ID == "" {
+				item.ID = strconv.Itoa(i)
+			}
+			results[i] = dispatchBatchItem(c, serve, item)
+		}
+		return results, nil
+	})
+}
+
+func dispatchBatchItem(c *fiber.Ctx, serve fasthttp.RequestHandler, item BatchItem) BatchResult {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	c.Request().Header.CopyTo(&req.Header)
+	req.Header.Del(fiber.HeaderContentType)
+	req.Header.Del(fiber.HeaderContentLength)
+	req.Header.SetMethod(strings.ToUpper(item.Method))
+	req.SetRequestURI(item.Path)
+	if len(item.Body) > 0 {
+		req.Header.SetContentType(fiber.MIMEApplicationJSON)
+		req.SetBody(item.Body)
+	}
+	for key, value := range item.Headers {
+		req.Header.Set(key, value)
+	}
+
+	var fctx fasthttp.RequestCtx
+	fctx.Init(req, c.Context().RemoteAddr(), nil)
+	fctx.SetUserValue(batchItemKey{}, true)
+	serve(&fctx)
+
+	result := BatchResult{ID: item.ID, Status: fctx.Response.StatusCode()}
+	fctx.Response.Header.VisitAll(func(key, value []byte) {
+		if string(key) == fiber.HeaderContentLength {
+			return
+		}
+		if result.Headers == nil {
+			result.Headers = map[string]string{}
+		}
+		result.Headers[string(key)] = string(value)
+	})
+
+	body := fctx.Response.Body()
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		result.Body = append(json.RawMessage(nil), body...)
+	default:
+		result.Body, _ = json.Marshal(string(body))
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Bindable is implemented by request structs that bind themselves. BindRequest replaces the default
+// body, query and multipart binding entirely, validation and the rest of the pipeline still run.
+// Errors implementing StatusCoder are sent as is, any other error is answered with 400.
+type Bindable interface {
+	BindRequest(c *fiber.Ctx) error
+}
+
+// Computed is implemented by request structs deriving fields from the bound values, e.g. normalizing a
+// slug or hashing an uploaded file. AfterBind runs after binding and before validation, its error is
+// sent as is.
+type Computed interface {
+	AfterBind(ctx context.Context) error
+}
+
+func (h *apiHandler[T]) bindRequest(c *fiber.Ctx, bindable Bindable) error {
+	err := bindable.BindRequest(c)
+	if err == nil {
+		return nil
+	}
+
+	slog.Error("Invalid request", h.redactor().errorAttr(err))
+	var statusErr StatusCoder
+	if errors.As(err, &statusErr) {
+		return err
+	}
+	return NewBadRequestError()
+}
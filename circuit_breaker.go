@@ -0,0 +1,154 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultBreakerThreshold   = 5
+	defaultBreakerOpenTimeout = 30 * time.Second
+)
+
+// CircuitBreaker guards the doFunc of a route, keyed by method and route path. Allow reports whether a
+// call may run, or how long the circuit stays open. done is called with the error of an allowed call.
+type CircuitBreaker interface {
+	Allow(key string) (done func(err error), retryAfter time.Duration, ok bool)
+}
+
+// CircuitBreakerConfig configures NewCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// Threshold is the number of consecutive failures opening the circuit, default 5.
+	Threshold int
+
+	// OpenTimeout is how long an open circuit fast-fails before a trial call is let through, default 30s.
+	OpenTimeout time.Duration
+
+	// IsFailure reports whether the error of a call counts as a downstream failure. Default server
+	// errors: errors without a status or with a 5xx status, except the cancellation of a call.
+	IsFailure func(err error) bool
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type circuit struct {
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+type circuitBreaker struct {
+	config   CircuitBreakerConfig
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// NewCircuitBreaker returns the built-in CircuitBreaker: a route opens after Threshold consecutive
+// failures, fast-fails for OpenTimeout, then lets one trial call through which closes it on success
+// or opens it again on failure.
+func NewCircuitBreaker(config ...CircuitBreakerConfig) CircuitBreaker {
+	cfg := CircuitBreakerConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultBreakerThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = isServerFailure
+	}
+	return &circuitBreaker{config: cfg, circuits: map[string]*circuit{}}
+}
+
+// WithCircuitBreaker fast-fails the calls of doFunc with an UnavailableError (503) carrying Retry-After
+// while the circuit of the route is open.
+func WithCircuitBreaker(breaker CircuitBreaker) Option {
+	return func(o *options) {
+		o.circuitBreaker = breaker
+	}
+}
+
+// Allow implements CircuitBreaker.
+func (b *circuitBreaker) Allow(key string) (func(err error), time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.circuits[key]
+	if !ok {
+		state = &circuit{}
+		b.circuits[key] = state
+	}
+
+	switch state.state {
+	case breakerOpen:
+		remaining := b.config.OpenTimeout - time.Since(state.openedAt)
+		if remaining > 0 {
+			return nil, remaining, false
+		}
+		state.state = breakerHalfOpen
+	case breakerHalfOpen:
+		// A trial call is running
+		return nil, b.config.OpenTimeout, false
+	}
+
+	return func(err error) {
+		b.record(state, err)
+	}, 0, true
+}
+
+func (b *circuitBreaker) record(state *circuit, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.config.IsFailure(err) {
+		state.state = breakerClosed
+		state.failures = 0
+		return
+	}
+
+	state.failures++
+	if state.state == breakerHalfOpen || state.failures >= b.config.Threshold {
+		state.state = breakerOpen
+		state.openedAt = time.Now()
+	}
+}
+
+func isServerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrClientDisconnected) {
+		return false
+	}
+	var statusErr StatusCoder
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode() >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// allowCall asks the circuit breaker of the handler whether doFunc may run, the returned function
+// records the outcome of the call.
+func (h *apiHandler[T]) allowCall(c *fiber.Ctx) (func(err error), error) {
+	breaker := h.options.circuitBreaker
+	if breaker == nil {
+		return func(error) {}, nil
+	}
+
+	done, retryAfter, ok := breaker.Allow(c.Method() + " " + c.Route().Path)
+	if !ok {
+		return nil, NewUnavailableError(retryAfter)
+	}
+	return done, nil
+}
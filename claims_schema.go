@@ -0,0 +1,108 @@
+package fiberhandler
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// numericDateClaims are registered JWT claims holding seconds since the epoch.
+var numericDateClaims = map[string]bool{"exp": true, "iat": true, "nbf": true}
+
+// ValidateClaims checks that the claims type T can be decoded from a JWT payload: every exported field
+// has a json tag, there are no unexported fields that would silently stay empty, and exp/iat/nbf are
+// numeric. Call it at startup, a mis-tagged claims type otherwise surfaces as nil claims at runtime.
+func ValidateClaims[T any]() error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("claims type %s: map keys must be strings", t)
+		}
+		return nil
+	default:
+		return fmt.Errorf("claims type %s: must be a struct or a map with string keys", t)
+	}
+
+	var problems []error
+	validateClaimsStruct(t, t.Name(), &problems)
+	return errors.Join(problems...)
+}
+
+// MustValidateClaims panics when ValidateClaims fails.
+func MustValidateClaims[T any]() {
+	if err := ValidateClaims[T](); err != nil {
+		panic(err)
+	}
+}
+
+func validateClaimsStruct(t reflect.Type, path string, problems *[]error) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath := path + "." + field.Name
+		tag, hasTag := field.Tag.Lookup("json")
+		name, _, _ := strings.Cut(tag, ",")
+
+		if name == "-" {
+			continue
+		}
+
+		// Untagged embedded structs are flattened into the payload
+		if field.Anonymous && !hasTag {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				validateClaimsStruct(embedded, fieldPath, problems)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			*problems = append(*problems, fmt.Errorf("%s: unexported field is never decoded from the token, export it with a json tag or remove it", fieldPath))
+			continue
+		}
+
+		if !hasTag || name == "" {
+			*problems = append(*problems, fmt.Errorf("%s: missing json tag, add `json:\"%s\"` with the claim name used in the token", fieldPath, strings.ToLower(field.Name)))
+			continue
+		}
+
+		if numericDateClaims[name] && !isNumericDate(field.Type) {
+			*problems = append(*problems, fmt.Errorf("%s: claim %q holds seconds since the epoch, use int64, float64 or *jwt.NumericDate instead of %s", fieldPath, name, field.Type))
+		}
+	}
+}
+
+func isNumericDate(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || t.Implements(jsonUnmarshalerType) {
+		// time.Time decodes RFC 3339 strings only
+		return t.String() != "time.Time" && t.String() != "*time.Time"
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package fiberhandler
+
+import (
+	stdjson "encoding/json"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// JSONCodec encodes and decodes JSON. sonic.ConfigStd and sonic.ConfigDefault implement it, as do
+// StdJSON and GoccyJSON.
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error)      { return stdjson.Marshal(v) }
+func (stdCodec) Unmarshal(data []byte, v any) error { return stdjson.Unmarshal(data, v) }
+
+type goccyCodec struct{}
+
+func (goccyCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (goccyCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+var (
+	// StdJSON is the JSONCodec of encoding/json.
+	StdJSON JSONCodec = stdCodec{}
+
+	// GoccyJSON is the JSONCodec of goccy/go-json, used by fiber and the handler by default.
+	GoccyJSON JSONCodec = goccyCodec{}
+)
+
+// WithJSONCodec decodes JSON request bodies and encodes the data of responses with codec instead of
+// the JSON encoder and decoder of the fiber app, so deployments can pick and benchmark codecs without
+// forking. The envelope around the data and the errors keep the encoder of the app.
+func WithJSONCodec(codec JSONCodec) Option {
+	return func(o *options) {
+		o.jsonCodec = codec
+	}
+}
+
+// isJSON reports whether the request body is JSON, e.g. application/json or application/problem+json.
+func isJSON(c *fiber.Ctx) bool {
+	contentType, _, _ := strings.Cut(string(c.Request().Header.ContentType()), ";")
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(contentType)), "json")
+}
+
+// decodeBody binds the request body, a JSON body with the codec of the handler.
+func (h *apiHandler[T]) decodeBody(c *fiber.Ctx, requestPtr any) error {
+	if h.options.jsonCodec == nil || !isJSON(c) {
+		return c.BodyParser(requestPtr)
+	}
+	return h.options.jsonCodec.Unmarshal(c.Body(), requestPtr)
+}
+
+// encodeData encodes the data of a response with the codec of the handler, the result is embedded as is
+// by the encoder of the app.
+func (h *apiHandler[T]) encodeData(data any) (any, error) {
+	if h.options.jsonCodec == nil || data == nil {
+		return data, nil
+	}
+	if _, ok := data.(json.RawMessage); ok {
+		return data, nil
+	}
+	encoded, err := h.options.jsonCodec.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(encoded), nil
+}
+
+// marshalJSON encodes data with the codec of the handler, or the JSON encoder of the fiber app.
+func (h *apiHandler[T]) marshalJSON(c *fiber.Ctx, data any) ([]byte, error) {
+	if h.options.jsonCodec != nil {
+		return h.options.jsonCodec.Marshal(data)
+	}
+	return c.App().Config().JSONEncoder(data)
+}
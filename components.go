@@ -0,0 +1,95 @@
+package fiberhandler
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fibererror"
+)
+
+// Encoder writes the success envelope and the errors without status of the handler, fibererror.New()
+// implements it.
+type Encoder interface {
+	With(c *fiber.Ctx) fibererror.HttpResponse
+}
+
+// Validator validates bound requests, *validator.Validate implements it. Errors of type
+// validator.ValidationErrors are reported field by field.
+type Validator interface {
+	Struct(s any) error
+}
+
+// RequestParser binds a request into requestPtr. Its error is sent as is, a BadRequestError for
+// undecodable input.
+type RequestParser interface {
+	ParseRequest(c *fiber.Ctx, requestPtr any) error
+}
+
+// RequestParserFunc adapts a function to RequestParser.
+type RequestParserFunc func(c *fiber.Ctx, requestPtr any) error
+
+// ParseRequest implements RequestParser.
+func (f RequestParserFunc) ParseRequest(c *fiber.Ctx, requestPtr any) error {
+	return f(c, requestPtr)
+}
+
+// Authenticator returns the claims of the caller, nil for an anonymous request. An error is logged and
+// the request continues as anonymous, the authorization of the route decides whether it is rejected.
+// A TokenError, e.g. NewTokenError(ErrTokenExpired), rejects the request with 401.
+type Authenticator[T any] interface {
+	Authenticate(c *fiber.Ctx) (*T, error)
+}
+
+// AuthenticatorFunc adapts a function to Authenticator.
+type AuthenticatorFunc[T any] func(c *fiber.Ctx) (*T, error)
+
+// Authenticate implements Authenticator.
+func (f AuthenticatorFunc[T]) Authenticate(c *fiber.Ctx) (*T, error) {
+	return f(c)
+}
+
+// Components are the subsystems of a handler, so frameworks embedding fiberhandler can replace them
+// one by one. Unset components keep the defaults of New.
+type Components[T any] struct {
+	// Encoder defaults to fibererror.New().
+	Encoder Encoder
+
+	// Validator defaults to validator.New().
+	Validator Validator
+
+	// Parser defaults to the query for GET and DELETE requests, the body otherwise, or Bindable.
+	Parser RequestParser
+
+	// Authenticator defaults to the bearer token, the token form field of multipart requests or the
+	// token query parameter of WebSocket upgrades, parsed by TokenParser.
+	Authenticator Authenticator[T]
+
+	// TokenParser parses the token of the default Authenticator, default NewJWTParser.
+	TokenParser TokenParser[T]
+}
+
+// NewWithComponents returns a handler assembled from components with opts applied. Observers, audit
+// hooks and event publishers are registered with their options.
+func NewWithComponents[T any](components Components[T], opts ...Option) ApiHandler {
+	if components.Encoder == nil {
+		components.Encoder = fibererror.New()
+	}
+	if components.Validator == nil {
+		components.Validator = validator.New()
+	}
+	if components.TokenParser == nil {
+		components.TokenParser = NewJWTParser[T]()
+	}
+
+	handler := &apiHandler[T]{
+		Response:      components.Encoder,
+		Validate:      components.Validator,
+		TokenParser:   &components.TokenParser,
+		Parser:        components.Parser,
+		Authenticator: components.Authenticator,
+	}
+	for _, opt := range opts {
+		opt(&handler.options)
+	}
+	handler.options.dropPersonalizedCache()
+	return handler
+}
@@ -0,0 +1,157 @@
+package fiberhandler
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeadlineSource tells which party set the deadline of the doFunc context.
+type DeadlineSource string
+
+const (
+	// DeadlineServer is a deadline configured on the handler.
+	DeadlineServer DeadlineSource = "server"
+
+	// DeadlineClient is a deadline requested by the caller, e.g. a Request-Timeout header.
+	DeadlineClient DeadlineSource = "client"
+
+	// DeadlineUpstream is a deadline inherited from the context set by a middleware before the handler.
+	DeadlineUpstream DeadlineSource = "upstream"
+)
+
+// Context keys of the values the handler puts in the doFunc context. The accessors below are the stable
+// contract for libraries reading them, the keys themselves are unexported.
+type (
+	requestIDKey      struct{}
+	traceIDKey        struct{}
+	claimsKey         struct{}
+	tenantKey         struct{}
+	localeKey         struct{}
+	deadlineSourceKey struct{}
+	referenceKey      struct{}
+	fieldMaskKey      struct{}
+)
+
+// ContextWithRequestID returns ctx carrying the request ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the ID of the request, from the X-Request-ID header or the requestid middleware.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok
+}
+
+// ContextWithTraceID returns ctx carrying the trace ID, hex encoded.
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the hex encoded ID of the trace propagated in the W3C traceparent header.
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	traceID, ok := ctx.Value(traceIDKey{}).(string)
+	return traceID, ok
+}
+
+// ContextWithClaims returns ctx carrying the claims of the caller.
+func ContextWithClaims[T any](ctx context.Context, claims *T) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of an authenticated caller. With WithLazyClaims, the token is
+// parsed by the first call, a token failing to parse reports no claims and fails the request.
+func ClaimsFromContext[T any](ctx context.Context) (*T, bool) {
+	value := ctx.Value(claimsKey{})
+	if lazy, ok := value.(*lazyClaims[T]); ok {
+		claims, err := lazy.get(ctx)
+		return claims, claims != nil && err == nil
+	}
+	claims, ok := value.(*T)
+	return claims, ok && claims != nil
+}
+
+// ContextWithTenant returns ctx carrying the tenant of the request.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant of the request.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// ContextWithLocale returns ctx carrying the locale of the request, a BCP 47 language tag.
+func ContextWithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale of the request, a BCP 47 language tag.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey{}).(string)
+	return locale, ok
+}
+
+// ContextWithFieldMask returns ctx carrying the fields provided in a merge patch.
+func ContextWithFieldMask(ctx context.Context, mask FieldMask) context.Context {
+	return context.WithValue(ctx, fieldMaskKey{}, mask)
+}
+
+// FieldMaskFromContext returns the fields provided in the merge patch of DoPatch.
+func FieldMaskFromContext(ctx context.Context) (FieldMask, bool) {
+	mask, ok := ctx.Value(fieldMaskKey{}).(FieldMask)
+	return mask, ok
+}
+
+// ContextWithDeadlineSource returns ctx carrying the party that set its deadline.
+func ContextWithDeadlineSource(ctx context.Context, source DeadlineSource) context.Context {
+	return context.WithValue(ctx, deadlineSourceKey{}, source)
+}
+
+// DeadlineSourceFromContext returns the party that set the deadline of ctx. A deadline without a source
+// was inherited from a middleware.
+func DeadlineSourceFromContext(ctx context.Context) (DeadlineSource, bool) {
+	if _, ok := ctx.Deadline(); !ok {
+		return "", false
+	}
+	if source, ok := ctx.Value(deadlineSourceKey{}).(DeadlineSource); ok {
+		return source, true
+	}
+	return DeadlineUpstream, true
+}
+
+// bindContext puts the request values known before binding into the user context.
+func bindContext(c *fiber.Ctx) {
+	ctx := c.UserContext()
+
+	requestID := c.Get(fiber.HeaderXRequestID)
+	if requestID == "" {
+		requestID = c.GetRespHeader(fiber.HeaderXRequestID)
+	}
+	if requestID != "" {
+		ctx = ContextWithRequestID(ctx, requestID)
+	}
+
+	if traceID, ok := parseTraceParent(c.Get("traceparent")); ok {
+		ctx = ContextWithTraceID(ctx, traceID)
+	}
+
+	c.SetUserContext(ctx)
+}
+
+// parseTraceParent returns the trace ID of a W3C traceparent header, version-traceid-parentid-flags.
+func parseTraceParent(traceParent string) (string, bool) {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return "", false
+	}
+	traceID, err := hex.DecodeString(parts[1])
+	if err != nil || strings.Trim(parts[1], "0") == "" {
+		return "", false
+	}
+	return hex.EncodeToString(traceID), true
+}
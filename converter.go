@@ -0,0 +1,103 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// converters are the string converters registered with RegisterType, by type.
+var converters = struct {
+	sync.RWMutex
+	byType map[reflect.Type]func(string) (reflect.Value, error)
+	parser []fiber.ParserType
+}{byType: map[reflect.Type]func(string) (reflect.Value, error){}}
+
+// RegisterType registers the converter of V from the strings of query parameters, form fields and
+// multipart fields, e.g. uuid.Parse, decimal.NewFromString, a custom enum or TimeLayout. Slices of V are
+// converted element by element, a failing value is answered with a 400 naming its field.
+//
+// The query and form parsers of fiber are global, the types are registered for every app of the process.
+// Register them at startup, before serving requests.
+func RegisterType[V any](convert func(value string) (V, error)) {
+	converters.Lock()
+	defer converters.Unlock()
+
+	var zero V
+	t := reflect.TypeFor[V]()
+	converters.byType[t] = func(value string) (reflect.Value, error) {
+		v, err := convert(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(v), nil
+	}
+	converters.parser = append(converters.parser, fiber.ParserType{
+		Customtype: zero,
+		Converter: func(value string) reflect.Value {
+			v, err := convert(value)
+			if err != nil {
+				// An invalid value makes the parser report a ConversionError
+				return reflect.Value{}
+			}
+			return reflect.ValueOf(v)
+		},
+	})
+
+	// The defaults of fiber, which replaces them along with the types
+	fiber.SetParserDecoder(fiber.ParserConfig{
+		IgnoreUnknownKeys: true,
+		ZeroEmpty:         true,
+		ParserType:        converters.parser,
+	})
+}
+
+// TimeLayout returns a converter of time.Time trying layouts in order, default time.RFC3339 and
+// time.DateOnly:
+//
+//	fiberhandler.RegisterType(fiberhandler.TimeLayout(time.DateOnly, "02/01/2006"))
+func TimeLayout(layouts ...string) func(value string) (time.Time, error) {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339, time.DateOnly}
+	}
+	return func(value string) (time.Time, error) {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+}
+
+// converterOf returns the converter registered for t.
+func converterOf(t reflect.Type) (func(string) (reflect.Value, error), bool) {
+	converters.RLock()
+	defer converters.RUnlock()
+	convert, ok := converters.byType[t]
+	return convert, ok
+}
+
+// convertField sets the value of the multipart field pointed to by fieldPtr with its registered
+// converter, it reports false when its type has none.
+func convertField(value string, fieldPtr any) (bool, error) {
+	ptr := reflect.ValueOf(fieldPtr)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return false, nil
+	}
+
+	convert, ok := converterOf(ptr.Type().Elem())
+	if !ok {
+		return false, nil
+	}
+
+	converted, err := convert(value)
+	if err != nil {
+		return true, err
+	}
+	ptr.Elem().Set(converted)
+	return true, nil
+}
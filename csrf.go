@@ -0,0 +1,125 @@
+package fiberhandler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	DefaultAuthCookie = "access_token"
+	DefaultCSRFCookie = "csrf_token"
+	HeaderCSRFToken   = "X-CSRF-Token"
+)
+
+// CookieAuthConfig configures WithCookieAuth.
+type CookieAuthConfig struct {
+	// Cookie holds the token of browser sessions, default access_token.
+	Cookie string
+
+	// CSRFCookie and CSRFHeader carry the double-submit CSRF token, default csrf_token and X-CSRF-Token.
+	CSRFCookie string
+	CSRFHeader string
+
+	// DisableCSRF turns the CSRF validation off, e.g. when SameSite=Strict cookies are enough.
+	DisableCSRF bool
+}
+
+type cookieAuthKey struct{}
+
+func (config CookieAuthConfig) withDefaults() CookieAuthConfig {
+	if config.Cookie == "" {
+		config.Cookie = DefaultAuthCookie
+	}
+	if config.CSRFCookie == "" {
+		config.CSRFCookie = DefaultCSRFCookie
+	}
+	if config.CSRFHeader == "" {
+		config.CSRFHeader = HeaderCSRFToken
+	}
+	return config
+}
+
+// WithCookieAuth reads the token from a cookie for requests without Authorization header, e.g. from
+// browsers. Unsafe requests authenticated by the cookie must echo the CSRF cookie in the CSRF header,
+// others are rejected with 403. Safe requests authenticated by the cookie get a CSRF cookie when they
+// have none, IssueCSRFToken issues one explicitly, e.g. at login. Requests authenticated by the cookie
+// of Sessions are validated the same way.
+func WithCookieAuth(config ...CookieAuthConfig) Option {
+	cfg := CookieAuthConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	cfg = cfg.withDefaults()
+	return func(o *options) {
+		o.cookieAuth = &cfg
+	}
+}
+
+// IssueCSRFToken sets a new CSRF cookie readable by scripts and returns the token, which clients send
+// back in the CSRF header of unsafe requests.
+func IssueCSRFToken(c *fiber.Ctx, config ...CookieAuthConfig) (string, error) {
+	cfg := CookieAuthConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	cfg = cfg.withDefaults()
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(token)
+	c.Cookie(&fiber.Cookie{
+		Name:     cfg.CSRFCookie,
+		Value:    value,
+		Path:     "/",
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return value, nil
+}
+
+// cookieToken returns the token of the auth cookie and marks the request as authenticated by it.
+func (h *apiHandler[T]) cookieToken(c *fiber.Ctx) string {
+	if h.options.cookieAuth == nil {
+		return ""
+	}
+	token := c.Cookies(h.options.cookieAuth.Cookie)
+	if token != "" {
+		c.Locals(cookieAuthKey{}, true)
+	}
+	return token
+}
+
+// checkCSRF validates the double-submit token of unsafe requests authenticated by the auth cookie.
+func (h *apiHandler[T]) checkCSRF(c *fiber.Ctx) error {
+	config := h.options.cookieAuth
+	if config == nil || config.DisableCSRF {
+		return nil
+	}
+	if fromCookie, _ := c.Locals(cookieAuthKey{}).(bool); !fromCookie {
+		return nil
+	}
+
+	cookie := c.Cookies(config.CSRFCookie)
+	switch c.Method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		if cookie == "" {
+			if _, err := IssueCSRFToken(c, *config); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	header := c.Get(config.CSRFHeader)
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+		return NewForbiddenError("Invalid CSRF token")
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+func TestCSRF(t *testing.T) {
+	token, err := fiberhandlertest.SignToken(testClaims{Sub: "42"}, fiberhandlertest.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := "access_token=" + token
+
+	tests := []struct {
+		name       string
+		config     fiberhandler.CookieAuthConfig
+		method     string
+		header     map[string]string
+		want       int
+		sub        string
+		issuesCSRF bool
+	}{
+		{
+			name:   "unsafe without token",
+			method: http.MethodPost,
+			header: map[string]string{"Cookie": session + "; csrf_token=abc"},
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "unsafe with token",
+			method: http.MethodPost,
+			header: map[string]string{"Cookie": session + "; csrf_token=abc", "X-CSRF-Token": "abc"},
+			want:   http.StatusOK,
+			sub:    "42",
+		},
+		{
+			name:   "unsafe with other token",
+			method: http.MethodPost,
+			header: map[string]string{"Cookie": session + "; csrf_token=abc", "X-CSRF-Token": "abd"},
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "unsafe without csrf cookie",
+			method: http.MethodPost,
+			header: map[string]string{"Cookie": session, "X-CSRF-Token": ""},
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "custom header",
+			config: fiberhandler.CookieAuthConfig{CSRFCookie: "xsrf", CSRFHeader: "X-XSRF-Token"},
+			method: http.MethodPost,
+			header: map[string]string{"Cookie": session + "; xsrf=abc", "X-XSRF-Token": "abc"},
+			want:   http.StatusOK,
+			sub:    "42",
+		},
+		{
+			name:   "bearer token",
+			method: http.MethodPost,
+			header: map[string]string{"Authorization": "Bearer " + token},
+			want:   http.StatusOK,
+			sub:    "42",
+		},
+		{
+			name:   "disabled",
+			config: fiberhandler.CookieAuthConfig{DisableCSRF: true},
+			method: http.MethodPost,
+			header: map[string]string{"Cookie": session},
+			want:   http.StatusOK,
+			sub:    "42",
+		},
+		{
+			name:       "safe issues token",
+			method:     http.MethodGet,
+			header:     map[string]string{"Cookie": session},
+			want:       http.StatusOK,
+			sub:        "42",
+			issuesCSRF: true,
+		},
+		{
+			name:   "safe keeps token",
+			method: http.MethodGet,
+			header: map[string]string{"Cookie": session + "; csrf_token=abc"},
+			want:   http.StatusOK,
+			sub:    "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := fiberhandler.NewWithComponents(
+				fiberhandler.Components[testClaims]{},
+				fiberhandler.WithCookieAuth(tt.config),
+			)
+			handler := func(c *fiber.Ctx) error {
+				return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+					claims, _ := fiberhandler.ClaimsFromContext[testClaims](ctx)
+					if claims == nil {
+						return profile{}, nil
+					}
+					return profile{Sub: claims.Sub}, nil
+				})
+			}
+
+			request := fiberhandlertest.NewRequest(tt.method, "/profile")
+			if tt.method == http.MethodPost {
+				request.JSON(struct{}{})
+			}
+			for key, value := range tt.header {
+				request.Header(key, value)
+			}
+			response := request.Run(t, handler).Status(tt.want)
+			if tt.want == http.StatusOK {
+				var got profile
+				if response.Data(&got); got.Sub != tt.sub {
+					t.Errorf("sub = %q, want %q", got.Sub, tt.sub)
+				}
+			}
+			issued := strings.Contains(response.Header.Get(fiber.HeaderSetCookie), fiberhandler.DefaultCSRFCookie+"=")
+			if issued != tt.issuesCSRF {
+				t.Errorf("issued CSRF cookie = %v, want %v", issued, tt.issuesCSRF)
+			}
+		})
+	}
+}
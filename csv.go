@@ -0,0 +1,238 @@
+package fiberhandler
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prongbang/gopkg/streamx"
+)
+
+// TagCSV is the struct tag naming the column of a field, "-" leaves the field out.
+const TagCSV = "csv"
+
+// utf8BOM makes Excel open UTF-8 files with the right encoding.
+const utf8BOM = "\xEF\xBB\xBF"
+
+// CSVConfig configures CSV and CSVSeq.
+type CSVConfig struct {
+	// Filename is sent in the Content-Disposition header, default "export.csv".
+	Filename string
+
+	// Header is the first row, by default the columns of struct rows, none for other rows.
+	Header []string
+
+	// Comma separates the fields, default ','.
+	Comma rune
+
+	// BOM starts the file with a UTF-8 byte order mark, for Excel.
+	BOM bool
+}
+
+// CSVRecord is implemented by rows encoding themselves.
+type CSVRecord interface {
+	CSVRecord() []string
+}
+
+type csvColumn struct {
+	index []int
+	name  string
+}
+
+// csvColumns caches the columns of struct row types.
+var csvColumns sync.Map
+
+// CSV streams the rows received from rows as a CSV attachment until the channel is closed. Rows are
+// []string, CSVRecord or structs whose exported fields are the columns, named by their csv tag. A row
+// that is an error ends the file. The producer should stop sending once the doFunc context is done:
+//
+//	return fiberhandler.CSV(orders.Export(ctx), fiberhandler.CSVConfig{Filename: "orders.csv", BOM: true}), nil
+func CSV[R any](rows <-chan R, config ...CSVConfig) *StreamResult {
+	return CSVSeq(func(yield func(R) bool) {
+		for row := range rows {
+			if !yield(row) {
+				return
+			}
+		}
+	}, config...)
+}
+
+// CSVSeq streams the rows of seq as a CSV attachment.
+func CSVSeq[R any](rows iter.Seq[R], config ...CSVConfig) *StreamResult {
+	cfg := CSVConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Filename == "" {
+		cfg.Filename = "export.csv"
+	}
+	if cfg.Header == nil {
+		if columns := csvColumnsOf(reflect.TypeFor[R]()); columns != nil {
+			for _, column := range columns {
+				cfg.Header = append(cfg.Header, column.name)
+			}
+		}
+	}
+
+	reader := &pipeReader{write: func(w io.Writer) error {
+		return writeCSV(w, rows, cfg)
+	}}
+	return Attachment(&streamx.Stream{Data: reader, ContentType: streamx.ContentTypeTextCsv, Filename: cfg.Filename})
+}
+
+func writeCSV[R any](w io.Writer, rows iter.Seq[R], config CSVConfig) error {
+	if config.BOM {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return err
+		}
+	}
+	writer := csv.NewWriter(w)
+	if config.Comma != 0 {
+		writer.Comma = config.Comma
+	}
+	if len(config.Header) > 0 {
+		if err := writer.Write(config.Header); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	for row := range rows {
+		var record []string
+		if record, err = csvRecord(row); err != nil {
+			break
+		}
+		if err = writer.Write(record); err != nil {
+			break
+		}
+	}
+	writer.Flush()
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func csvRecord(row any) ([]string, error) {
+	switch r := row.(type) {
+	case error:
+		return nil, r
+	case []string:
+		return r, nil
+	case CSVRecord:
+		return r.CSVRecord(), nil
+	}
+
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	columns := csvColumnsOf(v.Type())
+	if columns == nil {
+		return nil, fmt.Errorf("csv: unsupported row type %T", row)
+	}
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		field, err := v.FieldByIndexErr(column.index)
+		if err == nil {
+			record[i] = csvValue(field)
+		}
+	}
+	return record, nil
+}
+
+// csvColumnsOf returns the columns of a struct row type, nil for other types.
+func csvColumnsOf(t reflect.Type) []csvColumn {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeFor[time.Time]() {
+		return nil
+	}
+	if cached, ok := csvColumns.Load(t); ok {
+		return cached.([]csvColumn)
+	}
+
+	columns := []csvColumn{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || (field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		name := field.Tag.Get(TagCSV)
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, csvColumn{index: field.Index, name: name})
+	}
+	csvColumns.Store(t, columns)
+	return columns
+}
+
+func csvValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	case fmt.Stringer:
+		return value.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// pipeReader runs write in a goroutine on the first Read, so a result that is never sent starts
+// nothing. Close, called by fasthttp once the response ends, stops the writer.
+type pipeReader struct {
+	write  func(w io.Writer) error
+	once   sync.Once
+	reader *io.PipeReader
+}
+
+func (p *pipeReader) start() {
+	p.once.Do(func() {
+		reader, writer := io.Pipe()
+		p.reader = reader
+		go func() {
+			err := p.write(writer)
+			if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+				slog.Error("Failed to write stream", slog.String("error", err.Error()))
+			}
+			_ = writer.CloseWithError(err)
+		}()
+	})
+}
+
+// Read implements io.Reader.
+func (p *pipeReader) Read(b []byte) (int, error) {
+	p.start()
+	return p.reader.Read(b)
+}
+
+// Close implements io.Closer.
+func (p *pipeReader) Close() error {
+	p.once.Do(func() {})
+	if p.reader == nil {
+		return nil
+	}
+	return p.reader.Close()
+}
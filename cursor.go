@@ -0,0 +1,80 @@
+package fiberhandler
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/goccy/go-json"
+)
+
+// CursorPageable parses cursor and limit from the query for keyset pagination, embed it in a request
+// struct like Pageable. The limit is bounded the same way.
+type CursorPageable struct {
+	Cursor string `json:"cursor" query:"cursor" form:"cursor"`
+	Limit  int    `json:"limit" query:"limit" form:"limit"`
+}
+
+func (p *CursorPageable) normalizePage(limits pageLimits) error {
+	page := Pageable{Page: 1, Limit: p.Limit}
+	if err := page.normalizePage(limits); err != nil {
+		return err
+	}
+	p.Limit = page.Limit
+	return nil
+}
+
+// CursorPage is a page of items with the opaque cursor of the next page, NextCursor is empty on the last page.
+type CursorPage[T any] struct {
+	Items      []T    `json:"items"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// NewCursorPage returns the items of a page, next is the cursor of the last item when there are more items.
+// Fetch limit+1 rows to know whether there is a next page without counting.
+func NewCursorPage[T any, C any](items []T, limit int, next func(last T) C) (*CursorPage[T], error) {
+	page := &CursorPage[T]{Items: items, Limit: limit}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if limit > 0 && len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+
+		cursor, err := EncodeCursor(next(page.Items[limit-1]))
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = cursor
+	}
+	return page, nil
+}
+
+// EncodeCursor encodes cursor as an opaque URL safe token.
+func EncodeCursor[C any](cursor C) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a token created by EncodeCursor, an empty token returns the zero cursor.
+// Malformed tokens return a BadRequestError.
+func DecodeCursor[C any](token string) (C, error) {
+	var cursor C
+	if token == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, NewBadRequestError("Invalid cursor")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, NewBadRequestError("Invalid cursor")
+	}
+	return cursor, nil
+}
@@ -0,0 +1,210 @@
+package fiberhandler
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TagDefault is the struct tag holding the value of a request field left empty by the client, e.g.
+// `default:"20"`. Slices take comma separated values.
+const TagDefault = "default"
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// defaultPlan lists the fields of a struct type with a default, and the struct fields holding some.
+type defaultPlan struct {
+	fields []defaultField
+}
+
+type defaultField struct {
+	index  int
+	value  reflect.Value
+	nested *defaultPlan
+}
+
+type cachedDefaultPlan struct {
+	plan *defaultPlan
+	err  error
+}
+
+// defaultPlans caches the defaultPlan of request types, nil for types without defaults.
+var defaultPlans sync.Map
+
+// applyDefaults sets the fields tagged with TagDefault the request left at their zero value. A zero
+// value cannot be told from an explicit one, fields accepting a zero, e.g. false, should be pointers.
+func applyDefaults(requestPtr any) error {
+	v := reflect.ValueOf(requestPtr)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	plan, err := defaultPlanOf(v.Elem().Type())
+	if err != nil || plan == nil {
+		return err
+	}
+	plan.apply(v.Elem())
+	return nil
+}
+
+func defaultPlanOf(t reflect.Type) (*defaultPlan, error) {
+	if cached, ok := defaultPlans.Load(t); ok {
+		return cached.(cachedDefaultPlan).plan, cached.(cachedDefaultPlan).err
+	}
+	plan, err := compileDefaults(t, map[reflect.Type]bool{})
+	defaultPlans.Store(t, cachedDefaultPlan{plan: plan, err: err})
+	return plan, err
+}
+
+func compileDefaults(t reflect.Type, visiting map[reflect.Type]bool) (*defaultPlan, error) {
+	if visiting[t] {
+		return nil, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	plan := &defaultPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if tag, ok := field.Tag.Lookup(TagDefault); ok {
+			value, err := parseValue(field.Type, tag)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: invalid %s tag %q: %w", t, field.Name, TagDefault, tag, err)
+			}
+			plan.fields = append(plan.fields, defaultField{index: i, value: value})
+			continue
+		}
+
+		nestedType := field.Type
+		if nestedType.Kind() == reflect.Pointer {
+			nestedType = nestedType.Elem()
+		}
+		if nestedType.Kind() != reflect.Struct {
+			continue
+		}
+		nested, err := compileDefaults(nestedType, visiting)
+		if err != nil {
+			return nil, err
+		}
+		if nested != nil {
+			plan.fields = append(plan.fields, defaultField{index: i, nested: nested})
+		}
+	}
+
+	if len(plan.fields) == 0 {
+		return nil, nil
+	}
+	return plan, nil
+}
+
+func (p *defaultPlan) apply(v reflect.Value) {
+	for _, field := range p.fields {
+		target := v.Field(field.index)
+		if field.nested != nil {
+			if target.Kind() == reflect.Pointer {
+				// Absent nested objects stay absent
+				if target.IsNil() {
+					continue
+				}
+				target = target.Elem()
+			}
+			field.nested.apply(target)
+			continue
+		}
+		if target.IsZero() {
+			target.Set(copyDefault(field.value))
+		}
+	}
+}
+
+// copyDefault returns a copy of value, requests must not share the pointers and slices of a default.
+func copyDefault(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Pointer:
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(copyDefault(value.Elem()))
+		return copied
+	case reflect.Slice:
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(copied, value)
+		return copied
+	}
+	return value
+}
+
+// parseValue converts the string into a value of t, with the converter registered for t, its
+// encoding.TextUnmarshaler or its kind.
+func parseValue(t reflect.Type, s string) (reflect.Value, error) {
+	if convert, ok := converterOf(t); ok {
+		return convert(s)
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		value := reflect.New(t)
+		if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return value.Elem(), nil
+	}
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		return reflect.ValueOf(d), err
+	}
+
+	value := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := parseValue(t.Elem(), s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = reflect.New(t.Elem())
+		value.Elem().Set(elem)
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		items := strings.Split(s, ",")
+		value = reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			elem, err := parseValue(t.Elem(), strings.TrimSpace(item))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.Index(i).Set(elem)
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", t)
+	}
+	return value, nil
+}
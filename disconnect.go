@@ -0,0 +1,84 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatusClientClosedRequest is the status recorded for requests whose client disconnected before the
+// response, as used by nginx.
+const StatusClientClosedRequest = 499
+
+// ErrClientDisconnected is the cause of the doFunc context cancelled by WithDisconnectCancel.
+var ErrClientDisconnected = errors.New("client disconnected")
+
+const defaultDisconnectInterval = 250 * time.Millisecond
+
+// WithDisconnectCancel cancels the doFunc context, with ErrClientDisconnected as cause, once the client
+// closed the connection, so long-running queries and downstream calls stop. The connection is checked
+// every interval, default 250ms, without consuming its data. Nothing is sent for a cancelled request,
+// observers see StatusClientClosedRequest. Supported on Linux, macOS and the BSDs over TCP or TLS.
+func WithDisconnectCancel(interval ...time.Duration) Option {
+	d := defaultDisconnectInterval
+	if len(interval) > 0 && interval[0] > 0 {
+		d = interval[0]
+	}
+
+	return func(o *options) {
+		o.disconnectInterval = d
+	}
+}
+
+// watchDisconnect returns ctx cancelled when the client disconnects and the function stopping the
+// watch, which also cancels ctx when release is set.
+func (h *apiHandler[T]) watchDisconnect(c *fiber.Ctx, ctx context.Context) (context.Context, func(release bool)) {
+	interval := h.options.disconnectInterval
+	conn := c.Context().Conn()
+	if interval <= 0 || conn == nil {
+		return ctx, func(bool) {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				closed, supported := connClosed(conn)
+				if !supported {
+					return
+				}
+				if closed {
+					cancel(ErrClientDisconnected)
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func(release bool) {
+		// The connection is reused once the handler returns, the watch must be over by then
+		close(done)
+		<-stopped
+		if release {
+			cancel(nil)
+		}
+	}
+}
+
+// disconnected reports whether ctx was cancelled because the client disconnected.
+func disconnected(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrClientDisconnected)
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package fiberhandler
+
+import "net"
+
+// connClosed is not supported on this platform.
+func connClosed(net.Conn) (closed bool, supported bool) {
+	return false, false
+}
@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package fiberhandler
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// connClosed peeks at the socket of conn without blocking or consuming data: a read of zero bytes or an
+// error other than EAGAIN means the peer closed the connection.
+func connClosed(conn net.Conn) (closed bool, supported bool) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	sysConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return false, false
+	}
+	rawConn, err := sysConn.SyscallConn()
+	if err != nil {
+		return false, false
+	}
+
+	var buf [1]byte
+	err = rawConn.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf[:], syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		switch {
+		case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EWOULDBLOCK), errors.Is(err, syscall.EINTR):
+		case err != nil, n == 0:
+			closed = true
+		}
+		return true
+	})
+	if err != nil {
+		return true, true
+	}
+	return closed, true
+}
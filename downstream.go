@@ -0,0 +1,133 @@
+package fiberhandler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// grpcResourceExhausted is the RESOURCE_EXHAUSTED code of gRPC.
+const grpcResourceExhausted = 8
+
+// RetryAfterer is implemented by errors of clients knowing when a rate limited call may be retried.
+type RetryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// ParseRetryAfter returns the delay of a Retry-After header, in seconds or an HTTP date, zero when it is
+// missing or invalid.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// translateDownstreamError returns a TooManyRequestsError for the rate limit errors of downstream calls
+// made by doFunc: errors with a 429 status and gRPC RESOURCE_EXHAUSTED statuses. The retry delay of a
+// RetryAfterer or a gRPC RetryInfo detail is kept, other errors are returned as is.
+func translateDownstreamError(err error) error {
+	if IsTooManyRequests(err) {
+		return err
+	}
+
+	var statusErr StatusCoder
+	limited := errors.As(err, &statusErr) && statusErr.StatusCode() == http.StatusTooManyRequests
+	grpcRetryAfter, grpcLimited := grpcRateLimit(err)
+	if !limited && !grpcLimited {
+		return err
+	}
+
+	retryAfter := grpcRetryAfter
+	var retryErr RetryAfterer
+	if errors.As(err, &retryErr) {
+		retryAfter = retryErr.RetryAfter()
+	}
+	return NewTooManyRequestsError(retryAfter)
+}
+
+// grpcRateLimit reports whether err carries a gRPC RESOURCE_EXHAUSTED status, and the delay of its
+// RetryInfo detail. The status is read by its methods so the handler does not depend on grpc-go.
+func grpcRateLimit(err error) (time.Duration, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		method := reflect.ValueOf(err).MethodByName("GRPCStatus")
+		if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+			continue
+		}
+		status := method.Call(nil)[0]
+		if status.Kind() == reflect.Pointer && status.IsNil() {
+			continue
+		}
+
+		code, ok := callMethod(status, "Code")
+		if !ok || !code.CanUint() || code.Uint() != grpcResourceExhausted {
+			return 0, false
+		}
+		return grpcRetryDelay(status), true
+	}
+	return 0, false
+}
+
+// grpcRetryDelay returns the retry delay of the RetryInfo detail of a gRPC status.
+func grpcRetryDelay(status reflect.Value) time.Duration {
+	details, ok := callMethod(status, "Details")
+	if !ok || details.Kind() != reflect.Slice {
+		return 0
+	}
+	for i := range details.Len() {
+		delay, ok := callMethod(details.Index(i), "GetRetryDelay")
+		if !ok || (delay.Kind() == reflect.Pointer && delay.IsNil()) {
+			continue
+		}
+		if duration, ok := callMethod(delay, "AsDuration"); ok {
+			if d, ok := duration.Interface().(time.Duration); ok {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+// callMethod calls the method without arguments named name on v and returns its first result.
+func callMethod(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	method := v.MethodByName(name)
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() == 0 {
+		return reflect.Value{}, false
+	}
+	return method.Call(nil)[0], true
+}
+
+// setRetryAfter sets the Retry-After header of a TooManyRequestsError or an UnavailableError, in whole
+// seconds rounded up.
+func setRetryAfter(c *fiber.Ctx, err error) {
+	var retryAfter time.Duration
+	if tooMany, ok := AsTooManyRequests(err); ok {
+		retryAfter = tooMany.RetryAfter
+	} else if unavailable, ok := AsUnavailable(err); ok {
+		retryAfter = unavailable.RetryAfter
+	}
+	if retryAfter <= 0 {
+		return
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+}
@@ -0,0 +1,140 @@
+package fiberhandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/goerror"
+)
+
+// EnvelopeVersion selects the shape of the JSON bodies sent by the handler.
+type EnvelopeVersion string
+
+const (
+	// EnvelopeV1 is the legacy fibererror envelope, {code, message, data}.
+	EnvelopeV1 EnvelopeVersion = "1"
+
+	// EnvelopeV2 sends successful results as {data} and errors as {error: {code, message, status, violations}}.
+	EnvelopeV2 EnvelopeVersion = "2"
+
+	// HeaderEnvelopeVersion is the default header selecting the envelope of a request.
+	HeaderEnvelopeVersion = "X-Envelope-Version"
+)
+
+// EnvelopeConfig configures WithEnvelope.
+type EnvelopeConfig struct {
+	// Default is the envelope of requests without the header, default EnvelopeV1. Route groups migrate by
+	// using a handler derived with With and a different Default.
+	Default EnvelopeVersion
+
+	// Header selects the envelope of a request, "1", "2", "v1" or "v2". Default X-Envelope-Version.
+	Header string
+
+	// OnLegacy is called for every response sent in the EnvelopeV1 envelope, e.g. to count the clients
+	// left to migrate. The version is also reported as Outcome.Envelope.
+	OnLegacy func(c *fiber.Ctx)
+}
+
+type envelopeV2 struct {
+	Data any `json:"data"`
+}
+
+type envelopeV2Error struct {
+	Error envelopeV2ErrorBody `json:"error"`
+}
+
+type envelopeV2ErrorBody struct {
+	Code       string           `json:"code,omitempty"`
+	Message    string           `json:"message"`
+	Status     int              `json:"status"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+type envelopeKey struct{}
+
+// WithEnvelope serves the legacy and the new envelope side by side, selected per request by a header
+// or per route group by the default, so clients can migrate one at a time. The version sent is echoed
+// in the response header.
+func WithEnvelope(config EnvelopeConfig) Option {
+	if config.Default == "" {
+		config.Default = EnvelopeV1
+	}
+	if config.Header == "" {
+		config.Header = HeaderEnvelopeVersion
+	}
+
+	return func(o *options) {
+		o.envelope = &config
+	}
+}
+
+// envelopeVersion returns the envelope of the request, EnvelopeV1 when versioning is not configured.
+func (h *apiHandler[T]) envelopeVersion(c *fiber.Ctx) EnvelopeVersion {
+	config := h.options.envelope
+	if config == nil {
+		return EnvelopeV1
+	}
+
+	switch strings.TrimPrefix(strings.ToLower(c.Get(config.Header)), "v") {
+	case string(EnvelopeV1):
+		return EnvelopeV1
+	case string(EnvelopeV2):
+		return EnvelopeV2
+	}
+	return config.Default
+}
+
+// useEnvelope records the envelope of the response and returns it.
+func (h *apiHandler[T]) useEnvelope(c *fiber.Ctx) EnvelopeVersion {
+	version := h.envelopeVersion(c)
+	if config := h.options.envelope; config != nil {
+		c.Locals(envelopeKey{}, version)
+		c.Set(config.Header, string(version))
+		if version == EnvelopeV1 && config.OnLegacy != nil {
+			config.OnLegacy(c)
+		}
+	}
+	return version
+}
+
+// sendEnvelope sends data in the success envelope of the request.
+func (h *apiHandler[T]) sendEnvelope(c *fiber.Ctx, data any) error {
+	data, err := h.encodeData(data)
+	if err != nil {
+		return h.SendError(c, err)
+	}
+	if h.useEnvelope(c) == EnvelopeV2 {
+		return c.Status(http.StatusOK).JSON(envelopeV2{Data: data})
+	}
+	return h.Response.With(c).Response(goerror.NewOK(data))
+}
+
+// sendEnvelopeV2Error rewrites the error response sent for err in the EnvelopeV2 shape.
+func (h *apiHandler[T]) sendEnvelopeV2Error(c *fiber.Ctx, err error) error {
+	if sendErr := h.sendDefaultError(c, err); sendErr != nil {
+		return sendErr
+	}
+
+	status := c.Response().StatusCode()
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+		if IsValidationError(err) {
+			status = http.StatusBadRequest
+		}
+	}
+
+	var body goerror.Body
+	_ = json.Unmarshal(c.Response().Body(), &body)
+	c.Response().ResetBody()
+
+	errBody := envelopeV2ErrorBody{Code: body.Code, Message: body.Message, Status: status}
+	if errBody.Message == "" {
+		errBody.Message = http.StatusText(status)
+	}
+	if dataInvalid, ok := AsValidationError(err); ok {
+		errBody.Violations = dataInvalid.Violations
+	}
+	return c.Status(status).JSON(envelopeV2Error{Error: errBody})
+}
@@ -0,0 +1,208 @@
+package fiberhandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/goerror"
+)
+
+// ErrorFormat selects the body of error responses.
+type ErrorFormat int
+
+const (
+	// ErrorFormatDefault sends errors through the fibererror response.
+	ErrorFormatDefault ErrorFormat = iota
+
+	// ErrorFormatGRPCStatus sends google.rpc.Status bodies (code, message, details with ErrorInfo and
+	// BadRequest), so gateways translating to gRPC keep the semantics.
+	ErrorFormatGRPCStatus
+
+	// ErrorFormatProblemJSON sends RFC 7807 application/problem+json bodies (type, title, status, detail,
+	// instance) with the error code and the field violations as extension members.
+	ErrorFormatProblemJSON
+)
+
+// MIMEApplicationProblemJSON is the content type of RFC 7807 Problem Details.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ErrorDomain is the ErrorInfo domain of gRPC status bodies.
+var ErrorDomain = "fiberhandler"
+
+// ProblemTypeBase prefixes the error code to build the type URI of Problem Details, e.g.
+// "https://example.com/problems/". Left empty, the type is "about:blank".
+var ProblemTypeBase = ""
+
+// WithErrorFormat selects the body of error responses.
+func WithErrorFormat(format ErrorFormat) Option {
+	return func(o *options) {
+		o.errorFormat = format
+	}
+}
+
+// FieldViolation describes a request field failing validation.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+func fieldViolations(err error) []FieldViolation {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	violations := make([]FieldViolation, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		violations = append(violations, FieldViolation{
+			Field:       fieldErr.Namespace(),
+			Description: fieldErr.Error(),
+		})
+	}
+	return violations
+}
+
+// grpcCodes maps HTTP statuses to google.rpc.Code.
+var grpcCodes = map[int]int{
+	499:                                    1,  // CANCELLED
+	http.StatusBadRequest:                  3,  // INVALID_ARGUMENT
+	http.StatusUnprocessableEntity:         3,  // INVALID_ARGUMENT
+	http.StatusGatewayTimeout:              4,  // DEADLINE_EXCEEDED
+	http.StatusRequestTimeout:              4,  // DEADLINE_EXCEEDED
+	http.StatusNotFound:                    5,  // NOT_FOUND
+	http.StatusConflict:                    6,  // ALREADY_EXISTS
+	http.StatusForbidden:                   7,  // PERMISSION_DENIED
+	http.StatusTooManyRequests:             8,  // RESOURCE_EXHAUSTED
+	http.StatusRequestEntityTooLarge:       8,  // RESOURCE_EXHAUSTED
+	http.StatusPreconditionFailed:          9,  // FAILED_PRECONDITION
+	http.StatusMethodNotAllowed:            12, // UNIMPLEMENTED
+	http.StatusNotImplemented:              12, // UNIMPLEMENTED
+	http.StatusInternalServerError:         13, // INTERNAL
+	http.StatusServiceUnavailable:          14, // UNAVAILABLE
+	http.StatusUnauthorized:                16, // UNAUTHENTICATED
+	http.StatusRequestHeaderFieldsTooLarge: 8,  // RESOURCE_EXHAUSTED
+}
+
+type grpcStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Details []any  `json:"details,omitempty"`
+}
+
+type grpcErrorInfo struct {
+	Type     string            `json:"@type"`
+	Reason   string            `json:"reason"`
+	Domain   string            `json:"domain"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type grpcBadRequest struct {
+	Type            string           `json:"@type"`
+	FieldViolations []FieldViolation `json:"fieldViolations"`
+}
+
+func grpcCode(status int) int {
+	if code, ok := grpcCodes[status]; ok {
+		return code
+	}
+	switch {
+	case status >= http.StatusInternalServerError:
+		return 13 // INTERNAL
+	case status >= http.StatusBadRequest:
+		return 9 // FAILED_PRECONDITION
+	}
+	return 2 // UNKNOWN
+}
+
+// sendFormattedError lets the default error response render err, then rewrites its body in the
+// configured format keeping the status it chose.
+func (h *apiHandler[T]) sendFormattedError(c *fiber.Ctx, err error) error {
+	if sendErr := h.sendDefaultError(c, err); sendErr != nil {
+		return sendErr
+	}
+
+	status := c.Response().StatusCode()
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+		if IsValidationError(err) {
+			status = http.StatusBadRequest
+		}
+	}
+
+	var body goerror.Body
+	_ = json.Unmarshal(c.Response().Body(), &body)
+	c.Response().ResetBody()
+
+	switch h.options.errorFormat {
+	case ErrorFormatGRPCStatus:
+		return c.Status(status).JSON(newGRPCStatus(status, body, err))
+	case ErrorFormatProblemJSON:
+		return c.Status(status).JSON(newProblem(c, status, body, err), MIMEApplicationProblemJSON)
+	}
+	return nil
+}
+
+func newGRPCStatus(status int, body goerror.Body, err error) grpcStatus {
+	message := body.Message
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	result := grpcStatus{
+		Code:    grpcCode(status),
+		Message: message,
+	}
+	if body.Code != "" {
+		result.Details = append(result.Details, grpcErrorInfo{
+			Type:   "type.googleapis.com/google.rpc.ErrorInfo",
+			Reason: body.Code,
+			Domain: ErrorDomain,
+		})
+	}
+
+	var dataInvalid *DataInvalidError
+	if errors.As(err, &dataInvalid) && len(dataInvalid.Violations) > 0 {
+		result.Details = append(result.Details, grpcBadRequest{
+			Type:            "type.googleapis.com/google.rpc.BadRequest",
+			FieldViolations: dataInvalid.Violations,
+		})
+	}
+	return result
+}
+
+// problem is an RFC 7807 Problem Details body.
+type problem struct {
+	Type     string           `json:"type"`
+	Title    string           `json:"title"`
+	Status   int              `json:"status"`
+	Detail   string           `json:"detail,omitempty"`
+	Instance string           `json:"instance,omitempty"`
+	Code     string           `json:"code,omitempty"`
+	Errors   []FieldViolation `json:"errors,omitempty"`
+}
+
+func newProblem(c *fiber.Ctx, status int, body goerror.Body, err error) problem {
+	result := problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   body.Message,
+		Instance: c.OriginalURL(),
+		Code:     body.Code,
+	}
+	if ProblemTypeBase != "" && body.Code != "" {
+		result.Type = ProblemTypeBase + body.Code
+	}
+	if result.Detail == result.Title {
+		result.Detail = ""
+	}
+
+	var dataInvalid *DataInvalidError
+	if errors.As(err, &dataInvalid) {
+		result.Errors = dataInvalid.Violations
+	}
+	return result
+}
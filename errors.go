@@ -1,9 +1,49 @@
 package fiberhandler
 
-import "github.com/prongbang/goerror"
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/prongbang/goerror"
+)
+
+// Sentinel errors matched by the errors returned from the handler, use errors.Is to branch on the class.
+var (
+	ErrBadRequest       = errors.New("bad request")
+	ErrValidation       = errors.New("validation failed")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	ErrConflict         = errors.New("conflict")
+	ErrUnprocessable    = errors.New("unprocessable entity")
+	ErrInternal         = errors.New("internal server error")
+	ErrTooManyRequests  = errors.New("too many requests")
+	ErrTimeout          = errors.New("timeout")
+	ErrUnavailable      = errors.New("service unavailable")
+
+	// Reasons of a TokenError, they also match ErrUnauthorized.
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+	ErrTokenRevoked     = errors.New("token revoked")
+
+	// Reasons of a JSONPatchError.
+	ErrInvalidPatch      = errors.New("invalid JSON patch")
+	ErrPatchPathNotFound = errors.New("JSON patch path not found")
+	ErrPatchTestFailed   = errors.New("JSON patch test failed")
+)
+
+// StatusCoder is implemented by errors that carry their own HTTP status.
+type StatusCoder interface {
+	StatusCode() int
+}
 
 type DataInvalidError struct {
 	goerror.Body
+	Violations []FieldViolation `json:"-"`
 }
 
 // Error implements error.
@@ -11,6 +51,11 @@ func (c *DataInvalidError) Error() string {
 	return c.Message
 }
 
+// Is reports whether target is ErrValidation.
+func (c *DataInvalidError) Is(target error) bool {
+	return target == ErrValidation
+}
+
 func NewDataInvalidError() error {
 	return &DataInvalidError{
 		Body: goerror.Body{
@@ -19,3 +64,656 @@ func NewDataInvalidError() error {
 		},
 	}
 }
+
+// newValidationError returns a DataInvalidError carrying the field violations of a validator error.
+func newValidationError(err error) error {
+	dataInvalid := NewDataInvalidError().(*DataInvalidError)
+	dataInvalid.Violations = fieldViolations(err)
+	return dataInvalid
+}
+
+type MethodNotAllowedError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *MethodNotAllowedError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrMethodNotAllowed.
+func (c *MethodNotAllowedError) Is(target error) bool {
+	return target == ErrMethodNotAllowed
+}
+
+// StatusCode implements StatusCoder.
+func (c *MethodNotAllowedError) StatusCode() int {
+	return http.StatusMethodNotAllowed
+}
+
+func NewMethodNotAllowedError() error {
+	return &MethodNotAllowedError{
+		Body: goerror.Body{
+			Code:    "CLE030",
+			Message: "Method not allowed",
+		},
+	}
+}
+
+type BadRequestError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *BadRequestError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrBadRequest.
+func (c *BadRequestError) Is(target error) bool {
+	return target == ErrBadRequest
+}
+
+// StatusCode implements StatusCoder.
+func (c *BadRequestError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// NewBadRequestError returns a 400 with the code of goerror.NewBadRequest, CLE000.
+func NewBadRequestError(message ...string) error {
+	msg := http.StatusText(http.StatusBadRequest)
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &BadRequestError{
+		Body: goerror.Body{
+			Code:    goerror.CodeBadRequest,
+			Message: msg,
+		},
+	}
+}
+
+type UnauthorizedError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *UnauthorizedError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrUnauthorized.
+func (c *UnauthorizedError) Is(target error) bool {
+	return target == ErrUnauthorized
+}
+
+// StatusCode implements StatusCoder.
+func (c *UnauthorizedError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+func NewUnauthorizedError(message ...string) error {
+	msg := "Unauthorized"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &UnauthorizedError{
+		Body: goerror.Body{
+			Code:    "CLE032",
+			Message: msg,
+		},
+	}
+}
+
+type ForbiddenError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *ForbiddenError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrForbidden.
+func (c *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// StatusCode implements StatusCoder.
+func (c *ForbiddenError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+func NewForbiddenError(message ...string) error {
+	msg := "Forbidden"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &ForbiddenError{
+		Body: goerror.Body{
+			Code:    "CLE033",
+			Message: msg,
+		},
+	}
+}
+
+type ConflictError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *ConflictError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrConflict.
+func (c *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// StatusCode implements StatusCoder.
+func (c *ConflictError) StatusCode() int {
+	return http.StatusConflict
+}
+
+func NewConflictError(message ...string) error {
+	msg := "Conflict"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &ConflictError{
+		Body: goerror.Body{
+			Code:    "CLE034",
+			Message: msg,
+		},
+	}
+}
+
+type UnprocessableError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *UnprocessableError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrUnprocessable.
+func (c *UnprocessableError) Is(target error) bool {
+	return target == ErrUnprocessable
+}
+
+// StatusCode implements StatusCoder.
+func (c *UnprocessableError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func NewUnprocessableError(message ...string) error {
+	msg := "Unprocessable entity"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &UnprocessableError{
+		Body: goerror.Body{
+			Code:    "CLE035",
+			Message: msg,
+		},
+	}
+}
+
+type InternalError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *InternalError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrInternal.
+func (c *InternalError) Is(target error) bool {
+	return target == ErrInternal
+}
+
+// StatusCode implements StatusCoder.
+func (c *InternalError) StatusCode() int {
+	return http.StatusInternalServerError
+}
+
+func NewInternalError(message ...string) error {
+	msg := "Internal server error"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &InternalError{
+		Body: goerror.Body{
+			Code:    "CLE036",
+			Message: msg,
+		},
+	}
+}
+
+// TokenError rejects a token whose claims failed validation, its code tells clients whether to refresh
+// the token (CLE040, CLE041) or to log in again (CLE042, CLE043, CLE044).
+type TokenError struct {
+	goerror.Body
+	Reason error `json:"-"`
+}
+
+// tokenErrorCodes maps the codes of a TokenError back to its reason.
+var tokenErrorCodes = map[string]error{
+	"CLE040": ErrTokenExpired,
+	"CLE041": ErrTokenNotYetValid,
+	"CLE042": ErrInvalidIssuer,
+	"CLE043": ErrInvalidAudience,
+	"CLE044": ErrTokenRevoked,
+}
+
+// Error implements error.
+func (c *TokenError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrUnauthorized or the reason of the error.
+func (c *TokenError) Is(target error) bool {
+	return target == ErrUnauthorized || (c.Reason != nil && target == c.Reason)
+}
+
+// StatusCode implements StatusCoder.
+func (c *TokenError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// NewTokenError returns the error of a token rejected for reason, one of ErrTokenExpired,
+// ErrTokenNotYetValid, ErrInvalidIssuer, ErrInvalidAudience or ErrTokenRevoked.
+func NewTokenError(reason error) error {
+	var code, message string
+	switch reason {
+	case ErrTokenExpired:
+		code, message = "CLE040", "Token expired"
+	case ErrTokenNotYetValid:
+		code, message = "CLE041", "Token not yet valid"
+	case ErrInvalidIssuer:
+		code, message = "CLE042", "Invalid token issuer"
+	case ErrInvalidAudience:
+		code, message = "CLE043", "Invalid token audience"
+	case ErrTokenRevoked:
+		code, message = "CLE044", "Token revoked"
+	default:
+		return NewUnauthorizedError()
+	}
+	return &TokenError{Body: goerror.Body{Code: code, Message: message}, Reason: reason}
+}
+
+type TooManyRequestsError struct {
+	goerror.Body
+
+	// RetryAfter is sent as the Retry-After header when positive.
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Error implements error.
+func (c *TooManyRequestsError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrTooManyRequests.
+func (c *TooManyRequestsError) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
+
+// StatusCode implements StatusCoder.
+func (c *TooManyRequestsError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func NewTooManyRequestsError(retryAfter time.Duration, message ...string) error {
+	msg := "Too many requests"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &TooManyRequestsError{
+		Body: goerror.Body{
+			Code:    "CLE037",
+			Message: msg,
+		},
+		RetryAfter: retryAfter,
+	}
+}
+
+type TimeoutError struct {
+	goerror.Body
+}
+
+// Error implements error.
+func (c *TimeoutError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrTimeout.
+func (c *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// StatusCode implements StatusCoder.
+func (c *TimeoutError) StatusCode() int {
+	return http.StatusGatewayTimeout
+}
+
+func NewTimeoutError(message ...string) error {
+	msg := "Request timed out"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &TimeoutError{
+		Body: goerror.Body{
+			Code:    "CLE038",
+			Message: msg,
+		},
+	}
+}
+
+type UnavailableError struct {
+	goerror.Body
+
+	// RetryAfter is sent as the Retry-After header when positive.
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Error implements error.
+func (c *UnavailableError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrUnavailable.
+func (c *UnavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+// StatusCode implements StatusCoder.
+func (c *UnavailableError) StatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+func NewUnavailableError(retryAfter time.Duration, message ...string) error {
+	msg := "Service unavailable"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return &UnavailableError{
+		Body: goerror.Body{
+			Code:    "CLE039",
+			Message: msg,
+		},
+		RetryAfter: retryAfter,
+	}
+}
+
+func IsBadRequestError(err error) bool {
+	return errors.Is(err, ErrBadRequest)
+}
+
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+func IsMethodNotAllowed(err error) bool {
+	return errors.Is(err, ErrMethodNotAllowed)
+}
+
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+func IsUnprocessable(err error) bool {
+	return errors.Is(err, ErrUnprocessable)
+}
+
+func IsInternal(err error) bool {
+	return errors.Is(err, ErrInternal)
+}
+
+func IsTooManyRequests(err error) bool {
+	return errors.Is(err, ErrTooManyRequests)
+}
+
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout)
+}
+
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
+
+func AsBadRequestError(err error) (*BadRequestError, bool) {
+	var target *BadRequestError
+	return target, errors.As(err, &target)
+}
+
+func AsValidationError(err error) (*DataInvalidError, bool) {
+	var target *DataInvalidError
+	return target, errors.As(err, &target)
+}
+
+func AsUnauthorized(err error) (*UnauthorizedError, bool) {
+	var target *UnauthorizedError
+	return target, errors.As(err, &target)
+}
+
+func AsForbidden(err error) (*ForbiddenError, bool) {
+	var target *ForbiddenError
+	return target, errors.As(err, &target)
+}
+
+func AsConflict(err error) (*ConflictError, bool) {
+	var target *ConflictError
+	return target, errors.As(err, &target)
+}
+
+func AsUnprocessable(err error) (*UnprocessableError, bool) {
+	var target *UnprocessableError
+	return target, errors.As(err, &target)
+}
+
+func AsInternal(err error) (*InternalError, bool) {
+	var target *InternalError
+	return target, errors.As(err, &target)
+}
+
+func AsTooManyRequests(err error) (*TooManyRequestsError, bool) {
+	var target *TooManyRequestsError
+	return target, errors.As(err, &target)
+}
+
+func AsTimeout(err error) (*TimeoutError, bool) {
+	var target *TimeoutError
+	return target, errors.As(err, &target)
+}
+
+func AsUnavailable(err error) (*UnavailableError, bool) {
+	var target *UnavailableError
+	return target, errors.As(err, &target)
+}
+
+// ResponseError is returned by ParseError for error responses not produced by the handler itself.
+type ResponseError struct {
+	goerror.Body
+	Status int `json:"-"`
+}
+
+// Error implements error.
+func (c *ResponseError) Error() string {
+	return c.Message
+}
+
+// StatusCode implements StatusCoder.
+func (c *ResponseError) StatusCode() int {
+	return c.Status
+}
+
+// ParseError turns an error response of a service built on fiberhandler back into a typed error,
+// so Go clients can branch with the Is/As helpers. It returns nil for non error statuses.
+func ParseError(status int, body []byte) error {
+	if status < http.StatusBadRequest {
+		return nil
+	}
+
+	var errBody goerror.Body
+	_ = json.Unmarshal(body, &errBody)
+
+	switch errBody.Code {
+	case "CLE029":
+		return &DataInvalidError{Body: errBody}
+	case "CLE030":
+		return &MethodNotAllowedError{Body: errBody}
+	case goerror.CodeBadRequest:
+		return &BadRequestError{Body: errBody}
+	case "CLE032":
+		return &UnauthorizedError{Body: errBody}
+	case "CLE033":
+		return &ForbiddenError{Body: errBody}
+	case "CLE034":
+		return &ConflictError{Body: errBody}
+	case "CLE035":
+		return &UnprocessableError{Body: errBody}
+	case "CLE036":
+		return &InternalError{Body: errBody}
+	case "CLE037":
+		return &TooManyRequestsError{Body: errBody}
+	case "CLE038":
+		return &TimeoutError{Body: errBody}
+	case "CLE039":
+		return &UnavailableError{Body: errBody}
+	case "CLE040", "CLE041", "CLE042", "CLE043", "CLE044":
+		return &TokenError{Body: errBody, Reason: tokenErrorCodes[errBody.Code]}
+	case "CLE045", "CLE046", "CLE047":
+		return &JSONPatchError{Body: errBody, Reason: jsonPatchErrorCodes[errBody.Code]}
+	}
+
+	switch status {
+	case http.StatusBadRequest:
+		return &BadRequestError{Body: errBody}
+	case http.StatusUnauthorized:
+		return &UnauthorizedError{Body: errBody}
+	case http.StatusForbidden:
+		return &ForbiddenError{Body: errBody}
+	case http.StatusMethodNotAllowed:
+		return &MethodNotAllowedError{Body: errBody}
+	case http.StatusConflict:
+		return &ConflictError{Body: errBody}
+	case http.StatusUnprocessableEntity:
+		return &UnprocessableError{Body: errBody}
+	case http.StatusTooManyRequests:
+		return &TooManyRequestsError{Body: errBody}
+	case http.StatusGatewayTimeout:
+		return &TimeoutError{Body: errBody}
+	case http.StatusServiceUnavailable:
+		return &UnavailableError{Body: errBody}
+	}
+	return &ResponseError{Body: errBody, Status: status}
+}
+
+// goError returns the goerror type of the status of err holding its body, so the errors of the
+// handler are sent by fibererror like those of goerror. It returns nil for the statuses goerror
+// does not define.
+func goError(err StatusCoder) error {
+	e, ok := err.(error)
+	if !ok {
+		return nil
+	}
+	body, bodyErr := goerror.GetBody(e)
+	if bodyErr != nil {
+		return nil
+	}
+	switch err.StatusCode() {
+	case http.StatusBadRequest:
+		return &goerror.BadRequest{Body: body}
+	case http.StatusUnauthorized:
+		return &goerror.Unauthorized{Body: body}
+	case http.StatusPaymentRequired:
+		return &goerror.PaymentRequired{Body: body}
+	case http.StatusForbidden:
+		return &goerror.Forbidden{Body: body}
+	case http.StatusNotFound:
+		return &goerror.NotFound{Body: body}
+	case http.StatusMethodNotAllowed:
+		return &goerror.MethodNotAllowed{Body: body}
+	case http.StatusNotAcceptable:
+		return &goerror.NotAcceptable{Body: body}
+	case http.StatusProxyAuthRequired:
+		return &goerror.ProxyAuthRequired{Body: body}
+	case http.StatusRequestTimeout:
+		return &goerror.RequestTimeout{Body: body}
+	case http.StatusConflict:
+		return &goerror.Conflict{Body: body}
+	case http.StatusGone:
+		return &goerror.Gone{Body: body}
+	case http.StatusLengthRequired:
+		return &goerror.LengthRequired{Body: body}
+	case http.StatusPreconditionFailed:
+		return &goerror.PreconditionFailed{Body: body}
+	case http.StatusRequestEntityTooLarge:
+		return &goerror.RequestEntityTooLarge{Body: body}
+	case http.StatusRequestURITooLong:
+		return &goerror.RequestURITooLong{Body: body}
+	case http.StatusUnsupportedMediaType:
+		return &goerror.UnsupportedMediaType{Body: body}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return &goerror.RequestedRangeNotSatisfiable{Body: body}
+	case http.StatusExpectationFailed:
+		return &goerror.ExpectationFailed{Body: body}
+	case http.StatusTeapot:
+		return &goerror.Teapot{Body: body}
+	case http.StatusMisdirectedRequest:
+		return &goerror.MisdirectedRequest{Body: body}
+	case http.StatusUnprocessableEntity:
+		return &goerror.UnprocessableEntity{Body: body}
+	case http.StatusLocked:
+		return &goerror.Locked{Body: body}
+	case http.StatusFailedDependency:
+		return &goerror.FailedDependency{Body: body}
+	case http.StatusTooEarly:
+		return &goerror.TooEarly{Body: body}
+	case http.StatusUpgradeRequired:
+		return &goerror.UpgradeRequired{Body: body}
+	case http.StatusPreconditionRequired:
+		return &goerror.PreconditionRequired{Body: body}
+	case http.StatusTooManyRequests:
+		return &goerror.TooManyRequests{Body: body}
+	case http.StatusRequestHeaderFieldsTooLarge:
+		return &goerror.RequestHeaderFieldsTooLarge{Body: body}
+	case http.StatusUnavailableForLegalReasons:
+		return &goerror.UnavailableForLegalReasons{Body: body}
+	case http.StatusInternalServerError:
+		return &goerror.InternalServerError{Body: body}
+	case http.StatusNotImplemented:
+		return &goerror.NotImplemented{Body: body}
+	case http.StatusBadGateway:
+		return &goerror.BadGateway{Body: body}
+	case http.StatusServiceUnavailable:
+		return &goerror.ServiceUnavailable{Body: body}
+	case http.StatusGatewayTimeout:
+		return &goerror.GatewayTimeout{Body: body}
+	case http.StatusHTTPVersionNotSupported:
+		return &goerror.HTTPVersionNotSupported{Body: body}
+	case http.StatusVariantAlsoNegotiates:
+		return &goerror.VariantAlsoNegotiates{Body: body}
+	case http.StatusInsufficientStorage:
+		return &goerror.InsufficientStorage{Body: body}
+	case http.StatusLoopDetected:
+		return &goerror.LoopDetected{Body: body}
+	case http.StatusNotExtended:
+		return &goerror.NotExtended{Body: body}
+	case http.StatusNetworkAuthenticationRequired:
+		return &goerror.NetworkAuthenticationRequired{Body: body}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package fiberhandler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Operations reported by HandledEvent for the methods of mutation routes.
+const (
+	OperationCreated = "created"
+	OperationUpdated = "updated"
+	OperationDeleted = "deleted"
+)
+
+// RouteEvent declares the entity changed by a mutation route, see Emits.
+type RouteEvent struct {
+	Entity    string `json:"entity"`
+	IDParam   string `json:"id_param,omitempty"`
+	Operation string `json:"operation,omitempty"`
+}
+
+// HandledEvent is published after a successful mutation on a route declaring Emits, e.g. to feed the
+// projectors of CQRS read models.
+type HandledEvent struct {
+	Entity     string    `json:"entity"`
+	ID         string    `json:"id,omitempty"`
+	Operation  string    `json:"operation"`
+	Subject    string    `json:"subject,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventPublisher delivers handled events to a message bus.
+type EventPublisher interface {
+	Publish(ctx context.Context, event HandledEvent) error
+}
+
+// EventPublisherFunc adapts a function to EventPublisher.
+type EventPublisherFunc func(ctx context.Context, event HandledEvent) error
+
+// Publish implements EventPublisher.
+func (f EventPublisherFunc) Publish(ctx context.Context, event HandledEvent) error {
+	return f(ctx, event)
+}
+
+// Identifiable is implemented by results of routes whose entity ID is not a path parameter, e.g. the
+// entity returned by a create.
+type Identifiable interface {
+	EntityID() string
+}
+
+type eventPublisher struct {
+	publisher EventPublisher
+	subject   func(claims any) string
+}
+
+// Emits declares the entity the route changes, the ID is read from the idParam path parameter or from a
+// result implementing Identifiable. The operation follows the method: POST created, PUT and PATCH
+// updated, DELETE deleted.
+func Emits(entity string, idParam ...string) RouteOption {
+	return func(r *Route) {
+		r.Event = &RouteEvent{Entity: entity}
+		if len(idParam) > 0 {
+			r.Event.IDParam = idParam[0]
+		}
+		r.Event.Operation = operationOf(r.Method)
+	}
+}
+
+// WithEventPublisher publishes a HandledEvent to publisher after every successful mutation on routes
+// declaring Emits. subject names the caller in the event, it may be nil. Publishing errors are logged
+// and do not change the response.
+func WithEventPublisher[T any](publisher EventPublisher, subject func(claims *T) string) Option {
+	events := &eventPublisher{
+		publisher: publisher,
+		subject: func(claims any) string {
+			c, _ := claims.(*T)
+			if subject == nil || c == nil {
+				return ""
+			}
+			return subject(c)
+		},
+	}
+
+	return func(o *options) {
+		o.events = events
+	}
+}
+
+func (e *eventPublisher) publish(c *fiber.Ctx, claims any, data any) {
+	route := CurrentRoute(c)
+	if route == nil || route.Event == nil || route.Method == http.MethodGet || route.Method == http.MethodHead {
+		return
+	}
+	status := c.Response().StatusCode()
+	if status >= http.StatusBadRequest {
+		return
+	}
+
+	event := HandledEvent{
+		Entity:     route.Event.Entity,
+		Operation:  route.Event.Operation,
+		Subject:    e.subject(claims),
+		Method:     route.Method,
+		Path:       route.Path,
+		Status:     status,
+		OccurredAt: time.Now().UTC(),
+	}
+	if route.Event.IDParam != "" {
+		event.ID = c.Params(route.Event.IDParam)
+	}
+	if event.ID == "" {
+		event.ID = entityID(data)
+	}
+
+	if err := e.publisher.Publish(c.UserContext(), event); err != nil {
+		slog.Error("Failed to publish event", slog.String("entity", event.Entity), slog.String("id", event.ID), slog.String("error", err.Error()))
+	}
+}
+
+func entityID(data any) string {
+	switch result := data.(type) {
+	case *Result:
+		return entityID(result.Data)
+	case *RawResult:
+		return entityID(result.Data)
+	case Identifiable:
+		return result.EntityID()
+	}
+	return ""
+}
+
+func operationOf(method string) string {
+	switch method {
+	case http.MethodPost:
+		return OperationCreated
+	case http.MethodPut, http.MethodPatch:
+		return OperationUpdated
+	case http.MethodDelete:
+		return OperationDeleted
+	}
+	return strings.ToLower(method)
+}
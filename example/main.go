@@ -40,6 +40,8 @@ type PostRequest struct {
 }
 
 func main() {
+	fiberhandler.MustValidateClaims[Claims]()
+
 	app := fiber.New()
 	app.Use(logger.New())
 
@@ -2,14 +2,19 @@ package fiberhandler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
+	"runtime/debug"
+	"sync"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
-	"github.com/prongbang/fibererror"
-	"github.com/prongbang/goerror"
 	"github.com/prongbang/gopkg/core"
 	"github.com/prongbang/gopkg/multipartx"
 	"github.com/prongbang/gopkg/streamx"
@@ -21,43 +26,119 @@ type DoFunc func(ctx context.Context) (any, error)
 type ApiHandler interface {
 	Do(c *fiber.Ctx, requestPtr any, validateRequest bool, doFunc DoFunc) error
 	DoMultipart(c *fiber.Ctx, requestPtr any, validateRequest bool, allowedTypes []string, doFunc DoFunc) error
+	DoSSE(c *fiber.Ctx, requestPtr any, validateRequest bool, sseFunc SSEFunc) error
+	DoBatch(c *fiber.Ctx) error
+	DoAsync(c *fiber.Ctx, requestPtr any, validateRequest bool, doFunc DoFunc) error
+	DoWebhook(c *fiber.Ctx, webhook *Webhook, requestPtr any, validateRequest bool, doFunc DoFunc) error
+	DoPatch(c *fiber.Ctx, requestPtr any, validateRequest bool, doFunc DoFunc) error
+	SendError(c *fiber.Ctx, err error) error
+	With(opts ...Option) ApiHandler
 }
 
 type apiHandler[T any] struct {
-	Response    fibererror.Response
-	Validate    *validator.Validate
-	TokenParser *TokenParser[T]
+	Response      Encoder
+	Validate      Validator
+	TokenParser   *TokenParser[T]
+	Parser        RequestParser
+	Authenticator Authenticator[T]
+	options       options
 }
 
-func (h *apiHandler[T]) getUserRequestInfo(c *fiber.Ctx) *T {
-	return h.getRequestInfo(c, func(c *fiber.Ctx) string {
-		if multipartx.IsMultipartForm(c) {
-			return c.FormValue("token")
+// With returns a copy of the handler with the given options applied.
+func (h *apiHandler[T]) With(opts ...Option) ApiHandler {
+	handler := *h
+	for _, opt := range opts {
+		opt(&handler.options)
+	}
+	handler.options.dropPersonalizedCache()
+	return &handler
+}
+
+type authenticatedKey struct{}
+
+// authentication is the outcome of getUserRequestInfo, kept in the locals of the request so the caller
+// is authenticated once.
+type authentication[T any] struct {
+	claims *T
+	err    error
+}
+
+// getUserRequestInfo returns the claims of the caller, nil for an anonymous request. Only rejected
+// tokens and revocation check failures are returned, other authentication failures leave the request
+// anonymous.
+func (h *apiHandler[T]) getUserRequestInfo(c *fiber.Ctx) (*T, error) {
+	if done, ok := c.Locals(authenticatedKey{}).(authentication[T]); ok {
+		return done.claims, done.err
+	}
+	claims, err := h.authenticate(c)
+	c.Locals(authenticatedKey{}, authentication[T]{claims: claims, err: err})
+	return claims, err
+}
+
+func (h *apiHandler[T]) authenticate(c *fiber.Ctx) (*T, error) {
+	if h.Authenticator != nil {
+		claims, err := h.Authenticator.Authenticate(c)
+		if err != nil {
+			slog.Error("Failed to authenticate request", h.redactor().errorAttr(err))
+			return nil, tokenError(err)
 		}
-		return h.getRequestToken(c)
-	})
+		return claims, nil
+	}
+
+	return h.getRequestInfo(c, h.lookupToken)
 }
 
-func (h *apiHandler[T]) getRequestInfo(c *fiber.Ctx, onRequestToken func(c *fiber.Ctx) string) *T {
-	tequestToken := onRequestToken(c)
+// lookupToken returns the token of the request for the TokenParser.
+func (h *apiHandler[T]) lookupToken(c *fiber.Ctx) string {
+	if multipartx.IsMultipartForm(c) {
+		if token := h.formValue(c, h.tokenField()); token != "" {
+			return token
+		}
+		return h.cookieToken(c)
+	}
+	return h.getRequestToken(c)
+}
+
+func (h *apiHandler[T]) getRequestInfo(c *fiber.Ctx, onRequestToken func(c *fiber.Ctx) string) (*T, error) {
+	return h.parseToken(c.UserContext(), onRequestToken(c))
+}
+
+// parseToken returns the claims of a token, nil for an empty token.
+func (h *apiHandler[T]) parseToken(ctx context.Context, tequestToken string) (*T, error) {
 	if core.IsEmpty(tequestToken) {
-		return nil
+		return nil, nil
 	}
 
 	tokenData, err := (*h.TokenParser).ParseToken(tequestToken)
 	if err != nil {
-		slog.Error("Failed to parse token", slog.String("error", err.Error()))
-		return nil
+		slog.Error("Failed to parse token", h.redactor().errorAttr(err))
+		return nil, tokenError(err)
 	}
-	return tokenData
+	if err := h.checkRevocation(ctx, tequestToken); err != nil {
+		return nil, err
+	}
+	return tokenData, nil
+}
+
+// tokenError returns err when it is a TokenError, nil otherwise.
+func tokenError(err error) error {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr
+	}
+	return nil
 }
 
 func (h *apiHandler[T]) getRequestToken(c *fiber.Ctx) string {
-	requestToken := core.ExtractToken(core.Authorization(c))
+	requestToken := h.headerToken(c)
+	if core.IsEmpty(requestToken) && isWebSocketUpgrade(c) {
+		return c.Query(h.tokenField())
+	}
 	if core.IsEmpty(requestToken) {
-		accessToken := core.AccessToken{}
-		_ = c.BodyParser(&accessToken)
-		return accessToken.Token
+		requestToken = h.cookieToken(c)
+	}
+	if core.IsEmpty(requestToken) {
+		return h.bodyToken(c)
 	}
 	return requestToken
 }
@@ -71,113 +152,587 @@ func (h *apiHandler[T]) DoMultipart(c *fiber.Ctx, requestPtr any, validateReques
 		return nil
 	}
 
+	bindContext(c)
+	h.bindLocale(c)
+	defer h.startTrace(c)()
+	defer h.observe(c)()
+	defer h.audit(c)()
+
+	if err := h.limitRate(c); err != nil {
+		return h.SendError(c, err)
+	}
+
+	if err := h.beforeParse(c); err != nil {
+		return h.SendError(c, err)
+	}
+
 	// Ensure multipart form is parsed
-	if _, err := c.MultipartForm(); err != nil {
-		slog.Error("Invalid request", slog.String("error", err.Error()))
-		return h.Response.With(c).Response(goerror.NewBadRequest())
+	var form *multipart.Form
+	cleanup := func() {}
+	err := h.trace(c, SpanParse, func() (err error) {
+		form, cleanup, err = h.parseMultipartForm(c)
+		return err
+	})
+	defer cleanup()
+	if err != nil {
+		slog.Error("Invalid request", h.redactor().errorAttr(err))
+		return h.SendError(c, NewBadRequestError())
+	}
+
+	if bindable, ok := requestPtr.(Bindable); ok {
+		if err := h.bindRequest(c, bindable); err != nil {
+			return h.SendError(c, err)
+		}
+		return h.execute(c, requestPtr, validateRequest, doFunc)
 	}
 
 	// Validate type assertion for Multipart Request
 	multipartReq, ok := requestPtr.(multipartx.Request)
 	if !ok {
 		slog.Error("Invalid request", slog.String("error", "the task requires implementing the multipartx.Request"))
-		return h.Response.With(c).Response(goerror.NewBadRequest("Invalid request type"))
+		return h.SendError(c, NewBadRequestError("Invalid request type"))
 	}
 
 	// Process form fields
 	for fieldName, fieldPtr := range multipartReq.FormFields() {
-		if err := typex.SetField(c.FormValue(fieldName), fieldPtr); err != nil {
-			slog.Error("Invalid request", slog.String("error", err.Error()))
-			return h.Response.With(c).Response(goerror.NewBadRequest(fmt.Sprintf("Invalid value for field '%s': %v", fieldName, err)))
+		value := h.formValue(c, fieldName)
+		if h.options.formFieldLimits != nil {
+			if err := h.options.formFieldLimits.check(fieldName, value); err != nil {
+				slog.Error("Invalid request", h.redactor().errorAttr(err))
+				return h.SendError(c, err)
+			}
+		}
+		if converted, err := convertField(value, fieldPtr); converted || err != nil {
+			if err != nil {
+				slog.Error("Invalid request", h.redactor().errorAttr(err))
+				return h.SendError(c, NewBadRequestError(fmt.Sprintf("Invalid value for field '%s': %v", fieldName, err)))
+			}
+			continue
+		}
+		if err := typex.SetField(value, fieldPtr); err != nil {
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			return h.SendError(c, NewBadRequestError(fmt.Sprintf("Invalid value for field '%s': %v", fieldName, err)))
+		}
+	}
+
+	// Process JSON part
+	if h.options.multipartJSONPart != "" {
+		if err := h.parseMultipartJSONPart(form, requestPtr); err != nil {
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			message := fmt.Sprintf("Invalid JSON in part '%s'", h.options.multipartJSONPart)
+			if detail := describeParseError(err); detail != "" {
+				message += ": " + detail
+			}
+			return h.SendError(c, NewBadRequestError(message))
 		}
 	}
 
 	// Process file fields with optimized allocation
 	var allowedMimeTypes map[string]bool
 	if validateRequest && len(allowedTypes) > 0 {
-		allowedMimeTypes = make(map[string]bool, len(allowedTypes))
-		for _, v := range allowedTypes {
-			allowedMimeTypes[v] = true
+		allowedMimeTypes = mimeSet(allowedTypes)
+	}
+
+	acceptFile := func(fieldName string) *multipart.FileHeader {
+		fileHeader, err := h.formFile(c, fieldName)
+		if err != nil {
+			return nil
 		}
+		if validateRequest && allowedMimeTypes != nil && multipartx.ValidateMimeType(fileHeader, allowedMimeTypes) != nil {
+			return nil
+		}
+		return fileHeader
 	}
 
+	accepted := make(map[string]*multipart.FileHeader)
 	for fieldName, filePtr := range multipartReq.FileFields() {
-		if fileHeader, err := c.FormFile(fieldName); err == nil {
-			if validateRequest && allowedMimeTypes != nil {
-				if multipartx.ValidateMimeType(fileHeader, allowedMimeTypes) == nil {
-					*filePtr = fileHeader
-				}
-			} else {
-				*filePtr = fileHeader
+		if fileHeader := acceptFile(fieldName); fileHeader != nil {
+			*filePtr = fileHeader
+			accepted[fieldName] = fileHeader
+		}
+	}
+
+	// Files of storage fields are streamed to the sink instead of being bound
+	storageReq, _ := requestPtr.(StorageRequest)
+	toStore := make(map[string]*multipart.FileHeader)
+	if storageReq != nil {
+		for fieldName := range storageReq.StorageFields() {
+			if fileHeader := acceptFile(fieldName); fileHeader != nil {
+				toStore[fieldName] = fileHeader
+				accepted[fieldName] = fileHeader
 			}
 		}
 	}
 
 	// Validate request if needed
-	if validateRequest {
-		if err := h.Validate.Struct(requestPtr); err != nil {
-			slog.Error("Invalid request", slog.String("error", err.Error()))
-			return h.Response.With(c).Response(NewDataInvalidError())
+	claims, err := h.prepareRequest(c, requestPtr, validateRequest)
+	if err != nil {
+		return h.SendError(c, err)
+	}
+
+	if err := h.inspectUploads(c.UserContext(), accepted); err != nil {
+		return h.SendError(c, err)
+	}
+
+	succeeded := false
+	if storageReq != nil {
+		stored, err := h.storeUploads(c.UserContext(), storageReq, toStore)
+		if err != nil {
+			slog.Error("Failed to store file", h.redactor().errorAttr(err))
+			return h.SendError(c, err)
 		}
+		defer func() {
+			if !succeeded {
+				h.discardUploads(c.UserContext(), stored)
+			}
+		}()
 	}
 
-	requestInfo := &core.RequestInfo[T]{
-		Claims: h.getUserRequestInfo(c),
+	data, err := h.callDoFunc(c, doFunc)
+	if err != nil {
+		slog.Error("Invalid request", h.redactor().errorAttr(err))
+		return h.SendError(c, err)
 	}
 
-	reqModel, ok := requestPtr.(core.Request[T])
-	if ok {
-		reqModel.SetRequestInfo(requestInfo)
+	if h.options.fileCommit != nil {
+		for fieldName, filePtr := range multipartReq.FileFields() {
+			if *filePtr == nil {
+				continue
+			}
+			if err := h.options.fileCommit(c.UserContext(), fieldName, *filePtr); err != nil {
+				slog.Error("Failed to commit file", slog.String("field", fieldName), h.redactor().errorAttr(err))
+				return h.SendError(c, err)
+			}
+		}
+	}
+
+	succeeded = true
+	return h.sendResult(c, claims, data)
+}
+
+func (h *apiHandler[T]) parseMultipartJSONPart(form *multipart.Form, requestPtr any) error {
+	name := h.options.multipartJSONPart
+	codec := h.options.jsonCodec
+	if codec == nil {
+		codec = GoccyJSON
+	}
+	if values := form.Value[name]; len(values) > 0 {
+		return codec.Unmarshal([]byte(values[0]), requestPtr)
 	}
 
-	data, err := doFunc(c.UserContext())
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil
+	}
+
+	file, err := files[0].Open()
 	if err != nil {
-		slog.Error("Invalid request", slog.String("error", err.Error()))
-		return h.Response.With(c).Response(err)
+		return err
 	}
+	defer file.Close()
 
-	return h.Response.With(c).Response(goerror.NewOK(data))
+	if h.options.jsonCodec != nil {
+		body, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		return codec.Unmarshal(body, requestPtr)
+	}
+	return json.NewDecoder(file).Decode(requestPtr)
 }
 
 func (h *apiHandler[T]) Do(c *fiber.Ctx, requestPtr any, validateRequest bool, doFunc DoFunc) error {
-	err := h.requestParserIfNeeded(c, requestPtr)
+	bindContext(c)
+	h.bindLocale(c)
+	defer h.startTrace(c)()
+	defer h.observe(c)()
+	defer h.audit(c)()
+
+	if err := h.limitRate(c); err != nil {
+		return h.SendError(c, err)
+	}
+
+	if err := h.beforeParse(c); err != nil {
+		return h.SendError(c, err)
+	}
+
+	err := h.trace(c, SpanParse, func() error {
+		return h.requestParserIfNeeded(c, requestPtr)
+	})
+	if err != nil {
+		return h.SendError(c, err)
+	}
+
+	return h.execute(c, requestPtr, validateRequest, doFunc)
+}
+
+// execute runs the pipeline of Do on a bound request.
+func (h *apiHandler[T]) execute(c *fiber.Ctx, requestPtr any, validateRequest bool, doFunc DoFunc) error {
+	claims, err := h.prepareRequest(c, requestPtr, validateRequest)
+	if err != nil {
+		return h.SendError(c, err)
+	}
+
+	// Replays and cached responses are kept per caller
+	var caller string
+	if h.options.idempotency != nil || h.options.responseCache != nil {
+		if caller, err = h.callerIdentity(c, claims); err != nil {
+			return h.SendError(c, err)
+		}
+	}
+
+	if h.options.idempotency != nil {
+		claim, answered, err := h.options.idempotency.begin(c, caller)
+		if answered {
+			if err != nil {
+				return h.SendError(c, err)
+			}
+			return nil
+		}
+		if claim != nil {
+			defer claim.finish(c)
+		}
+	}
+
+	var cached *cacheState
+	if h.options.responseCache != nil {
+		var served bool
+		if cached, served, err = h.options.responseCache.serveCached(c, caller); served {
+			return err
+		}
+	}
+
+	data, err := h.callDoFunc(c, doFunc)
+	if err != nil {
+		slog.Error("Invalid request", h.redactor().errorAttr(err))
+		return h.SendError(c, err)
+	}
+
+	if err := h.sendResult(c, claims, data); err != nil || cached == nil {
+		return err
+	}
+	cached.store(c)
+	return nil
+}
+
+// callerIdentity returns a hash of the claims of the caller, whatever authenticated it, "" for an
+// anonymous caller. Lazy claims are parsed.
+func (h *apiHandler[T]) callerIdentity(c *fiber.Ctx, claims *T) (string, error) {
+	if claims == nil && h.options.lazyClaims {
+		var err error
+		if claims, err = resolveClaims[T](c.UserContext()); err != nil {
+			return "", err
+		}
+	}
+	if claims == nil {
+		return "", nil
+	}
+
+	identity, err := json.Marshal(claims)
+	if err != nil {
+		slog.Error("Failed to identify caller", h.redactor().errorAttr(err))
+		return "", NewInternalError()
+	}
+	sum := sha256.Sum256(identity)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// callDoFunc runs doFunc with the request context when the circuit breaker allows it. A panic of doFunc
+// is logged with its stack and returned as the error of a known classifier or an InternalError, so it is
+// sent in the configured error format instead of the bare 500 of the fiber recover middleware.
+func (h *apiHandler[T]) callDoFunc(c *fiber.Ctx, doFunc DoFunc) (data any, err error) {
+	done, err := h.allowCall(c)
 	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		done(err)
+	}()
+
+	call := func() {
+		ctx, cancel := h.startTimeout(c.UserContext())
+		ctx, stopWatch := h.watchDisconnect(c, ctx)
+		ctx, release := context.WithCancel(ctx)
+		defer func() {
+			stop := sync.OnceFunc(func() {
+				release()
+				stopWatch(true)
+				cancel()
+			})
+			// Lazy results keep the context and the disconnect watch until their stream ends
+			if lazyResult(data) {
+				holdStream(c, stop)
+				return
+			}
+			stop()
+		}()
+
+		defer func() {
+			if value := recover(); value != nil {
+				slog.Error("Recovered panic",
+					slog.Any("panic", h.redactor().LogValuer(value)),
+					slog.String("stack", string(debug.Stack())),
+				)
+				classified, ok := h.options.classifyPanic(value)
+				if !ok {
+					classified = NewInternalError()
+				}
+				data, err = nil, classified
+			}
+		}()
+
+		data, err = h.retried(c, doFunc)(ctx)
+		if disconnected(ctx) {
+			data, err = nil, ErrClientDisconnected
+		}
+		err = timeoutError(ctx, err)
+		if claimsErr := lazyClaimsError[T](ctx); claimsErr != nil && err == nil {
+			data, err = nil, claimsErr
+		}
+	}
+
+	_ = h.trace(c, SpanDo, func() error {
+		if h.options.allocationBudget != nil {
+			h.options.allocationBudget.measure(c, call)
+		} else {
+			call()
+		}
 		return err
+	})
+	return data, err
+}
+
+// prepareRequest validates a bound request and attaches the request info. It returns the claims of the
+// caller, the returned error has not been sent yet.
+func (h *apiHandler[T]) prepareRequest(c *fiber.Ctx, requestPtr any, validateRequest bool) (*T, error) {
+	h.bindReferences(c)
+
+	if err := h.sanitize(requestPtr); err != nil {
+		slog.Error("Invalid sanitizer", h.redactor().errorAttr(err))
+		return nil, err
+	}
+
+	// Fields left out of a merge patch stay unchanged, they are not defaulted
+	if _, patch := FieldMaskFromContext(c.UserContext()); !patch {
+		if err := applyDefaults(requestPtr); err != nil {
+			slog.Error("Invalid default", h.redactor().errorAttr(err))
+			return nil, err
+		}
+	}
+
+	if err := bindListQuery(c, requestPtr); err != nil {
+		slog.Error("Invalid request", h.redactor().errorAttr(err))
+		return nil, err
+	}
+
+	if pageable, ok := requestPtr.(pageRequest); ok {
+		if err := pageable.normalizePage(h.options.pageLimits); err != nil {
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			return nil, err
+		}
+	}
+
+	if computed, ok := requestPtr.(Computed); ok {
+		if err := computed.AfterBind(c.UserContext()); err != nil {
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			return nil, err
+		}
 	}
 
 	if validateRequest {
-		err := h.Validate.Struct(requestPtr)
+		err := h.trace(c, SpanValidate, func() error {
+			return h.validateStruct(c, requestPtr)
+		})
 		if err != nil {
-			slog.Error("Invalid request", slog.String("error", err.Error()))
-			return h.Response.With(c).Response(NewDataInvalidError())
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			return nil, newValidationError(err)
 		}
 	}
 
-	requestInfo := &core.RequestInfo[T]{
-		Claims: h.getUserRequestInfo(c),
+	var claims *T
+	if !h.deferClaims(c, requestPtr) {
+		err := h.trace(c, SpanToken, func() (err error) {
+			claims, err = h.getUserRequestInfo(c)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if claims != nil {
+			c.SetUserContext(ContextWithClaims(c.UserContext(), claims))
+		}
+	}
+
+	if err := h.resolveTenant(c, requestPtr, claims); err != nil {
+		return nil, err
+	}
+
+	if err := h.checkCSRF(c); err != nil {
+		slog.Error("Unauthorized request", h.redactor().errorAttr(err))
+		return nil, err
+	}
+
+	if err := h.authorize(c, claims); err != nil {
+		slog.Error("Unauthorized request", h.redactor().errorAttr(err))
+		return nil, err
 	}
 
 	reqModel, ok := requestPtr.(core.Request[T])
 	if ok {
-		reqModel.SetRequestInfo(requestInfo)
+		reqModel.SetRequestInfo(&core.RequestInfo[T]{Claims: claims})
+	}
+
+	if err := h.afterValidate(c, requestPtr); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (h *apiHandler[T]) sendResult(c *fiber.Ctx, claims *T, data any) error {
+	// Releases the context of a lazy result that is not streamed
+	defer func() {
+		takeStream(c)()
+	}()
+
+	if claims == nil && h.options.lazyClaims && (h.options.events != nil || h.options.fieldACL != nil || h.options.watermark != nil) {
+		resolved, err := resolveClaims[T](c.UserContext())
+		if err != nil {
+			return h.SendError(c, err)
+		}
+		claims = resolved
+	}
+	if h.options.events != nil {
+		defer h.options.events.publish(c, claims, data)
 	}
 
-	data, err := doFunc(c.UserContext())
+	data, err := h.beforeResponse(c, data)
 	if err != nil {
-		slog.Error("Invalid request", slog.String("error", err.Error()))
-		return h.Response.With(c).Response(err)
+		return h.SendError(c, err)
 	}
 
-	streamData, ok := data.(*streamx.Stream)
-	if ok {
-		return h.sendStream(c, streamData)
+	if status, ok := bodylessStatus(c, data); ok {
+		return sendBodyless(c, data, status)
+	}
+
+	if h.options.pageLinks {
+		setPageLinks(c, data)
+	}
+
+	if h.options.fieldACL != nil {
+		var err error
+		data, err = h.options.fieldACL.apply(data, claims)
+		if err != nil {
+			slog.Error("Failed to filter response", h.redactor().errorAttr(err))
+			return h.SendError(c, err)
+		}
+	}
+
+	if h.options.sparseFieldsets != nil {
+		var err error
+		data, err = h.options.sparseFieldsets.apply(c, data)
+		if err != nil {
+			slog.Error("Failed to prune response", h.redactor().errorAttr(err))
+			return h.SendError(c, err)
+		}
+	}
+
+	if h.options.watermark != nil {
+		var err error
+		data, err = h.options.watermark.apply(data, claims, func(v any) ([]byte, error) {
+			return h.marshalJSON(c, v)
+		})
+		if err != nil {
+			slog.Error("Failed to watermark response", h.redactor().errorAttr(err))
+			return h.SendError(c, err)
+		}
+	}
+
+	switch result := data.(type) {
+	case *streamx.Stream:
+		return h.sendStream(c, &StreamResult{Stream: result})
+	case *StreamResult:
+		return h.sendStream(c, result)
+	case *Result:
+		return h.sendStatusResult(c, result)
+	case *RawResult:
+		return h.sendRaw(c, result.Data)
+	case *RenderResult:
+		return h.sendRender(c, result)
+	case *NDJSONResult:
+		return h.sendNDJSON(c, result)
+	case <-chan any:
+		return h.sendNDJSON(c, NDJSON(result))
+	}
+
+	if h.options.rawResponse {
+		return h.sendRaw(c, data)
+	}
+
+	return h.sendEnvelope(c, data)
+}
+
+// SendError sends err through the handler's error response, errors implementing StatusCoder
+// are sent with their own status. Rate limit errors of downstream calls are sent as a
+// TooManyRequestsError keeping their Retry-After.
+func (h *apiHandler[T]) SendError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, ErrClientDisconnected) {
+		c.Locals(sentErrorKey{}, err)
+		c.Status(StatusClientClosedRequest)
+		return nil
+	}
+
+	err = h.validationStatus(translateDownstreamError(err))
+	setRetryAfter(c, err)
+	c.Locals(sentErrorKey{}, err)
+	if h.options.htmlErrors != nil && wantsHTML(c) {
+		if sent, sendErr := h.sendHTMLError(c, err); sent {
+			return sendErr
+		}
+		c.Response().ResetBody()
+	}
+	if h.options.errorFormat != ErrorFormatDefault {
+		return h.sendFormattedError(c, err)
+	}
+	if h.useEnvelope(c) == EnvelopeV2 {
+		return h.sendEnvelopeV2Error(c, err)
 	}
+	return h.sendDefaultError(c, err)
+}
 
-	return h.Response.With(c).Response(goerror.NewOK(data))
+func (h *apiHandler[T]) sendDefaultError(c *fiber.Ctx, err error) error {
+	var statusErr StatusCoder
+	if errors.As(err, &statusErr) {
+		if mapped := goError(statusErr); mapped != nil {
+			return h.Response.With(c).Response(mapped)
+		}
+		return c.Status(statusErr.StatusCode()).JSON(statusErr)
+	}
+	return h.Response.With(c).Response(err)
 }
 
-func (h *apiHandler[T]) sendStream(c *fiber.Ctx, streamData *streamx.Stream) error {
-	streamx.AttachmentHeader(c, streamData.ContentType, streamData.Filename)
+func (h *apiHandler[T]) sendStream(c *fiber.Ctx, streamData *StreamResult) error {
+	setStreamHeaders(c, streamData)
+	setValidatorHeaders(c, streamData)
+	if notModified(c, streamData) {
+		if closer, ok := streamData.Data.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	if h.options.bandwidth != nil {
+		streamData = h.options.bandwidth.throttle(streamData)
+	}
+
+	if h.sendCompressed(c, streamData) {
+		return nil
+	}
+
+	if seeker, ok := streamData.Data.(io.ReadSeeker); ok {
+		return h.sendRange(c, streamData, seeker)
+	}
 	if streamData.Size != nil {
 		return c.SendStream(streamData.Data, *streamData.Size)
 	}
@@ -190,25 +745,41 @@ func (h *apiHandler[T]) requestParserIfNeeded(c *fiber.Ctx, requestPtr interface
 		return nil
 	}
 
+	if bindable, ok := requestPtr.(Bindable); ok {
+		return h.bindRequest(c, bindable)
+	}
+
+	if h.Parser != nil {
+		err := h.Parser.ParseRequest(c, requestPtr)
+		if err != nil {
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+		}
+		return err
+	}
+
 	switch c.Method() {
-	case http.MethodGet, http.MethodDelete:
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
 		err := c.QueryParser(requestPtr)
 		if err != nil {
-			slog.Error("Invalid request", slog.String("error", err.Error()))
-			return h.Response.With(c).Response(goerror.NewBadRequest())
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			return parseError(err)
 		}
 	default:
-		err := c.BodyParser(requestPtr)
+		if err := h.checkUnknownFields(c, requestPtr); err != nil {
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			return err
+		}
+		err := h.decodeBody(c, requestPtr)
 		if err != nil {
-			slog.Error("Invalid request", slog.String("error", err.Error()))
-			return h.Response.With(c).Response(goerror.NewBadRequest())
+			slog.Error("Invalid request", h.redactor().errorAttr(err))
+			return parseError(err)
 		}
 	}
 
 	return nil
 }
 
-func New[T any](response fibererror.Response, validate *validator.Validate, tokenParser ...TokenParser[T]) ApiHandler {
+func New[T any](response Encoder, validate Validator, tokenParser ...TokenParser[T]) ApiHandler {
 	var newTokenParser TokenParser[T]
 	if len(tokenParser) == 0 {
 		newTokenParser = NewJWTParser[T]()
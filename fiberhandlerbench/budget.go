@@ -0,0 +1,88 @@
+package fiberhandlerbench
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Budget is the allocation allowed per operation of a benchmark, zero fields are not checked. Time is
+// left out, it varies too much between machines to fail a build on.
+type Budget struct {
+	Allocs int64 `json:"allocs"`
+	Bytes  int64 `json:"bytes"`
+}
+
+// Result is the measure of a benchmark of the suite.
+type Result struct {
+	Name string
+	testing.BenchmarkResult
+}
+
+// Failed reports whether the benchmark failed, e.g. on an unexpected status.
+func (r Result) Failed() bool {
+	return r.N == 0
+}
+
+// Exceeds returns the measures of r above budget, e.g. "allocs/op 31 > 24".
+func (r Result) Exceeds(budget Budget) []string {
+	var exceeded []string
+	if budget.Allocs > 0 && r.AllocsPerOp() > budget.Allocs {
+		exceeded = append(exceeded, fmt.Sprintf("allocs/op %d > %d", r.AllocsPerOp(), budget.Allocs))
+	}
+	if budget.Bytes > 0 && r.AllocedBytesPerOp() > budget.Bytes {
+		exceeded = append(exceeded, fmt.Sprintf("B/op %d > %d", r.AllocedBytesPerOp(), budget.Bytes))
+	}
+	return exceeded
+}
+
+// Measure runs the benchmarks of suite with testing.Benchmark, which also works outside of go test.
+func Measure(suite []Benchmark) []Result {
+	results := make([]Result, 0, len(suite))
+	for _, bench := range suite {
+		results = append(results, Result{Name: bench.Name, BenchmarkResult: testing.Benchmark(bench.Func)})
+	}
+	return results
+}
+
+// Budgets returns the measures of results as budgets raised by headroom percent, to record a baseline.
+func Budgets(results []Result, headroom float64) map[string]Budget {
+	raise := func(n int64) int64 {
+		return int64(math.Ceil(float64(n) * (1 + headroom/100)))
+	}
+
+	budgets := make(map[string]Budget, len(results))
+	for _, result := range results {
+		if result.Failed() {
+			continue
+		}
+		budgets[result.Name] = Budget{Allocs: raise(result.AllocsPerOp()), Bytes: raise(result.AllocedBytesPerOp())}
+	}
+	return budgets
+}
+
+// CheckBudgets measures the benchmarks of suite having a budget and fails t for those above it or
+// failing:
+//
+//	func TestAllocations(t *testing.T) {
+//		fiberhandlerbench.CheckBudgets(t, fiberhandlerbench.Suite(), budgets)
+//	}
+func CheckBudgets(t testing.TB, suite []Benchmark, budgets map[string]Budget) {
+	t.Helper()
+
+	var budgeted []Benchmark
+	for _, bench := range suite {
+		if _, ok := budgets[bench.Name]; ok {
+			budgeted = append(budgeted, bench)
+		}
+	}
+	for _, result := range Measure(budgeted) {
+		if result.Failed() {
+			t.Errorf("%s: benchmark failed", result.Name)
+			continue
+		}
+		for _, exceeded := range result.Exceeds(budgets[result.Name]) {
+			t.Errorf("%s: %s", result.Name, exceeded)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Command fiberhandlerbench runs the benchmark suite of fiberhandler and checks its allocations against
+// a budget file, exiting with status 1 when a benchmark fails or exceeds its budget:
+//
+//	go run ./fiberhandlerbench/cmd/fiberhandlerbench -budgets budgets.json -update   # record a baseline
+//	go run ./fiberhandlerbench/cmd/fiberhandlerbench -budgets budgets.json           # check a change
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+	"github.com/prongbang/fiberhandler/fiberhandlerbench"
+)
+
+func main() {
+	bench := flag.String("bench", ".", "run the benchmarks matching this regexp")
+	budgetsPath := flag.String("budgets", "", "JSON file of the budgets, by benchmark name")
+	update := flag.Bool("update", false, "write the measures to the budgets file instead of checking them")
+	headroom := flag.Float64("headroom", 10, "percent added to the measures written by -update")
+	flag.Parse()
+
+	filter, err := regexp.Compile(*bench)
+	if err != nil {
+		log.Fatalf("invalid -bench: %v", err)
+	}
+	var suite []fiberhandlerbench.Benchmark
+	for _, benchmark := range fiberhandlerbench.Suite() {
+		if filter.MatchString(benchmark.Name) {
+			suite = append(suite, benchmark)
+		}
+	}
+
+	budgets := map[string]fiberhandlerbench.Budget{}
+	if *budgetsPath != "" && !*update {
+		content, err := os.ReadFile(*budgetsPath)
+		if err != nil {
+			log.Fatalf("read budgets: %v", err)
+		}
+		if err := json.Unmarshal(content, &budgets); err != nil {
+			log.Fatalf("decode budgets: %v", err)
+		}
+	}
+
+	results := fiberhandlerbench.Measure(suite)
+	failed := false
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "benchmark\tns/op\tB/op\tallocs/op\tbudget\t")
+	for _, result := range results {
+		status := ""
+		switch budget, ok := budgets[result.Name]; {
+		case result.Failed():
+			status, failed = "FAILED", true
+		case ok:
+			status = "ok"
+			if exceeded := result.Exceeds(budget); len(exceeded) > 0 {
+				status, failed = strings.Join(exceeded, ", "), true
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t\n", result.Name, result.NsPerOp(), result.AllocedBytesPerOp(), result.AllocsPerOp(), status)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *update {
+		if *budgetsPath == "" {
+			log.Fatal("-update needs -budgets")
+		}
+		content, err := json.MarshalIndent(fiberhandlerbench.Budgets(results, *headroom), "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*budgetsPath, append(content, '\n'), 0o644); err != nil {
+			log.Fatalf("write budgets: %v", err)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
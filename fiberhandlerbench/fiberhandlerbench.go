@@ -0,0 +1,336 @@
+// Package fiberhandlerbench benchmarks the request pipeline of fiberhandler. Requests are served by
+// the fasthttp handler of a fiber app, without network, and report their allocations:
+//
+//	func BenchmarkSuite(b *testing.B) {
+//		fiberhandlerbench.Run(b)
+//	}
+//
+// Run them with go test -bench . and compare runs with benchstat. Measure and CheckBudgets hold the
+// allocations of the suite to per-benchmark budgets, the fiberhandlerbench command records and checks
+// them outside of go test.
+package fiberhandlerbench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fibererror"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+	"github.com/prongbang/gopkg/core"
+	"github.com/prongbang/gopkg/streamx"
+	"github.com/valyala/fasthttp"
+)
+
+// Claims are the claims of the benchmarked handlers.
+type Claims struct {
+	Sub   string   `json:"sub"`
+	Roles []string `json:"roles"`
+}
+
+// Address is the nested struct of Order.
+type Address struct {
+	Street string `json:"street" query:"street" validate:"required" sanitize:"trim"`
+	City   string `json:"city" query:"city" validate:"required"`
+	Zip    string `json:"zip" query:"zip"`
+}
+
+// Item is a line of Order.
+type Item struct {
+	SKU      string   `json:"sku" validate:"required" sanitize:"upper"`
+	Quantity int      `json:"quantity" default:"1" validate:"min=1"`
+	Price    float64  `json:"price" validate:"gte=0"`
+	Tags     []string `json:"tags"`
+}
+
+// Order is the request of the binding benchmarks, the shape of a typical create endpoint: nested
+// structs, a slice, sanitize and default tags and validation rules.
+type Order struct {
+	core.RequestInfo[Claims] `json:"-"`
+	Customer                 string  `json:"customer" query:"customer" validate:"required" sanitize:"trim"`
+	Email                    string  `json:"email" query:"email" validate:"required,email" sanitize:"trim,lower"`
+	Note                     string  `json:"note" query:"note"`
+	Currency                 string  `json:"currency" query:"currency" default:"USD"`
+	Address                  Address `json:"address" query:"address"`
+	Items                    []Item  `json:"items" validate:"dive"`
+}
+
+// NewOrder returns an Order with n items.
+func NewOrder(n int) Order {
+	order := Order{
+		Customer: " Ada Lovelace ",
+		Email:    "Ada@Example.com",
+		Address:  Address{Street: " 12 Analytical Row ", City: "London", Zip: "N1"},
+	}
+	for range n {
+		order.Items = append(order.Items, Item{SKU: "sku-42", Quantity: 2, Price: 9.5, Tags: []string{"gift", "fragile"}})
+	}
+	return order
+}
+
+// NewHandler returns the handler of the benchmarks, with the default encoder and validator.
+func NewHandler(opts ...fiberhandler.Option) fiberhandler.ApiHandler {
+	return fiberhandler.New[Claims](fibererror.New(), validator.New()).With(opts...)
+}
+
+// Request is a request served by Serve.
+type Request struct {
+	Method      string
+	URI         string
+	ContentType string
+	Body        []byte
+	Header      map[string]string
+}
+
+// Serve benchmarks handler mounted on route serving req, failing b on a status other than want.
+func Serve(b *testing.B, route string, handler fiber.Handler, req Request, want int) {
+	b.Helper()
+
+	app := fiber.New()
+	app.Add(req.Method, route, handler)
+	serve := app.Handler()
+
+	ctx := &fasthttp.RequestCtx{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		// Like fasthttp between requests, the user values hold the context of fiber
+		ctx.Request.Reset()
+		ctx.Response.Reset()
+		ctx.ResetUserValues()
+		ctx.Request.Header.SetMethod(req.Method)
+		ctx.Request.SetRequestURI(req.URI)
+		if req.ContentType != "" {
+			ctx.Request.Header.SetContentType(req.ContentType)
+		}
+		for key, value := range req.Header {
+			ctx.Request.Header.Set(key, value)
+		}
+		ctx.Request.SetBody(req.Body)
+
+		serve(ctx)
+		// Streams are sent by fasthttp once the handler returns
+		if err := ctx.Response.BodyWriteTo(io.Discard); err != nil {
+			b.Fatalf("fiberhandlerbench: %s %s: %v", req.Method, req.URI, err)
+		}
+		if status := ctx.Response.StatusCode(); status != want {
+			b.Fatalf("fiberhandlerbench: %s %s: status = %d, want %d, body: %s", req.Method, req.URI, status, want, ctx.Response.Body())
+		}
+	}
+}
+
+// Upload is the request of the multipart benchmarks.
+type Upload struct {
+	Title string                `form:"title" validate:"required"`
+	File  *multipart.FileHeader `form:"file" validate:"required"`
+}
+
+// FormFields implements multipartx.Request.
+func (u *Upload) FormFields() map[string]any {
+	return map[string]any{"title": &u.Title}
+}
+
+// FileFields implements multipartx.Request.
+func (u *Upload) FileFields() map[string]**multipart.FileHeader {
+	return map[string]**multipart.FileHeader{"file": &u.File}
+}
+
+// Benchmark is a named benchmark of the suite, e.g. "Bind/JSON/20Items".
+type Benchmark struct {
+	Name string
+	Func func(b *testing.B)
+}
+
+// Suite returns the benchmarks of a handler created with opts: binding, Do with and without a token,
+// DoMultipart, token parsing and stream sending.
+func Suite(opts ...fiberhandler.Option) []Benchmark {
+	var suite []Benchmark
+	suite = append(suite, bindBenchmarks(opts)...)
+	suite = append(suite, doBenchmarks(opts)...)
+	suite = append(suite, multipartBenchmarks(opts)...)
+	suite = append(suite, tokenBenchmarks()...)
+	suite = append(suite, streamBenchmarks(opts)...)
+	return suite
+}
+
+// Run runs the suite as sub-benchmarks of b, select some with -bench, e.g. -bench 'Suite/Do/'.
+func Run(b *testing.B, opts ...fiberhandler.Option) {
+	for _, bench := range Suite(opts...) {
+		b.Run(bench.Name, bench.Func)
+	}
+}
+
+// Bind benchmarks Do binding, sanitizing, defaulting and validating an Order, as a JSON body with 1
+// and 20 items and as a query. The reflection metadata of the request type is compiled on its first
+// use, the allocations left are mostly those of the body decoder and the validator.
+func Bind(b *testing.B, opts ...fiberhandler.Option) {
+	for _, bench := range bindBenchmarks(opts) {
+		b.Run(strings.TrimPrefix(bench.Name, "Bind/"), bench.Func)
+	}
+}
+
+func bindBenchmarks(opts []fiberhandler.Option) []Benchmark {
+	handle := NewHandler(opts...)
+	handler := func(c *fiber.Ctx) error {
+		order := Order{}
+		return handle.Do(c, &order, true, func(ctx context.Context) (any, error) {
+			return nil, nil
+		})
+	}
+
+	var suite []Benchmark
+	for _, items := range []int{1, 20} {
+		suite = append(suite, Benchmark{Name: fmt.Sprintf("Bind/JSON/%dItems", items), Func: func(b *testing.B) {
+			body, err := json.Marshal(NewOrder(items))
+			if err != nil {
+				b.Fatal(err)
+			}
+			Serve(b, "/orders", handler, Request{
+				Method:      http.MethodPost,
+				URI:         "/orders",
+				ContentType: fiber.MIMEApplicationJSON,
+				Body:        body,
+			}, http.StatusOK)
+		}})
+	}
+
+	return append(suite, Benchmark{Name: "Bind/Query", Func: func(b *testing.B) {
+		Serve(b, "/orders", handler, Request{
+			Method: http.MethodGet,
+			URI:    "/orders?customer=Ada&email=ada@example.com&address.street=Row&address.city=London",
+		}, http.StatusOK)
+	}})
+}
+
+// doBenchmarks send an Order in the success envelope, to an anonymous caller and to a caller with a
+// bearer token.
+func doBenchmarks(opts []fiberhandler.Option) []Benchmark {
+	handle := NewHandler(opts...)
+	order := NewOrder(3)
+	handler := func(c *fiber.Ctx) error {
+		return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			return order, nil
+		})
+	}
+
+	return []Benchmark{
+		{Name: "Do/Anonymous", Func: func(b *testing.B) {
+			Serve(b, "/orders/42", handler, Request{Method: http.MethodGet, URI: "/orders/42"}, http.StatusOK)
+		}},
+		{Name: "Do/Token", Func: func(b *testing.B) {
+			token, err := fiberhandlertest.SignToken(Claims{Sub: "42", Roles: []string{"admin"}}, fiberhandlertest.Secret)
+			if err != nil {
+				b.Fatal(err)
+			}
+			Serve(b, "/orders/42", handler, Request{
+				Method: http.MethodGet,
+				URI:    "/orders/42",
+				Header: map[string]string{fiber.HeaderAuthorization: "Bearer " + token},
+			}, http.StatusOK)
+		}},
+	}
+}
+
+// multipartBenchmarks upload a 4 KiB text file with a form field.
+func multipartBenchmarks(opts []fiberhandler.Option) []Benchmark {
+	handle := NewHandler(opts...)
+	allowedTypes := []string{"text/plain; charset=utf-8"}
+	handler := func(c *fiber.Ctx) error {
+		upload := Upload{}
+		return handle.DoMultipart(c, &upload, true, allowedTypes, func(ctx context.Context) (any, error) {
+			return nil, nil
+		})
+	}
+
+	return []Benchmark{{Name: "DoMultipart/File4KiB", Func: func(b *testing.B) {
+		req, err := fiberhandlertest.Post("/uploads").
+			Field("title", "report").
+			File("file", "report.txt", bytes.Repeat([]byte("fiberhandler "), 4<<10/13), "text/plain").
+			Build()
+		if err != nil {
+			b.Fatal(err)
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			b.Fatal(err)
+		}
+		Serve(b, "/uploads", handler, Request{
+			Method:      http.MethodPost,
+			URI:         "/uploads",
+			ContentType: req.Header.Get(fiber.HeaderContentType),
+			Body:        body,
+		}, http.StatusOK)
+	}}}
+}
+
+// tokenBenchmarks parse a JWT with the default TokenParser, without and with validation of its
+// registered claims.
+func tokenBenchmarks() []Benchmark {
+	parse := func(parser fiberhandler.TokenParser[Claims]) func(b *testing.B) {
+		return func(b *testing.B) {
+			token, err := fiberhandlertest.SignToken(map[string]any{
+				"sub":   "42",
+				"roles": []string{"admin"},
+				"iss":   "fiberhandlerbench",
+				"exp":   time.Now().Add(time.Hour).Unix(),
+			}, fiberhandlertest.Secret)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				if _, err := parser.ParseToken(token); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+
+	return []Benchmark{
+		{Name: "TokenParsing/JWT", Func: parse(fiberhandler.NewJWTParser[Claims]())},
+		{Name: "TokenParsing/JWTValidation", Func: parse(fiberhandler.NewJWTParser[Claims](fiberhandler.JWTValidation{
+			Issuers:       []string{"fiberhandlerbench"},
+			RequireExpiry: true,
+		}))},
+	}
+}
+
+// streamBenchmarks send a 64 KiB attachment, whole and as a range.
+func streamBenchmarks(opts []fiberhandler.Option) []Benchmark {
+	handle := NewHandler(opts...)
+	payload := bytes.Repeat([]byte{0x2a}, 64<<10)
+	handler := func(c *fiber.Ctx) error {
+		return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			return fiberhandler.Attachment(&streamx.Stream{
+				Filename:    "export.bin",
+				ContentType: fiber.MIMEOctetStream,
+				Data:        bytes.NewReader(payload),
+			}), nil
+		})
+	}
+
+	return []Benchmark{
+		{Name: "SendStream/64KiB", Func: func(b *testing.B) {
+			Serve(b, "/export", handler, Request{Method: http.MethodGet, URI: "/export"}, http.StatusOK)
+		}},
+		{Name: "SendStream/Range", Func: func(b *testing.B) {
+			Serve(b, "/export", handler, Request{
+				Method: http.MethodGet,
+				URI:    "/export",
+				Header: map[string]string{fiber.HeaderRange: "bytes=1024-9215"},
+			}, http.StatusPartialContent)
+		}},
+	}
+}
@@ -0,0 +1,67 @@
+package fiberhandlerbench_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/prongbang/fiberhandler/fiberhandlerbench"
+)
+
+// budgetsFile holds the allocation budgets of the suite, record them again after an intended change:
+//
+//	go run ./fiberhandlerbench/cmd/fiberhandlerbench -budgets fiberhandlerbench/testdata/budgets.json -update
+const budgetsFile = "testdata/budgets.json"
+
+// group runs the benchmarks of the suite whose name starts with prefix.
+func group(b *testing.B, prefix string) {
+	for _, bench := range fiberhandlerbench.Suite() {
+		if name, ok := strings.CutPrefix(bench.Name, prefix); ok {
+			b.Run(name, bench.Func)
+		}
+	}
+}
+
+func BenchmarkBind(b *testing.B) {
+	group(b, "Bind/")
+}
+
+func BenchmarkDo(b *testing.B) {
+	group(b, "Do/")
+}
+
+func BenchmarkDoMultipart(b *testing.B) {
+	group(b, "DoMultipart/")
+}
+
+func BenchmarkTokenParsing(b *testing.B) {
+	group(b, "TokenParsing/")
+}
+
+func BenchmarkSendStream(b *testing.B) {
+	group(b, "SendStream/")
+}
+
+func TestBudgets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures every benchmark of the suite")
+	}
+
+	content, err := os.ReadFile(budgetsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budgets := map[string]fiberhandlerbench.Budget{}
+	if err := json.Unmarshal(content, &budgets); err != nil {
+		t.Fatal(err)
+	}
+
+	suite := fiberhandlerbench.Suite()
+	for _, bench := range suite {
+		if _, ok := budgets[bench.Name]; !ok {
+			t.Errorf("%s: no budget in %s", bench.Name, budgetsFile)
+		}
+	}
+	fiberhandlerbench.CheckBudgets(t, suite, budgets)
+}
@@ -0,0 +1,366 @@
+// Package fiberhandlertest builds requests for handlers built with fiberhandler, runs them through a
+// fiber app and asserts on the response and its envelope.
+//
+//	func TestCreateUser(t *testing.T) {
+//		fiberhandlertest.Post("/users").
+//			Token(Claims{Sub: "42"}).
+//			JSON(CreateUser{Name: "Ada"}).
+//			Run(t, handler.CreateUser).
+//			Status(http.StatusOK).
+//			Data(&user)
+//	}
+package fiberhandlertest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Secret signs the tokens of Request.Token, verifiers of the app under test can use it as their key.
+var Secret = []byte("fiberhandlertest")
+
+// SignToken returns an HS256 JWT with claims as payload signed with secret.
+func SignToken(claims any, secret []byte) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("fiberhandlertest: marshal claims: %w", err)
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + encoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+type formFile struct {
+	field, filename, contentType string
+	content                      []byte
+}
+
+// Request builds a request. Its methods record the first error, reported by Build, Do and Run.
+type Request struct {
+	method string
+	target string
+	route  string
+	header http.Header
+	query  url.Values
+	body   []byte
+	fields [][2]string
+	files  []formFile
+	err    error
+}
+
+// NewRequest starts a request with method to target, a path with an optional query.
+func NewRequest(method, target string) *Request {
+	return &Request{method: method, target: target, header: http.Header{}, query: url.Values{}}
+}
+
+// Get starts a GET request.
+func Get(target string) *Request { return NewRequest(http.MethodGet, target) }
+
+// Post starts a POST request.
+func Post(target string) *Request { return NewRequest(http.MethodPost, target) }
+
+// Put starts a PUT request.
+func Put(target string) *Request { return NewRequest(http.MethodPut, target) }
+
+// Patch starts a PATCH request.
+func Patch(target string) *Request { return NewRequest(http.MethodPatch, target) }
+
+// Delete starts a DELETE request.
+func Delete(target string) *Request { return NewRequest(http.MethodDelete, target) }
+
+// Route sets the route Run mounts the handler on, e.g. "/users/:id", default the path of the target.
+func (r *Request) Route(path string) *Request {
+	r.route = path
+	return r
+}
+
+// Header sets a request header.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query adds a query parameter.
+func (r *Request) Query(key string, values ...string) *Request {
+	for _, value := range values {
+		r.query.Add(key, value)
+	}
+	return r
+}
+
+// Bearer sets the Authorization header to the bearer token.
+func (r *Request) Bearer(token string) *Request {
+	return r.Header(fiber.HeaderAuthorization, "Bearer "+token)
+}
+
+// Token sends claims as a bearer JWT signed with Secret, or with secret when given.
+func (r *Request) Token(claims any, secret ...[]byte) *Request {
+	key := Secret
+	if len(secret) > 0 {
+		key = secret[0]
+	}
+	token, err := SignToken(claims, key)
+	if err != nil {
+		r.fail(err)
+		return r
+	}
+	return r.Bearer(token)
+}
+
+// JSON sends v as the JSON body.
+func (r *Request) JSON(v any) *Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.fail(fmt.Errorf("fiberhandlertest: marshal body: %w", err))
+		return r
+	}
+	r.body = body
+	return r.Header(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+}
+
+// Body sends body with contentType.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.body = body
+	return r.Header(fiber.HeaderContentType, contentType)
+}
+
+// Field adds a multipart form field, the request is sent as multipart/form-data.
+func (r *Request) Field(name, value string) *Request {
+	r.fields = append(r.fields, [2]string{name, value})
+	return r
+}
+
+// File adds a multipart file, with contentType when given, the request is sent as multipart/form-data.
+func (r *Request) File(field, filename string, content []byte, contentType ...string) *Request {
+	file := formFile{field: field, filename: filename, content: content}
+	if len(contentType) > 0 {
+		file.contentType = contentType[0]
+	}
+	r.files = append(r.files, file)
+	return r
+}
+
+func (r *Request) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Build returns the http.Request for fiber.App.Test.
+func (r *Request) Build() (*http.Request, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	target := r.target
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+
+	body := r.body
+	header := r.header.Clone()
+	if len(r.fields) > 0 || len(r.files) > 0 {
+		var err error
+		var contentType string
+		if body, contentType, err = r.multipart(); err != nil {
+			return nil, err
+		}
+		header.Set(fiber.HeaderContentType, contentType)
+	}
+
+	req := httptest.NewRequest(r.method, target, bytes.NewReader(body))
+	req.Header = header
+	return req, nil
+}
+
+func (r *Request) multipart() ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, field := range r.fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, file := range r.files {
+		header := make(map[string][]string)
+		header["Content-Disposition"] = []string{
+			fmt.Sprintf(`form-data; name=%q; filename=%q`, file.field, file.filename),
+		}
+		if file.contentType != "" {
+			header["Content-Type"] = []string{file.contentType}
+		} else {
+			header["Content-Type"] = []string{http.DetectContentType(file.content)}
+		}
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(file.content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// Do sends the request to app, failing t when it cannot be built or sent.
+func (r *Request) Do(t testing.TB, app *fiber.App) *Response {
+	t.Helper()
+
+	req, err := r.Build()
+	if err != nil {
+		t.Fatalf("fiberhandlertest: build request: %v", err)
+	}
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("fiberhandlertest: %s %s: %v", r.method, r.target, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("fiberhandlertest: read body: %v", err)
+	}
+	return &Response{Response: res, Body: body, t: t}
+}
+
+// Run mounts handler on a new fiber app for the method and route of the request and sends it.
+func (r *Request) Run(t testing.TB, handler fiber.Handler) *Response {
+	t.Helper()
+
+	route := r.route
+	if route == "" {
+		route, _, _ = strings.Cut(r.target, "?")
+	}
+	app := fiber.New()
+	app.Add(r.method, route, handler)
+	return r.Do(t, app)
+}
+
+// Response is the response to a request, its assertions report to the test with t.Errorf.
+type Response struct {
+	*http.Response
+	Body []byte
+	t    testing.TB
+}
+
+// Status asserts the status code.
+func (r *Response) Status(want int) *Response {
+	r.t.Helper()
+	if r.StatusCode != want {
+		r.t.Errorf("status = %d, want %d, body: %s", r.StatusCode, want, r.Body)
+	}
+	return r
+}
+
+// HasHeader asserts the value of a response header.
+func (r *Response) HasHeader(key, want string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); got != want {
+		r.t.Errorf("header %s = %q, want %q", key, got, want)
+	}
+	return r
+}
+
+// envelope holds the fields of the v1 and v2 envelopes, Problem Details and google.rpc.Status bodies.
+type envelope struct {
+	Code    json.RawMessage `json:"code"`
+	Message string          `json:"message"`
+	Detail  string          `json:"detail"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (r *Response) envelope() envelope {
+	r.t.Helper()
+
+	var body envelope
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		r.t.Fatalf("fiberhandlertest: decode envelope: %v, body: %s", err, r.Body)
+	}
+	return body
+}
+
+// ErrorCode returns the error code of the envelope, e.g. CLE029.
+func (r *Response) ErrorCode() string {
+	r.t.Helper()
+
+	body := r.envelope()
+	if body.Error != nil {
+		return body.Error.Code
+	}
+	var code string
+	_ = json.Unmarshal(body.Code, &code)
+	return code
+}
+
+// Message returns the message of the envelope.
+func (r *Response) Message() string {
+	r.t.Helper()
+
+	body := r.envelope()
+	switch {
+	case body.Error != nil:
+		return body.Error.Message
+	case body.Detail != "":
+		return body.Detail
+	}
+	return body.Message
+}
+
+// Code asserts the error code of the envelope.
+func (r *Response) Code(want string) *Response {
+	r.t.Helper()
+	if got := r.ErrorCode(); got != want {
+		r.t.Errorf("code = %q, want %q, body: %s", got, want, r.Body)
+	}
+	return r
+}
+
+// Data decodes the data of the envelope into v.
+func (r *Response) Data(v any) *Response {
+	r.t.Helper()
+
+	data := r.envelope().Data
+	if len(data) == 0 {
+		r.t.Fatalf("fiberhandlertest: no data in body: %s", r.Body)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		r.t.Fatalf("fiberhandlertest: decode data: %v, body: %s", err, r.Body)
+	}
+	return r
+}
+
+// JSON decodes the whole body into v, for raw responses.
+func (r *Response) JSON(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("fiberhandlertest: decode body: %v, body: %s", err, r.Body)
+	}
+	return r
+}
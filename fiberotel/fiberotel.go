@@ -0,0 +1,107 @@
+// Package fiberotel traces fiberhandler requests with OpenTelemetry.
+//
+//	handle := fiberhandler.New[Claims](response, validate).
+//		With(fiberotel.WithTracing(otel.GetTracerProvider()))
+package fiberotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prongbang/fiberhandler"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/prongbang/fiberhandler"
+
+// WithTracing traces requests with spans from tp, continuing the trace propagated in the W3C
+// traceparent and baggage headers, or with the given propagator.
+func WithTracing(tp trace.TracerProvider, propagator ...propagation.TextMapPropagator) fiberhandler.Option {
+	t := &tracer{
+		tracer:     tp.Tracer(instrumentationName),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+	if len(propagator) > 0 {
+		t.propagator = propagator[0]
+	}
+	return fiberhandler.WithTracer(t)
+}
+
+type tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// Extract implements fiberhandler.Tracer.
+func (t *tracer) Extract(ctx context.Context, header func(key string) string) context.Context {
+	return t.propagator.Extract(ctx, headerCarrier(header))
+}
+
+// Start implements fiberhandler.Tracer. The request span is a server span unless a server span was
+// already started by a middleware, stage spans are internal.
+func (t *tracer) Start(ctx context.Context, name string, attrs ...fiberhandler.SpanAttribute) (context.Context, fiberhandler.Span) {
+	kind := trace.SpanKindInternal
+	if parent := trace.SpanContextFromContext(ctx); !parent.IsValid() || parent.IsRemote() {
+		kind = trace.SpanKindServer
+	}
+
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attributes(attrs)...))
+	return ctx, span{s}
+}
+
+type span struct {
+	trace.Span
+}
+
+// SetAttributes implements fiberhandler.Span.
+func (s span) SetAttributes(attrs ...fiberhandler.SpanAttribute) {
+	s.Span.SetAttributes(attributes(attrs)...)
+}
+
+// RecordError implements fiberhandler.Span.
+func (s span) RecordError(err error) {
+	s.Span.RecordError(err)
+	s.Span.SetStatus(codes.Error, err.Error())
+}
+
+// End implements fiberhandler.Span.
+func (s span) End() {
+	s.Span.End()
+}
+
+func attributes(attrs []fiberhandler.SpanAttribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch value := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, value))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, value))
+		case int:
+			kvs = append(kvs, attribute.Int(attr.Key, value))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, value))
+		case float64:
+			kvs = append(kvs, attribute.Float64(attr.Key, value))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, fmt.Sprint(value)))
+		}
+	}
+	return kvs
+}
+
+// headerCarrier reads the propagated context from the request headers.
+type headerCarrier func(key string) string
+
+func (h headerCarrier) Get(key string) string {
+	return h(key)
+}
+
+func (h headerCarrier) Set(string, string) {}
+
+func (h headerCarrier) Keys() []string {
+	return nil
+}
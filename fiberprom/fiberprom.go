@@ -0,0 +1,101 @@
+// Package fiberprom exports fiberhandler request metrics to Prometheus.
+//
+//	handle := fiberhandler.New[Claims](response, validate).
+//		With(fiberprom.WithMetrics(prometheus.DefaultRegisterer))
+package fiberprom
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prongbang/fiberhandler"
+)
+
+const namespace = "fiberhandler"
+
+type metrics struct {
+	requests           *prometheus.CounterVec
+	duration           *prometheus.HistogramVec
+	validationFailures *prometheus.CounterVec
+	authFailures       *prometheus.CounterVec
+	legacyEnvelopes    *prometheus.CounterVec
+}
+
+// WithMetrics registers the request metrics with registerer and records every request handled:
+//
+//   - fiberhandler_requests_total{method, route, code}
+//   - fiberhandler_request_duration_seconds{method, route}
+//   - fiberhandler_validation_failures_total{method, route}
+//   - fiberhandler_auth_failures_total{method, route, reason}
+//   - fiberhandler_legacy_envelope_total{method, route}, with fiberhandler.WithEnvelope
+//
+// Handlers sharing a registerer share the collectors.
+func WithMetrics(registerer prometheus.Registerer, buckets ...float64) fiberhandler.Option {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	m := &metrics{
+		requests: register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests handled, by route, method and response code.",
+		}, []string{"method", "route", "code"})),
+		duration: register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the handled requests.",
+			Buckets:   buckets,
+		}, []string{"method", "route"})),
+		validationFailures: register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_failures_total",
+			Help:      "Requests rejected by validation.",
+		}, []string{"method", "route"})),
+		authFailures: register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_failures_total",
+			Help:      "Requests rejected as unauthorized or forbidden.",
+		}, []string{"method", "route", "reason"})),
+		legacyEnvelopes: register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "legacy_envelope_total",
+			Help:      "Responses sent in the legacy envelope.",
+		}, []string{"method", "route"})),
+	}
+	return fiberhandler.WithObserver(m)
+}
+
+// Observe implements fiberhandler.Observer.
+func (m *metrics) Observe(outcome fiberhandler.Outcome) {
+	m.requests.WithLabelValues(outcome.Method, outcome.Route, strconv.Itoa(outcome.Status)).Inc()
+	m.duration.WithLabelValues(outcome.Method, outcome.Route).Observe(outcome.Duration.Seconds())
+	if outcome.Envelope == fiberhandler.EnvelopeV1 {
+		m.legacyEnvelopes.WithLabelValues(outcome.Method, outcome.Route).Inc()
+	}
+
+	switch {
+	case outcome.Err == nil:
+	case fiberhandler.IsValidationError(outcome.Err):
+		m.validationFailures.WithLabelValues(outcome.Method, outcome.Route).Inc()
+	case fiberhandler.IsUnauthorized(outcome.Err):
+		m.authFailures.WithLabelValues(outcome.Method, outcome.Route, "unauthorized").Inc()
+	case fiberhandler.IsForbidden(outcome.Err):
+		m.authFailures.WithLabelValues(outcome.Method, outcome.Route, "forbidden").Inc()
+	}
+}
+
+// register registers collector, or returns the collector already registered under the same name.
+func register[C prometheus.Collector](registerer prometheus.Registerer, collector C) C {
+	if err := registerer.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return collector
+}
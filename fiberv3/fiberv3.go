@@ -0,0 +1,126 @@
+// Package fiberv3 mounts handlers built with fiberhandler on a Fiber v3 app, so projects migrating to
+// Fiber v3 keep using them without a fork. The routes run on an embedded Fiber v2 app sharing the
+// fasthttp request, Fiber v3 middleware keeps running around them.
+//
+//	app := fiber.New()
+//	api := fiberv3.New(app.Group("/api"), "/api")
+//	fiberv3.Get(api, "/users/:id", handle, users.Get)
+//	api.Post("/users", func(c *fiberv2.Ctx) error {
+//		req := CreateUserRequest{}
+//		return handle.Do(c, &req, true, func(ctx context.Context) (any, error) {
+//			return users.Create(ctx, &req)
+//		})
+//	})
+package fiberv3
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	fiberv2 "github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v3"
+	"github.com/prongbang/fiberhandler"
+	"github.com/valyala/fasthttp"
+)
+
+// Router registers Fiber v2 handlers on a Fiber v3 router.
+type Router struct {
+	router fiber.Router
+	prefix string
+	bridge *bridge
+}
+
+// bridge is the Fiber v2 app the routes of a Router and its groups run on.
+type bridge struct {
+	app     *fiberv2.App
+	once    sync.Once
+	handler fasthttp.RequestHandler
+}
+
+// New returns a Router registering on router, mounted at prefix, the path of router when it is a group.
+// config configures the embedded Fiber v2 app, e.g. its ErrorHandler or JSON codec.
+func New(router fiber.Router, prefix string, config ...fiberv2.Config) *Router {
+	return &Router{
+		router: router,
+		prefix: strings.TrimRight(prefix, "/"),
+		bridge: &bridge{app: fiberv2.New(config...)},
+	}
+}
+
+// Group returns a Router for routes under prefix.
+func (r *Router) Group(prefix string) *Router {
+	return &Router{
+		router: r.router.Group(prefix),
+		prefix: r.prefix + strings.TrimRight(prefix, "/"),
+		bridge: r.bridge,
+	}
+}
+
+// Add registers handler for method and path.
+func (r *Router) Add(method, path string, handler fiberv2.Handler) *Router {
+	r.bridge.app.Add(method, r.prefix+path, handler)
+	r.router.Add([]string{method}, path, fiber.Handler(r.bridge.serve))
+	return r
+}
+
+// Get registers handler for GET and HEAD requests to path.
+func (r *Router) Get(path string, handler fiberv2.Handler) *Router {
+	r.Add(http.MethodHead, path, handler)
+	return r.Add(http.MethodGet, path, handler)
+}
+
+// Post registers handler for POST requests to path.
+func (r *Router) Post(path string, handler fiberv2.Handler) *Router {
+	return r.Add(http.MethodPost, path, handler)
+}
+
+// Put registers handler for PUT requests to path.
+func (r *Router) Put(path string, handler fiberv2.Handler) *Router {
+	return r.Add(http.MethodPut, path, handler)
+}
+
+// Patch registers handler for PATCH requests to path.
+func (r *Router) Patch(path string, handler fiberv2.Handler) *Router {
+	return r.Add(http.MethodPatch, path, handler)
+}
+
+// Delete registers handler for DELETE requests to path.
+func (r *Router) Delete(path string, handler fiberv2.Handler) *Router {
+	return r.Add(http.MethodDelete, path, handler)
+}
+
+// serve runs the request through the routes of the Fiber v2 app, which writes the response.
+func (b *bridge) serve(c fiber.Ctx) error {
+	// The tree of the v2 app is built on the first request, once every route is registered
+	b.once.Do(func() {
+		b.handler = b.app.Handler()
+	})
+	b.handler(c.RequestCtx())
+	return nil
+}
+
+// Get registers fiberhandler.Handle(h, fn) for GET requests to path.
+func Get[R any](r *Router, path string, h fiberhandler.ApiHandler, fn fiberhandler.RequestFunc[R]) *Router {
+	return r.Get(path, fiberhandler.Handle(h, fn))
+}
+
+// Post registers fiberhandler.Handle(h, fn) for POST requests to path.
+func Post[R any](r *Router, path string, h fiberhandler.ApiHandler, fn fiberhandler.RequestFunc[R]) *Router {
+	return r.Post(path, fiberhandler.Handle(h, fn))
+}
+
+// Put registers fiberhandler.Handle(h, fn) for PUT requests to path.
+func Put[R any](r *Router, path string, h fiberhandler.ApiHandler, fn fiberhandler.RequestFunc[R]) *Router {
+	return r.Put(path, fiberhandler.Handle(h, fn))
+}
+
+// Patch registers fiberhandler.Handle(h, fn) for PATCH requests to path.
+func Patch[R any](r *Router, path string, h fiberhandler.ApiHandler, fn fiberhandler.RequestFunc[R]) *Router {
+	return r.Patch(path, fiberhandler.Handle(h, fn))
+}
+
+// Delete registers fiberhandler.Handle(h, fn) for DELETE requests to path.
+func Delete[R any](r *Router, path string, h fiberhandler.ApiHandler, fn fiberhandler.RequestFunc[R]) *Router {
+	return r.Delete(path, fiberhandler.Handle(h, fn))
+}
@@ -0,0 +1,319 @@
+package fiberhandler
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/prongbang/gopkg/streamx"
+)
+
+// TagACL is the struct tag listing the roles allowed to see a response field, e.g. `acl:"admin,auditor"`.
+const TagACL = "acl"
+
+// FieldACLConfig configures WithFieldACL.
+type FieldACLConfig struct {
+	// Mask replaces the value of the fields the caller may not see, they are removed when empty.
+	Mask string
+}
+
+type fieldACL struct {
+	roles  func(claims any) []string
+	config FieldACLConfig
+}
+
+// aclTypes caches whether a type holds fields tagged with TagACL.
+var aclTypes sync.Map
+
+// WithFieldACL strips the response fields tagged with TagACL from callers holding none of their roles,
+// returned by roles for the claims, so one handler serves the admin and the user views of a resource:
+//
+//	type User struct {
+//		ID    string `json:"id"`
+//		Email string `json:"email" acl:"admin,support"`
+//	}
+//
+// Values held in interfaces such as fiber.Map are filtered by their dynamic type and NDJSON items one
+// by one. Rendered templates, streams and values implementing json.Marshaler are sent unfiltered.
+func WithFieldACL[T any](roles func(claims *T) []string, config ...FieldACLConfig) Option {
+	acl := &fieldACL{
+		roles: func(claims any) []string {
+			c, _ := claims.(*T)
+			if c == nil {
+				return nil
+			}
+			return roles(c)
+		},
+	}
+	if len(config) > 0 {
+		acl.config = config[0]
+	}
+
+	return func(o *options) {
+		o.fieldACL = acl
+	}
+}
+
+func (a *fieldACL) apply(data any, claims any) (any, error) {
+	if data == nil {
+		return data, nil
+	}
+
+	switch result := data.(type) {
+	case *Result:
+		payload, err := a.apply(result.Data, claims)
+		if err != nil {
+			return nil, err
+		}
+		filtered := *result
+		filtered.Data = payload
+		return &filtered, nil
+	case *RawResult:
+		payload, err := a.apply(result.Data, claims)
+		return Raw(payload), err
+	case *NDJSONResult:
+		return a.applyNDJSON(result, claims), nil
+	case <-chan any:
+		return a.applyNDJSON(NDJSON(result), claims), nil
+	case *RenderResult, *streamx.Stream, *StreamResult:
+		return result, nil
+	}
+
+	v := reflect.ValueOf(data)
+	if !hasACL(v.Type()) {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	if err := a.encode(&buf, v, a.roles(claims)); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(buf.Bytes()), nil
+}
+
+// applyNDJSON filters every item of result, an item failing to encode ends the stream with its error.
+func (a *fieldACL) applyNDJSON(result *NDJSONResult, claims any) *NDJSONResult {
+	return &NDJSONResult{
+		Items: func(yield func(any) bool) {
+			for item := range result.Items {
+				if _, isErr := item.(error); !isErr {
+					filtered, err := a.apply(item, claims)
+					if err != nil {
+						yield(err)
+						return
+					}
+					item = filtered
+				}
+				if !yield(item) {
+					return
+				}
+			}
+		},
+	}
+}
+
+// hasACL reports whether values of t may hold fields tagged with TagACL.
+func hasACL(t reflect.Type) bool {
+	if cached, ok := aclTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	found := inspectACL(t, map[reflect.Type]bool{})
+	aclTypes.Store(t, found)
+	return found
+}
+
+// inspectACL looks for TagACL in t. Interfaces may hold any type, they are walked by encode. Types being
+// inspected are skipped, so recursive types terminate; only the result of the root type is cached as the
+// others may depend on it.
+func inspectACL(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if cached, ok := aclTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return inspectACL(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if _, ok := field.Tag.Lookup(TagACL); ok || inspectACL(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *fieldACL) encode(buf *bytes.Buffer, v reflect.Value, roles []string) error {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	if !hasACL(v.Type()) || v.Type().Implements(reflect.TypeFor[json.Marshaler]()) {
+		encoded, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return a.encode(buf, v.Elem(), roles)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := a.encode(buf, v.Index(i), roles); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(x, y reflect.Value) int {
+			return strings.Compare(fmt.Sprint(x.Interface()), fmt.Sprint(y.Interface()))
+		})
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			name, _ := json.Marshal(fmt.Sprint(key.Interface()))
+			buf.Write(name)
+			buf.WriteByte(':')
+			if err := a.encode(buf, v.MapIndex(key), roles); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		if err := a.encodeFields(buf, v, roles, &first); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+
+	encoded, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	buf.Write(encoded)
+	return nil
+}
+
+// encodeFields writes the fields of struct v visible to roles, inlining embedded structs like encoding/json.
+func (a *fieldACL) encodeFields(buf *bytes.Buffer, v reflect.Value, roles []string, first *bool) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			embedded := value
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := a.encodeFields(buf, embedded, roles, first); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		// Fields of unexported embedded structs cannot be read through reflection
+		if !field.IsExported() || !value.CanInterface() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if slices.Contains(strings.Split(opts, ","), "omitempty") && isEmptyJSONValue(value) {
+			continue
+		}
+
+		allowed := true
+		if acl, ok := field.Tag.Lookup(TagACL); ok {
+			allowed = slices.ContainsFunc(strings.Split(acl, ","), func(role string) bool {
+				return slices.Contains(roles, strings.TrimSpace(role))
+			})
+		}
+		if !allowed && a.config.Mask == "" {
+			continue
+		}
+
+		if !*first {
+			buf.WriteByte(',')
+		}
+		*first = false
+		encodedName, _ := json.Marshal(name)
+		buf.Write(encodedName)
+		buf.WriteByte(':')
+
+		if !allowed {
+			mask, _ := json.Marshal(a.config.Mask)
+			buf.Write(mask)
+			continue
+		}
+		if err := a.encode(buf, value, roles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmptyJSONValue reports whether omitempty drops v.
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
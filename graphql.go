@@ -0,0 +1,166 @@
+package fiberhandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEApplicationGraphQL is the content type of a POST body holding the GraphQL query itself.
+const MIMEApplicationGraphQL = "application/graphql"
+
+// GraphQLParams is a GraphQL request, read from the query of GET requests and from the JSON body (or an
+// application/graphql body holding the query) of POST requests.
+type GraphQLParams struct {
+	Query         string         `json:"query" validate:"required"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// BindRequest implements Bindable.
+func (p *GraphQLParams) BindRequest(c *fiber.Ctx) error {
+	if c.Method() == http.MethodGet {
+		p.Query = c.Query("query")
+		p.OperationName = c.Query("operationName")
+		for name, target := range map[string]*map[string]any{"variables": &p.Variables, "extensions": &p.Extensions} {
+			if value := c.Query(name); value != "" {
+				if err := json.Unmarshal([]byte(value), target); err != nil {
+					message := fmt.Sprintf("Invalid value for field '%s'", name)
+					if detail := describeParseError(err); detail != "" {
+						message += ": " + detail
+					}
+					return NewBadRequestError(message)
+				}
+			}
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), MIMEApplicationGraphQL) {
+		p.Query = string(c.Body())
+		return nil
+	}
+	if err := json.Unmarshal(c.Body(), p); err != nil {
+		return parseError(err)
+	}
+	return nil
+}
+
+// GraphQLExecutor executes a GraphQL operation and returns its JSON response, {data, errors}. Adapters
+// wrap gqlgen or graphql-go; resolvers read the caller with ClaimsFromContext.
+type GraphQLExecutor interface {
+	Execute(ctx context.Context, params GraphQLParams) any
+}
+
+// GraphQLExecutorFunc adapts a function to GraphQLExecutor.
+type GraphQLExecutorFunc func(ctx context.Context, params GraphQLParams) any
+
+// Execute implements GraphQLExecutor.
+func (f GraphQLExecutorFunc) Execute(ctx context.Context, params GraphQLParams) any {
+	return f(ctx, params)
+}
+
+// GraphQL returns a fiber handler serving executor on GET and POST, through the pipeline of Do: the
+// token is parsed and the claims put into the context before executor runs. Mutations are rejected on
+// GET with 405, like unsafe methods they require POST. The GraphQL response is sent without the success
+// envelope:
+//
+//	app.All("/graphql", fiberhandler.GraphQL(handle, fiberhandler.GraphQLExecutorFunc(
+//		func(ctx context.Context, params fiberhandler.GraphQLParams) any {
+//			return graphql.Do(graphql.Params{
+//				Schema:         schema,
+//				RequestString:  params.Query,
+//				OperationName:  params.OperationName,
+//				VariableValues: params.Variables,
+//				Context:        ctx,
+//			})
+//		},
+//	)))
+func GraphQL(h ApiHandler, executor GraphQLExecutor) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case http.MethodGet, http.MethodPost:
+		default:
+			c.Set(fiber.HeaderAllow, "GET, POST")
+			return h.SendError(c, NewMethodNotAllowedError())
+		}
+
+		params := &GraphQLParams{}
+		return h.Do(c, params, true, func(ctx context.Context) (any, error) {
+			// GET requests can be forged cross-site, they only run queries
+			if c.Method() == http.MethodGet && graphQLMutation(params.Query, params.OperationName) {
+				c.Set(fiber.HeaderAllow, "POST")
+				return nil, NewMethodNotAllowedError()
+			}
+			return Raw(executor.Execute(ctx, *params)), nil
+		})
+	}
+}
+
+// graphQLMutation reports whether the operation of query run for operationName, any operation when it
+// is empty, is a mutation. It only scans the top level definitions, the executor validates the document.
+func graphQLMutation(query, operationName string) bool {
+	var kind, name string
+	depth, parens := 0, 0
+	for i := 0; i < len(query); i++ {
+		switch ch := query[i]; {
+		case ch == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case ch == '"':
+			if strings.HasPrefix(query[i:], `"""`) {
+				// Block strings escape their delimiter as \"""
+				for i += 3; i < len(query) && !strings.HasPrefix(query[i:], `"""`); i++ {
+					if strings.HasPrefix(query[i:], `\"""`) {
+						i += 3
+					}
+				}
+				i += 2
+				continue
+			}
+			for i++; i < len(query) && query[i] != '"'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		case ch == '(':
+			parens++
+		case ch == ')':
+			parens--
+		case ch == '{':
+			depth++
+		case ch == '}':
+			depth--
+			if depth == 0 {
+				if kind == "mutation" && (operationName == "" || name == operationName) {
+					return true
+				}
+				kind, name = "", ""
+			}
+		case depth == 0 && parens == 0 && (ch == '_' || ch == '@' || isASCIILetter(ch)):
+			start := i
+			for i+1 < len(query) && (query[i+1] == '_' || isASCIILetter(query[i+1]) || query[i+1] >= '0' && query[i+1] <= '9') {
+				i++
+			}
+			word := query[start : i+1]
+			switch {
+			case ch == '@':
+			case kind == "":
+				kind = word
+			case name == "":
+				name = word
+			}
+		}
+	}
+	return kind == "mutation"
+}
+
+func isASCIILetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
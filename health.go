@@ -0,0 +1,165 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultHealthTimeout = 2 * time.Second
+
+// HealthStatus is the status of a health check or of the whole service.
+type HealthStatus string
+
+const (
+	HealthUp   HealthStatus = "up"
+	HealthDown HealthStatus = "down"
+)
+
+// Checker checks a dependency of the service, e.g. its database or a downstream API.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// Name implements Checker.
+func (c *checker) Name() string {
+	return c.name
+}
+
+// Check implements Checker.
+func (c *checker) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// NewChecker returns a Checker named name running check:
+//
+//	fiberhandler.NewChecker("postgres", pool.Ping)
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return &checker{name: name, check: check}
+}
+
+// CheckResult is the outcome of a Checker.
+type CheckResult struct {
+	Name     string       `json:"name"`
+	Status   HealthStatus `json:"status"`
+	Error    string       `json:"error,omitempty"`
+	Duration string       `json:"duration"`
+}
+
+// HealthReport is the body of the health endpoints.
+type HealthReport struct {
+	Status HealthStatus  `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// HealthChecks builds the liveness and readiness endpoints of a service.
+type HealthChecks struct {
+	checks  []Checker
+	timeout time.Duration
+}
+
+// Health returns the health endpoints running checks:
+//
+//	health := fiberhandler.Health(fiberhandler.NewChecker("postgres", pool.Ping))
+//	app.Get("/livez", health.Liveness(handle))
+//	app.Get("/readyz", health.Readiness(handle))
+func Health(checks ...Checker) *HealthChecks {
+	return &HealthChecks{checks: checks, timeout: defaultHealthTimeout}
+}
+
+// WithTimeout bounds every check, default 2s. A check still running at the timeout is reported down.
+func (hc *HealthChecks) WithTimeout(timeout time.Duration) *HealthChecks {
+	hc.timeout = timeout
+	return hc
+}
+
+// Liveness returns the handler reporting the process is up, without running the checks: a failing
+// dependency must not get a healthy process restarted.
+func (hc *HealthChecks) Liveness(h ApiHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return h.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			return HealthReport{Status: HealthUp}, nil
+		})
+	}
+}
+
+// Readiness returns the handler running the checks concurrently and reporting each of them in the
+// success envelope, with 503 Service Unavailable when one is down.
+func (hc *HealthChecks) Readiness(h ApiHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return h.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			report := hc.Check(ctx)
+			if report.Status == HealthDown {
+				return &Result{Status: http.StatusServiceUnavailable, Data: report}, nil
+			}
+			return report, nil
+		})
+	}
+}
+
+// Check runs the checks concurrently, each within the timeout, and reports their outcome in order.
+func (hc *HealthChecks) Check(ctx context.Context) HealthReport {
+	report := HealthReport{Status: HealthUp, Checks: make([]CheckResult, len(hc.checks))}
+
+	var wg sync.WaitGroup
+	for i, check := range hc.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = hc.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == HealthDown {
+			report.Status = HealthDown
+		}
+	}
+	return report
+}
+
+func (hc *HealthChecks) run(ctx context.Context, check Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// A check ignoring its context is abandoned
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Name: check.Name(), Status: HealthUp, Duration: time.Since(start).Round(time.Microsecond).String()}
+	if err != nil {
+		result.Status = HealthDown
+		result.Error = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = "timeout after " + hc.timeout.String()
+		}
+	}
+	return result
+}
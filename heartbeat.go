@@ -0,0 +1,49 @@
+package fiberhandler
+
+import "time"
+
+// WithHeartbeat writes a keep-alive after every interval without data on SSE and NDJSON streams, so
+// proxies do not close idle connections while the producer is slow. SSE streams get a ": ping" comment
+// and NDJSON streams an empty line, both ignored by conforming clients.
+func WithHeartbeat(interval time.Duration) Option {
+	return func(o *options) {
+		o.heartbeat = interval
+	}
+}
+
+// pump writes the items with send until items is closed, and calls beat after every interval without
+// items when interval > 0. Once a write fails stop is called and the remaining items are drained unsent,
+// so the producer is never blocked.
+func pump[E any](items <-chan E, interval time.Duration, send func(item E) error, beat func() error, stop func()) {
+	var ticker *time.Ticker
+	var idle <-chan time.Time
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+		idle = ticker.C
+	}
+
+	failed := false
+	for {
+		select {
+		case item, ok := <-items:
+			if !ok {
+				return
+			}
+			if failed {
+				continue
+			}
+			if err := send(item); err != nil {
+				failed, idle = true, nil
+				stop()
+			} else if ticker != nil {
+				ticker.Reset(interval)
+			}
+		case <-idle:
+			if err := beat(); err != nil {
+				failed, idle = true, nil
+				stop()
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package fiberhandler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/goerror"
+)
+
+// HTMLErrorConfig configures the errors sent to browsers, see WithHTMLErrors.
+type HTMLErrorConfig struct {
+	// Redirect returns the page a browser is redirected to with 303 See Other, e.g. the login page when
+	// the session expired. An empty location renders Template instead.
+	Redirect func(c *fiber.Ctx, err error) string
+
+	// Template is rendered with the status as a fiber.Map of Status, Code, Message and Path. Without a
+	// template, errors are sent as JSON.
+	Template string
+	Layouts  []string
+}
+
+// WithHTMLErrors sends the errors of browser requests, preferring text/html in Accept, as a redirect or
+// a rendered error page, while API clients keep receiving the JSON error response:
+//
+//	fiberhandler.WithHTMLErrors(fiberhandler.HTMLErrorConfig{
+//		Redirect: func(c *fiber.Ctx, err error) string {
+//			if fiberhandler.IsUnauthorized(err) {
+//				return "/login?next=" + url.QueryEscape(c.OriginalURL())
+//			}
+//			return ""
+//		},
+//		Template: "errors/default",
+//	})
+func WithHTMLErrors(config HTMLErrorConfig) Option {
+	return func(o *options) {
+		o.htmlErrors = &config
+	}
+}
+
+// wantsHTML reports whether the client prefers an HTML page over JSON, e.g. a browser navigation.
+func wantsHTML(c *fiber.Ctx) bool {
+	return c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML
+}
+
+// sendHTMLError redirects a browser or renders the error page. It reports false when the error is left
+// to the JSON response.
+func (h *apiHandler[T]) sendHTMLError(c *fiber.Ctx, err error) (bool, error) {
+	config := h.options.htmlErrors
+	if config.Redirect != nil {
+		if location := config.Redirect(c, err); location != "" {
+			return true, c.Redirect(location, http.StatusSeeOther)
+		}
+	}
+	if config.Template == "" {
+		return false, nil
+	}
+
+	// The default response gives the status and body of the error page
+	if sendErr := h.sendDefaultError(c, err); sendErr != nil {
+		return true, sendErr
+	}
+	status := c.Response().StatusCode()
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+		if IsValidationError(err) {
+			status = http.StatusBadRequest
+		}
+	}
+	var body goerror.Body
+	_ = json.Unmarshal(c.Response().Body(), &body)
+	c.Response().ResetBody()
+	if body.Message == "" {
+		body.Message = http.StatusText(status)
+	}
+
+	var bind any = fiber.Map{"Status": status, "Code": body.Code, "Message": body.Message, "Path": c.Path()}
+	if h.options.csp != nil {
+		var cspErr error
+		if bind, cspErr = h.options.csp.apply(c, bind); cspErr != nil {
+			slog.Error("Failed to generate CSP nonce", slog.String("error", cspErr.Error()))
+			return false, nil
+		}
+	}
+	c.Status(status)
+	return true, c.Render(config.Template, bind, config.Layouts...)
+}
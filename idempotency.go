@@ -0,0 +1,188 @@
+package fiberhandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	idempotencyPrefix         = "fiberhandler:idempotency:"
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = time.Minute
+)
+
+type IdempotencyConfig struct {
+	// Store keeps the recorded responses, default an in-memory store.
+	Store Store
+
+	// TTL is how long a response is replayed for retries, default 24 hours.
+	TTL time.Duration
+
+	// LockTTL bounds how long a request in progress holds its key, so a crashed instance does not
+	// block the key until TTL, default one minute.
+	LockTTL time.Duration
+
+	// Required answers unsafe requests without an Idempotency-Key with 400.
+	Required bool
+
+	// Scope returns the namespace of the keys of the request, it must include the caller so two
+	// callers can't collide or replay each other's responses. Default a hash of the claims of the
+	// caller, from the TokenParser or the Authenticator, followed by the route path. Anonymous callers
+	// share the scope of the route, set Scope to tell them apart, e.g. by API key.
+	Scope func(c *fiber.Ctx) string
+}
+
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type idempotency struct {
+	config IdempotencyConfig
+}
+
+type idempotencyClaim struct {
+	idempotency *idempotency
+	key         string
+	lock        []byte
+	fingerprint string
+}
+
+// WithIdempotency records the first response of unsafe requests carrying an Idempotency-Key header and
+// replays it to retries with the same key within the TTL. A key reused with a different payload is
+// answered with 422, a retry while the first request is still running with 409. Server errors are not
+// recorded so they can be retried.
+func WithIdempotency(config ...IdempotencyConfig) Option {
+	cfg := IdempotencyConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyTTL
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = defaultIdempotencyLockTTL
+	}
+	return func(o *options) {
+		o.idempotency = &idempotency{config: cfg}
+	}
+}
+
+// begin claims the key of the request. It reports whether the request is answered without running it,
+// by a replay or by the returned error, otherwise a returned claim must be finished once the response
+// is written. caller identifies the caller by its claims, scoping the keys unless the config has a Scope.
+func (i *idempotency) begin(c *fiber.Ctx, caller string) (*idempotencyClaim, bool, error) {
+	switch c.Method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil, false, nil
+	}
+
+	key := c.Get(HeaderIdempotencyKey)
+	if key == "" {
+		if i.config.Required {
+			return nil, true, NewBadRequestError("Missing " + HeaderIdempotencyKey + " header")
+		}
+		return nil, false, nil
+	}
+
+	var scope string
+	if i.config.Scope != nil {
+		scope = i.config.Scope(c)
+	} else {
+		scope = caller + ":" + c.Route().Path
+	}
+
+	sum := sha256.Sum256([]byte(c.Method() + " " + c.OriginalURL() + "\n" + string(c.Body())))
+	claim := &idempotencyClaim{
+		idempotency: i,
+		key:         idempotencyPrefix + scope + ":" + key,
+		fingerprint: hex.EncodeToString(sum[:]),
+	}
+	claim.lock, _ = json.Marshal(idempotencyRecord{Fingerprint: claim.fingerprint})
+
+	ctx := c.UserContext()
+	claimed, err := i.config.Store.CompareAndSwap(ctx, claim.key, nil, claim.lock, i.config.LockTTL)
+	if err != nil {
+		slog.Error("Failed to claim idempotency key", slog.String("error", err.Error()))
+		return nil, true, NewInternalError()
+	}
+	if claimed {
+		return claim, false, nil
+	}
+
+	value, err := i.config.Store.Get(ctx, claim.key)
+	if errors.Is(err, ErrKeyNotFound) {
+		// The first request failed and released the key in the meantime
+		return nil, true, NewConflictError("A request with this " + HeaderIdempotencyKey + " is in progress")
+	}
+	if err != nil {
+		slog.Error("Failed to read idempotency key", slog.String("error", err.Error()))
+		return nil, true, NewInternalError()
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(value, &record); err != nil {
+		slog.Error("Failed to read idempotency key", slog.String("error", err.Error()))
+		return nil, true, NewInternalError()
+	}
+	if record.Fingerprint != claim.fingerprint {
+		return nil, true, NewUnprocessableError(HeaderIdempotencyKey + " was used with a different request")
+	}
+	if !record.Done {
+		return nil, true, NewConflictError("A request with this " + HeaderIdempotencyKey + " is in progress")
+	}
+
+	c.Set(HeaderIdempotentReplayed, "true")
+	if record.ContentType != "" {
+		c.Set(fiber.HeaderContentType, record.ContentType)
+	}
+	if record.Location != "" {
+		c.Set(fiber.HeaderLocation, record.Location)
+	}
+	return nil, true, c.Status(record.Status).Send(record.Body)
+}
+
+// finish records the written response for replays, or releases the key after a server error.
+func (claim *idempotencyClaim) finish(c *fiber.Ctx) {
+	store := claim.idempotency.config.Store
+	ctx := c.UserContext()
+	response := c.Response()
+
+	if response.StatusCode() >= http.StatusInternalServerError || response.IsBodyStream() {
+		if err := store.Delete(ctx, claim.key); err != nil {
+			slog.Error("Failed to release idempotency key", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	value, err := json.Marshal(idempotencyRecord{
+		Fingerprint: claim.fingerprint,
+		Done:        true,
+		Status:      response.StatusCode(),
+		ContentType: string(response.Header.ContentType()),
+		Location:    string(response.Header.Peek(fiber.HeaderLocation)),
+		Body:        response.Body(),
+	})
+	if err == nil {
+		_, err = store.CompareAndSwap(ctx, claim.key, claim.lock, value, claim.idempotency.config.TTL)
+	}
+	if err != nil {
+		slog.Error("Failed to record idempotent response", slog.String("error", err.Error()))
+	}
+}
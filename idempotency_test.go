@@ -0,0 +1,160 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+type createOrder struct {
+	Item string `json:"item"`
+}
+
+// headerAuthenticator authenticates the caller named by the X-User header, like a session or a client
+// certificate would, without a bearer token.
+var headerAuthenticator = fiberhandler.AuthenticatorFunc[testClaims](func(c *fiber.Ctx) (*testClaims, error) {
+	if user := c.Get("X-User"); user != "" {
+		return &testClaims{Sub: user}, nil
+	}
+	return nil, nil
+})
+
+type idempotentRequest struct {
+	user string
+	key  string
+	item string
+}
+
+func TestIdempotency(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   fiberhandler.IdempotencyConfig
+		fail     bool
+		first    idempotentRequest
+		second   idempotentRequest
+		want     int
+		replayed bool
+		calls    int
+	}{
+		{
+			name:     "replay",
+			first:    idempotentRequest{user: "ada", key: "k1", item: "book"},
+			second:   idempotentRequest{user: "ada", key: "k1", item: "book"},
+			want:     http.StatusOK,
+			replayed: true,
+			calls:    1,
+		},
+		{
+			name:   "different payload",
+			first:  idempotentRequest{user: "ada", key: "k1", item: "book"},
+			second: idempotentRequest{user: "ada", key: "k1", item: "pen"},
+			want:   http.StatusUnprocessableEntity,
+			calls:  1,
+		},
+		{
+			name:   "other key",
+			first:  idempotentRequest{user: "ada", key: "k1", item: "book"},
+			second: idempotentRequest{user: "ada", key: "k2", item: "book"},
+			want:   http.StatusOK,
+			calls:  2,
+		},
+		{
+			name:   "other caller",
+			first:  idempotentRequest{user: "ada", key: "k1", item: "book"},
+			second: idempotentRequest{user: "bob", key: "k1", item: "book"},
+			want:   http.StatusOK,
+			calls:  2,
+		},
+		{
+			name:   "server error not recorded",
+			fail:   true,
+			first:  idempotentRequest{user: "ada", key: "k1", item: "book"},
+			second: idempotentRequest{user: "ada", key: "k1", item: "book"},
+			want:   http.StatusInternalServerError,
+			calls:  2,
+		},
+		{
+			name:   "without key",
+			first:  idempotentRequest{user: "ada", item: "book"},
+			second: idempotentRequest{user: "ada", item: "book"},
+			want:   http.StatusOK,
+			calls:  2,
+		},
+		{
+			name:   "required key",
+			config: fiberhandler.IdempotencyConfig{Required: true},
+			first:  idempotentRequest{user: "ada", key: "k1", item: "book"},
+			second: idempotentRequest{user: "ada", item: "book"},
+			want:   http.StatusBadRequest,
+			calls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := fiberhandler.NewWithComponents(
+				fiberhandler.Components[testClaims]{Authenticator: headerAuthenticator},
+				fiberhandler.WithIdempotency(tt.config),
+			)
+			calls := 0
+			handler := func(c *fiber.Ctx) error {
+				request := createOrder{}
+				return handle.Do(c, &request, true, func(ctx context.Context) (any, error) {
+					calls++
+					if tt.fail {
+						return nil, fiberhandler.NewInternalError()
+					}
+					return request, nil
+				})
+			}
+			send := func(r idempotentRequest) *fiberhandlertest.Response {
+				request := fiberhandlertest.Post("/orders").Header("X-User", r.user).JSON(createOrder{Item: r.item})
+				if r.key != "" {
+					request.Header(fiberhandler.HeaderIdempotencyKey, r.key)
+				}
+				return request.Run(t, handler)
+			}
+
+			send(tt.first)
+			response := send(tt.second).Status(tt.want)
+			if replayed := response.Header.Get(fiberhandler.HeaderIdempotentReplayed) == "true"; replayed != tt.replayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.replayed)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyStoreError(t *testing.T) {
+	handle := fiberhandler.NewWithComponents(
+		fiberhandler.Components[testClaims]{},
+		fiberhandler.WithIdempotency(fiberhandler.IdempotencyConfig{Store: failingStore{}}),
+	)
+	fiberhandlertest.Post("/orders").
+		Header(fiberhandler.HeaderIdempotencyKey, "k1").
+		JSON(createOrder{Item: "book"}).
+		Run(t, func(c *fiber.Ctx) error {
+			return handle.Do(c, &createOrder{}, true, func(ctx context.Context) (any, error) {
+				t.Error("doFunc ran without a claimed key")
+				return nil, nil
+			})
+		}).
+		Status(http.StatusInternalServerError)
+}
+
+var errStoreDown = errors.New("store down")
+
+// failingStore fails every operation, like a store that is down.
+type failingStore struct{ fiberhandler.Store }
+
+func (failingStore) CompareAndSwap(context.Context, string, []byte, []byte, time.Duration) (bool, error) {
+	return false, errStoreDown
+}
@@ -0,0 +1,69 @@
+package fiberhandler
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"mime/multipart"
+	"slices"
+)
+
+// ImageRule limits the images uploaded in a file field, zero values are unlimited.
+type ImageRule struct {
+	MaxWidth  int
+	MaxHeight int
+
+	// MaxPixels caps width × height, e.g. 12_000_000 for 12 megapixels.
+	MaxPixels int
+
+	// Formats lists the accepted formats as named by the image package, e.g. "jpeg", "png" or "gif".
+	// Other formats are accepted once their decoder is registered with a blank import.
+	Formats []string
+}
+
+// ImageValidator is an UploadHook checking the images of the fields in rules by decoding their header,
+// so the declared Content-Type cannot disguise another file and oversized images are rejected before
+// they are decoded. Files of other fields are not checked:
+//
+//	fiberhandler.WithUploadHooks(fiberhandler.ImageValidator(map[string]fiberhandler.ImageRule{
+//		"avatar": {MaxWidth: 1024, MaxHeight: 1024, Formats: []string{"jpeg", "png"}},
+//	}))
+func ImageValidator(rules map[string]ImageRule) UploadHook {
+	return UploadHookFunc(func(_ context.Context, fieldName string, file *multipart.FileHeader) error {
+		rule, ok := rules[fieldName]
+		if !ok {
+			return nil
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		config, format, err := image.DecodeConfig(src)
+		if err != nil {
+			return NewUploadRejectedError("not a supported image")
+		}
+		return rule.check(config, format)
+	})
+}
+
+func (r ImageRule) check(config image.Config, format string) error {
+	if len(r.Formats) > 0 && !slices.Contains(r.Formats, format) {
+		return NewUploadRejectedError(fmt.Sprintf("format %s is not allowed", format))
+	}
+	if r.MaxWidth > 0 && config.Width > r.MaxWidth {
+		return NewUploadRejectedError(fmt.Sprintf("width %d exceeds %d pixels", config.Width, r.MaxWidth))
+	}
+	if r.MaxHeight > 0 && config.Height > r.MaxHeight {
+		return NewUploadRejectedError(fmt.Sprintf("height %d exceeds %d pixels", config.Height, r.MaxHeight))
+	}
+	if r.MaxPixels > 0 && config.Width*config.Height > r.MaxPixels {
+		return NewUploadRejectedError(fmt.Sprintf("%d pixels exceed %d", config.Width*config.Height, r.MaxPixels))
+	}
+	return nil
+}
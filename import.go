@@ -0,0 +1,321 @@
+package fiberhandler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/goerror"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	defaultImportField     = "file"
+	defaultImportMaxErrors = 100
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// ImportConfig configures DoImport.
+type ImportConfig struct {
+	// Field is the multipart field of the file, default "file".
+	Field string
+
+	// Sheet is the sheet read from XLSX files, default the first one. Comma separates the fields of CSV
+	// files, default ','.
+	Sheet string
+	Comma rune
+
+	// MaxRows stops the import after that many rows, the report is then Truncated. Zero reads them all.
+	MaxRows int
+
+	// MaxErrors is the number of row errors listed in the report, default 100. Further rejected rows are
+	// only counted.
+	MaxErrors int
+}
+
+// ImportFunc imports a row that passed validation. An error with a client status (4xx), e.g. a
+// ConflictError for a duplicate, rejects the row, other errors stop the import.
+type ImportFunc[R any] func(ctx context.Context, row R) error
+
+// ImportRowError is the reason a row was rejected, Row is its row in the file, the header being row 1.
+type ImportRowError struct {
+	Row        int              `json:"row"`
+	Message    string           `json:"message"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// ImportReport is the result of DoImport.
+type ImportReport struct {
+	Accepted  int              `json:"accepted"`
+	Rejected  int              `json:"rejected"`
+	Errors    []ImportRowError `json:"errors,omitempty"`
+	Truncated bool             `json:"truncated,omitempty"`
+}
+
+// importRequest binds nothing, DoImport reads the file itself.
+type importRequest struct{}
+
+// BindRequest implements Bindable.
+func (importRequest) BindRequest(*fiber.Ctx) error {
+	return nil
+}
+
+type rowPreparer interface {
+	prepareRow(row any) error
+}
+
+// prepareRow sanitizes, defaults and validates an imported row like a request.
+func (h *apiHandler[T]) prepareRow(row any) error {
+	if err := h.sanitize(row); err != nil {
+		return err
+	}
+	if err := applyDefaults(row); err != nil {
+		return err
+	}
+	return h.Validate.Struct(row)
+}
+
+// DoImport runs the pipeline of Do on the upload of a CSV or XLSX file, streams its rows into R, a
+// struct whose fields are matched to the header row by their csv tag, and calls importFunc with every
+// row that passes validation. It answers with an ImportReport of the accepted rows and the errors of
+// the rejected ones:
+//
+//	app.Post("/users/import", func(c *fiber.Ctx) error {
+//		return fiberhandler.DoImport(handle, c, fiberhandler.ImportConfig{}, func(ctx context.Context, row UserRow) error {
+//			return users.Create(ctx, row)
+//		})
+//	})
+func DoImport[R any](h ApiHandler, c *fiber.Ctx, config ImportConfig, importFunc ImportFunc[R]) error {
+	preparer, ok := h.(rowPreparer)
+	if !ok {
+		return fmt.Errorf("DoImport: the handler was not created with New")
+	}
+	if config.Field == "" {
+		config.Field = defaultImportField
+	}
+	if config.MaxErrors <= 0 {
+		config.MaxErrors = defaultImportMaxErrors
+	}
+
+	return h.Do(c, &importRequest{}, false, func(ctx context.Context) (any, error) {
+		header, err := c.FormFile(config.Field)
+		if err != nil {
+			return nil, NewBadRequestError(fmt.Sprintf("Missing file '%s'", config.Field))
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		rows, err := importRows(file, header, config)
+		if err != nil {
+			return nil, err
+		}
+		return importFile(ctx, preparer, rows, config, importFunc)
+	})
+}
+
+// importRow is a row of an uploaded file, raw tells that its cells are unformatted XLSX values.
+type importRow struct {
+	line  int
+	cells []string
+	raw   bool
+	err   error
+}
+
+func importRows(file multipart.File, header *multipart.FileHeader, config ImportConfig) (iter.Seq[importRow], error) {
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".csv":
+		return csvRows(file, config), nil
+	case ".xlsx":
+		return xlsxRows(file, config)
+	}
+	return nil, &ResponseError{
+		Body:   goerror.Body{Message: "Unsupported file type, upload a .csv or .xlsx file"},
+		Status: http.StatusUnsupportedMediaType,
+	}
+}
+
+func csvRows(file io.Reader, config ImportConfig) iter.Seq[importRow] {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	if config.Comma != 0 {
+		reader.Comma = config.Comma
+	}
+	return func(yield func(importRow) bool) {
+		for line := 1; ; line++ {
+			cells, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if line == 1 && len(cells) > 0 {
+				cells[0] = strings.TrimPrefix(cells[0], utf8BOM)
+			}
+			if !yield(importRow{line: line, cells: cells, err: err}) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func xlsxRows(file io.Reader, config ImportConfig) (iter.Seq[importRow], error) {
+	workbook, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, NewUnprocessableError("Invalid XLSX file")
+	}
+	sheet := config.Sheet
+	if sheet == "" {
+		sheet = workbook.GetSheetName(0)
+	}
+	rows, err := workbook.Rows(sheet)
+	if err != nil {
+		_ = workbook.Close()
+		return nil, NewUnprocessableError(fmt.Sprintf("Sheet '%s' not found", sheet))
+	}
+
+	return func(yield func(importRow) bool) {
+		defer workbook.Close()
+		defer rows.Close()
+		for line := 1; rows.Next(); line++ {
+			cells, err := rows.Columns(excelize.Options{RawCellValue: true})
+			if !yield(importRow{line: line, cells: cells, raw: true, err: err}) || err != nil {
+				return
+			}
+		}
+		if err := rows.Error(); err != nil {
+			yield(importRow{err: err})
+		}
+	}, nil
+}
+
+func importFile[R any](ctx context.Context, preparer rowPreparer, rows iter.Seq[importRow], config ImportConfig, importFunc ImportFunc[R]) (*ImportReport, error) {
+	report := &ImportReport{}
+	reject := func(rowErr ImportRowError) {
+		report.Rejected++
+		if len(report.Errors) < config.MaxErrors {
+			report.Errors = append(report.Errors, rowErr)
+		}
+	}
+
+	var columns []*csvColumn
+	for row := range rows {
+		if row.err != nil {
+			return nil, NewUnprocessableError(fmt.Sprintf("Unreadable file at row %d", row.line))
+		}
+		if columns == nil {
+			columns = importColumns(reflect.TypeFor[R](), row.cells)
+			continue
+		}
+		if isBlankRow(row.cells) {
+			continue
+		}
+		if config.MaxRows > 0 && report.Accepted+report.Rejected >= config.MaxRows {
+			report.Truncated = true
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var value R
+		if violations := decodeRow(&value, columns, row); len(violations) > 0 {
+			reject(ImportRowError{Row: row.line, Message: "Invalid values", Violations: violations})
+			continue
+		}
+		if err := preparer.prepareRow(&value); err != nil {
+			reject(ImportRowError{Row: row.line, Message: "Invalid data provided", Violations: fieldViolations(err)})
+			continue
+		}
+		if err := importFunc(ctx, value); err != nil {
+			var statusErr StatusCoder
+			if !errors.As(err, &statusErr) || statusErr.StatusCode() >= http.StatusInternalServerError {
+				return nil, err
+			}
+			reject(ImportRowError{Row: row.line, Message: err.Error()})
+			continue
+		}
+		report.Accepted++
+	}
+	return report, nil
+}
+
+// importColumns matches the header cells to the columns of the row type, case-insensitively. Unknown
+// headers are nil.
+func importColumns(t reflect.Type, header []string) []*csvColumn {
+	known := csvColumnsOf(t)
+	columns := make([]*csvColumn, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		for j := range known {
+			if strings.EqualFold(known[j].name, name) {
+				columns[i] = &known[j]
+				break
+			}
+		}
+	}
+	return columns
+}
+
+func decodeRow(rowPtr any, columns []*csvColumn, row importRow) []FieldViolation {
+	v := reflect.ValueOf(rowPtr).Elem()
+	var violations []FieldViolation
+	for i, cell := range row.cells {
+		if i >= len(columns) || columns[i] == nil || strings.TrimSpace(cell) == "" {
+			continue
+		}
+		field, err := v.FieldByIndexErr(columns[i].index)
+		if err != nil {
+			continue
+		}
+
+		value, err := importValue(field.Type(), cell, row.raw)
+		if err != nil {
+			violations = append(violations, FieldViolation{
+				Field:       columns[i].name,
+				Description: fmt.Sprintf("invalid value %q", cell),
+			})
+			continue
+		}
+		field.Set(value)
+	}
+	return violations
+}
+
+// importValue parses a cell, the serial dates of XLSX files included.
+func importValue(t reflect.Type, cell string, raw bool) (reflect.Value, error) {
+	if raw && (t == timeType || (t.Kind() == reflect.Pointer && t.Elem() == timeType)) {
+		if serial, err := strconv.ParseFloat(cell, 64); err == nil {
+			date, err := excelize.ExcelDateToTime(serial, false)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if t.Kind() == reflect.Pointer {
+				return reflect.ValueOf(&date), nil
+			}
+			return reflect.ValueOf(date), nil
+		}
+	}
+	return parseValue(t, strings.TrimSpace(cell))
+}
+
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package fiberhandler
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Interceptor hooks into the phases of Do, DoMultipart and DoSSE for cross-cutting concerns such as
+// enrichment, auditing or response mutation. Every hook is optional, an error it returns is sent as the
+// response and stops the request.
+type Interceptor struct {
+	// BeforeParse runs before the request is bound.
+	BeforeParse func(c *fiber.Ctx) error
+
+	// AfterValidate runs once the request is bound, validated, authenticated and authorized, before
+	// doFunc. It may enrich the request or the user context.
+	AfterValidate func(c *fiber.Ctx, requestPtr any) error
+
+	// BeforeResponse runs with the result of doFunc before it is sent and returns the result to send.
+	BeforeResponse func(c *fiber.Ctx, data any) (any, error)
+}
+
+// WithInterceptors appends interceptors to the handler, their hooks run in the order they were added.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(slices.Clip(o.interceptors), interceptors...)
+	}
+}
+
+func (h *apiHandler[T]) beforeParse(c *fiber.Ctx) error {
+	for _, interceptor := range h.options.interceptors {
+		if interceptor.BeforeParse == nil {
+			continue
+		}
+		if err := interceptor.BeforeParse(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *apiHandler[T]) afterValidate(c *fiber.Ctx, requestPtr any) error {
+	for _, interceptor := range h.options.interceptors {
+		if interceptor.AfterValidate == nil {
+			continue
+		}
+		if err := interceptor.AfterValidate(c, requestPtr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *apiHandler[T]) beforeResponse(c *fiber.Ctx, data any) (any, error) {
+	for _, interceptor := range h.options.interceptors {
+		if interceptor.BeforeResponse == nil {
+			continue
+		}
+		var err error
+		if data, err = interceptor.BeforeResponse(c, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,196 @@
+package fiberhandler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// provider resolves a doFunc parameter, release is called with the doFunc error once it returned.
+type provider func(ctx context.Context) (value any, release func(err error), err error)
+
+// WithProvider registers provide as the source of doFunc parameters of type D for Invoke, e.g. a
+// repository or a tenant scoped client.
+func WithProvider[D any](provide func(ctx context.Context) (D, error)) Option {
+	return WithScopedProvider(func(ctx context.Context) (D, func(err error), error) {
+		value, err := provide(ctx)
+		return value, nil, err
+	})
+}
+
+// WithScopedProvider registers provide as the source of doFunc parameters of type D for Invoke, release
+// is called with the doFunc error once it returned, or a non-nil error when it panicked, e.g. to commit
+// or roll back a transaction:
+//
+//	fiberhandler.WithScopedProvider(func(ctx context.Context) (pgx.Tx, func(error), error) {
+//		tx, err := pool.Begin(ctx)
+//		return tx, func(err error) {
+//			if err != nil {
+//				_ = tx.Rollback(ctx)
+//				return
+//			}
+//			_ = tx.Commit(ctx)
+//		}, err
+//	})
+func WithScopedProvider[D any](provide func(ctx context.Context) (D, func(err error), error)) Option {
+	return func(o *options) {
+		providers := maps.Clone(o.providers)
+		if providers == nil {
+			providers = map[reflect.Type]provider{}
+		}
+		providers[reflect.TypeFor[D]()] = func(ctx context.Context) (any, func(err error), error) {
+			return provide(ctx)
+		}
+		o.providers = providers
+	}
+}
+
+// Invoke runs the pipeline of Do for a doFunc declaring its dependencies as parameters, resolved by type:
+// context.Context, *fiber.Ctx, the claims pointer of the handler, the types registered with WithProvider
+// and WithScopedProvider, and one pointer to a struct which is bound and validated as the request.
+// fn returns a result and an error:
+//
+//	return fiberhandler.Invoke(handle, c, true, func(ctx context.Context, req *PostRequest, claims *Claims, tx pgx.Tx) (any, error) {
+//		...
+//	})
+func Invoke(h ApiHandler, c *fiber.Ctx, validateRequest bool, fn any) error {
+	handler, ok := h.(invoker)
+	if !ok {
+		return fmt.Errorf("Invoke: the handler was not created with New")
+	}
+	return handler.invoke(c, validateRequest, fn)
+}
+
+type invoker interface {
+	invoke(c *fiber.Ctx, validateRequest bool, fn any) error
+}
+
+type paramKind int
+
+const (
+	paramContext paramKind = iota
+	paramFiberCtx
+	paramClaims
+	paramProvided
+	paramRequest
+)
+
+type invokePlan struct {
+	fn      reflect.Value
+	kinds   []paramKind
+	types   []reflect.Type
+	request reflect.Type
+}
+
+var (
+	contextType  = reflect.TypeFor[context.Context]()
+	fiberCtxType = reflect.TypeFor[*fiber.Ctx]()
+	errorType    = reflect.TypeFor[error]()
+)
+
+func (h *apiHandler[T]) invoke(c *fiber.Ctx, validateRequest bool, fn any) error {
+	plan, err := h.plan(fn)
+	if err != nil {
+		return err
+	}
+
+	// Do expects a request, functions without one bind an empty struct
+	var requestPtr any = &struct{}{}
+	if plan.request != nil {
+		requestPtr = reflect.New(plan.request.Elem()).Interface()
+	}
+
+	return h.Do(c, requestPtr, validateRequest && plan.request != nil, func(ctx context.Context) (data any, err error) {
+		var releases []func(err error)
+		defer func() {
+			// A panic must not commit, the releases see an error before it reaches the recovery of Do
+			value := recover()
+			if value != nil {
+				err = fmt.Errorf("panic: %v", value)
+			}
+			for _, release := range releases {
+				release(err)
+			}
+			if value != nil {
+				panic(value)
+			}
+		}()
+
+		args := make([]reflect.Value, len(plan.kinds))
+		for i, kind := range plan.kinds {
+			switch kind {
+			case paramContext:
+				args[i] = reflect.ValueOf(&ctx).Elem()
+			case paramFiberCtx:
+				args[i] = reflect.ValueOf(c)
+			case paramClaims:
+				claims, _ := ClaimsFromContext[T](ctx)
+				args[i] = reflect.ValueOf(claims)
+			case paramRequest:
+				args[i] = reflect.ValueOf(requestPtr)
+			case paramProvided:
+				value, release, provideErr := h.options.providers[plan.types[i]](ctx)
+				if provideErr != nil {
+					return nil, provideErr
+				}
+				if release != nil {
+					releases = append(releases, release)
+				}
+				args[i] = reflect.New(plan.types[i]).Elem()
+				if value != nil {
+					args[i].Set(reflect.ValueOf(value))
+				}
+			}
+		}
+
+		results := plan.fn.Call(args)
+		if errValue := results[1]; !errValue.IsNil() {
+			return nil, errValue.Interface().(error)
+		}
+		return results[0].Interface(), nil
+	})
+}
+
+// plan resolves the parameters of fn by type.
+func (h *apiHandler[T]) plan(fn any) (*invokePlan, error) {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("Invoke: %T is not a function", fn)
+	}
+	if fnType.NumOut() != 2 || fnType.Out(1) != errorType {
+		return nil, fmt.Errorf("Invoke: %s must return a result and an error", fnType)
+	}
+
+	claimsType := reflect.TypeFor[*T]()
+	plan := &invokePlan{
+		fn:    reflect.ValueOf(fn),
+		kinds: make([]paramKind, fnType.NumIn()),
+		types: make([]reflect.Type, fnType.NumIn()),
+	}
+	for i := range fnType.NumIn() {
+		param := fnType.In(i)
+		plan.types[i] = param
+
+		_, provided := h.options.providers[param]
+		switch {
+		case param == contextType:
+			plan.kinds[i] = paramContext
+		case param == fiberCtxType:
+			plan.kinds[i] = paramFiberCtx
+		case param == claimsType:
+			plan.kinds[i] = paramClaims
+		case provided:
+			plan.kinds[i] = paramProvided
+		case plan.request == nil && param.Kind() == reflect.Pointer && param.Elem().Kind() == reflect.Struct:
+			plan.kinds[i] = paramRequest
+			plan.request = param
+		default:
+			return nil, fmt.Errorf("Invoke: no provider for parameter %d (%s) of %s", i, param, fnType)
+		}
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,438 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/goerror"
+)
+
+// MIMEApplicationJSONPatchJSON is the media type of RFC 6902 JSON Patch documents.
+const MIMEApplicationJSONPatchJSON = "application/json-patch+json"
+
+// Operations of a JSON Patch.
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// jsonPatchErrorCodes maps the codes of a JSONPatchError back to its reason.
+var jsonPatchErrorCodes = map[string]error{
+	"CLE045": ErrInvalidPatch,
+	"CLE046": ErrPatchPathNotFound,
+	"CLE047": ErrPatchTestFailed,
+}
+
+// JSONPatchError is returned for a JSON Patch that cannot be parsed or applied, with the operation at
+// fault. Invalid patches are sent with 400, paths missing from the document with 422 and failed tests
+// with 409.
+type JSONPatchError struct {
+	goerror.Body
+	Index  int    `json:"-"`
+	Op     string `json:"-"`
+	Path   string `json:"-"`
+	Reason error  `json:"-"`
+}
+
+// Error implements error.
+func (c *JSONPatchError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is the reason of the error or the class of its status.
+func (c *JSONPatchError) Is(target error) bool {
+	switch c.Reason {
+	case ErrPatchPathNotFound:
+		return target == c.Reason || target == ErrUnprocessable
+	case ErrPatchTestFailed:
+		return target == c.Reason || target == ErrConflict
+	}
+	return target == ErrInvalidPatch || target == ErrBadRequest
+}
+
+// StatusCode implements StatusCoder.
+func (c *JSONPatchError) StatusCode() int {
+	switch c.Reason {
+	case ErrPatchPathNotFound:
+		return http.StatusUnprocessableEntity
+	case ErrPatchTestFailed:
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// NewJSONPatchError returns the error of the operation at index of a patch failing for reason, one of
+// ErrInvalidPatch, ErrPatchPathNotFound or ErrPatchTestFailed, with detail added to the message.
+func NewJSONPatchError(reason error, index int, op PatchOperation, detail ...string) error {
+	var code, message string
+	switch reason {
+	case ErrPatchPathNotFound:
+		code, message = "CLE046", "Path not found"
+	case ErrPatchTestFailed:
+		code, message = "CLE047", "Test failed"
+	default:
+		reason = ErrInvalidPatch
+		code, message = "CLE045", "Invalid operation"
+	}
+	if len(detail) > 0 {
+		message += ": " + detail[0]
+	}
+	return &JSONPatchError{
+		Body: goerror.Body{
+			Code:    code,
+			Message: fmt.Sprintf("JSON patch operation %d (%s %s): %s", index, op.Op, op.Path, message),
+		},
+		Index:  index,
+		Op:     op.Op,
+		Path:   op.Path,
+		Reason: reason,
+	}
+}
+
+// PatchOperation is an operation of a JSON Patch, Path and From are JSON Pointers (RFC 6901).
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is a request binding an RFC 6902 JSON Patch body, application/json-patch+json or
+// application/json. The operations are checked when bound, doFunc applies them to the resource it
+// loads:
+//
+//	patch := fiberhandler.JSONPatch{}
+//	return handle.Do(c, &patch, true, func(ctx context.Context) (any, error) {
+//		user, err := users.Get(ctx, c.Params("id"))
+//		if err != nil {
+//			return nil, err
+//		}
+//		if err := patch.ApplyTo(user); err != nil {
+//			return nil, err
+//		}
+//		return users.Save(ctx, user)
+//	})
+type JSONPatch struct {
+	Operations []PatchOperation
+}
+
+// BindRequest implements Bindable.
+func (p *JSONPatch) BindRequest(c *fiber.Ctx) error {
+	if !isJSON(c) {
+		return &ResponseError{
+			Body:   goerror.Body{Message: "Unsupported media type, use " + MIMEApplicationJSONPatchJSON},
+			Status: http.StatusUnsupportedMediaType,
+		}
+	}
+	patch, err := ParseJSONPatch(c.Body())
+	if err != nil {
+		return err
+	}
+	*p = patch
+	return nil
+}
+
+// ParseJSONPatch parses and checks the operations of a JSON Patch document, a JSONPatchError reports
+// the first invalid one.
+func ParseJSONPatch(document []byte) (JSONPatch, error) {
+	var operations []PatchOperation
+	if err := json.Unmarshal(document, &operations); err != nil {
+		return JSONPatch{}, NewBadRequestError("JSON patch must be an array of operations")
+	}
+	for i, op := range operations {
+		if err := op.check(); err != nil {
+			return JSONPatch{}, NewJSONPatchError(ErrInvalidPatch, i, op, err.Error())
+		}
+	}
+	return JSONPatch{Operations: operations}, nil
+}
+
+func (op PatchOperation) check() error {
+	if _, err := parsePointer(op.Path); err != nil {
+		return err
+	}
+	switch op.Op {
+	case PatchAdd, PatchReplace, PatchTest:
+		if op.Value == nil {
+			return fmt.Errorf("missing value")
+		}
+	case PatchMove, PatchCopy:
+		if _, err := parsePointer(op.From); err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		if op.Op == PatchMove && strings.HasPrefix(op.Path, op.From+"/") {
+			return fmt.Errorf("cannot move a value into itself")
+		}
+	case PatchRemove:
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	return nil
+}
+
+// Apply applies the operations in order to the JSON document and returns the patched document. The
+// patch is atomic, document is left untouched when an operation fails.
+func (p JSONPatch) Apply(document []byte) ([]byte, error) {
+	var doc any
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, err
+	}
+	for i, op := range p.Operations {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, NewJSONPatchError(err, i, op)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// ApplyTo applies the operations to the struct, map or slice pointed to by targetPtr through its JSON
+// encoding. targetPtr is only updated when the whole patch applies.
+func (p JSONPatch) ApplyTo(targetPtr any) error {
+	target := reflect.ValueOf(targetPtr)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("JSONPatch.ApplyTo: target must be a non-nil pointer, got %T", targetPtr)
+	}
+	document, err := json.Marshal(targetPtr)
+	if err != nil {
+		return err
+	}
+	patched, err := p.Apply(document)
+	if err != nil {
+		return err
+	}
+
+	result := reflect.New(target.Elem().Type())
+	if err := json.Unmarshal(patched, result.Interface()); err != nil {
+		message := "Patched document does not fit the resource"
+		if detail := describeParseError(err); detail != "" {
+			message += ": " + detail
+		}
+		return NewUnprocessableError(message)
+	}
+	target.Elem().Set(result.Elem())
+	return nil
+}
+
+func (op PatchOperation) apply(doc any) (any, error) {
+	path, _ := parsePointer(op.Path)
+	switch op.Op {
+	case PatchAdd, PatchReplace, PatchTest:
+		var value any
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, ErrInvalidPatch
+		}
+		switch op.Op {
+		case PatchAdd:
+			return addValue(doc, path, value)
+		case PatchReplace:
+			return replaceValue(doc, path, value)
+		}
+		current, err := pointerValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	case PatchRemove:
+		return removeValue(doc, path)
+	case PatchMove, PatchCopy:
+		from, _ := parsePointer(op.From)
+		value, err := pointerValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == PatchMove {
+			if doc, err = removeValue(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = copyJSONValue(value)
+		}
+		return addValue(doc, path, value)
+	}
+	return nil, ErrInvalidPatch
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens, none for the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses the index of an array of length n, n itself (or "-") only when appending.
+func arrayIndex(token string, n int, appending bool) (int, bool) {
+	if appending && token == "-" {
+		return n, true
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > n || (i == n && !appending) {
+		return 0, false
+	}
+	return i, true
+}
+
+func pointerValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			doc = value
+		case []any:
+			i, ok := arrayIndex(token, len(node), false)
+			if !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			doc = node[i]
+		default:
+			return nil, ErrPatchPathNotFound
+		}
+	}
+	return doc, nil
+}
+
+// updateParent calls update with the container holding the last token of path and stores the
+// container it returns, slices are reallocated when they grow or shrink.
+func updateParent(doc any, path []string, update func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+	switch node := doc.(type) {
+	case map[string]any:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, ErrPatchPathNotFound
+		}
+		updated, err := updateParent(child, path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		node[path[0]] = updated
+		return node, nil
+	case []any:
+		i, ok := arrayIndex(path[0], len(node), false)
+		if !ok {
+			return nil, ErrPatchPathNotFound
+		}
+		updated, err := updateParent(node[i], path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = updated
+		return node, nil
+	}
+	return nil, ErrPatchPathNotFound
+}
+
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			i, ok := arrayIndex(token, len(node), true)
+			if !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			return slices.Insert(node, i, value), nil
+		}
+		return nil, ErrPatchPathNotFound
+	})
+}
+
+func removeValue(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, ErrInvalidPatch
+	}
+	return updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			delete(node, token)
+			return node, nil
+		case []any:
+			i, ok := arrayIndex(token, len(node), false)
+			if !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			return slices.Delete(node, i, i+1), nil
+		}
+		return nil, ErrPatchPathNotFound
+	})
+}
+
+func replaceValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			node[token] = value
+			return node, nil
+		case []any:
+			i, ok := arrayIndex(token, len(node), false)
+			if !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			node[i] = value
+			return node, nil
+		}
+		return nil, ErrPatchPathNotFound
+	})
+}
+
+// copyJSONValue deep copies a decoded JSON value, a copied object must not alias its source.
+func copyJSONValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, item := range v {
+			copied[key] = copyJSONValue(item)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = copyJSONValue(item)
+		}
+		return copied
+	}
+	return value
+}
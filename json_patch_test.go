@@ -0,0 +1,147 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+func TestJSONPatchApply(t *testing.T) {
+	const document = `{"name":"Ada","tags":["a","b"],"address":{"city":"London"},"a/b":1,"m~n":2}`
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+		err   error
+	}{
+		{name: "add field", patch: `[{"op":"add","path":"/email","value":"ada@example.com"}]`, want: `{"a/b":1,"address":{"city":"London"},"email":"ada@example.com","m~n":2,"name":"Ada","tags":["a","b"]}`},
+		{name: "add replaces field", patch: `[{"op":"add","path":"/name","value":"Grace"}]`, want: `{"a/b":1,"address":{"city":"London"},"m~n":2,"name":"Grace","tags":["a","b"]}`},
+		{name: "add array element", patch: `[{"op":"add","path":"/tags/1","value":"x"}]`, want: `{"a/b":1,"address":{"city":"London"},"m~n":2,"name":"Ada","tags":["a","x","b"]}`},
+		{name: "append array element", patch: `[{"op":"add","path":"/tags/-","value":"c"}]`, want: `{"a/b":1,"address":{"city":"London"},"m~n":2,"name":"Ada","tags":["a","b","c"]}`},
+		{name: "add past array end", patch: `[{"op":"add","path":"/tags/3","value":"c"}]`, err: fiberhandler.ErrPatchPathNotFound},
+		{name: "add to missing parent", patch: `[{"op":"add","path":"/missing/city","value":"x"}]`, err: fiberhandler.ErrPatchPathNotFound},
+		{name: "remove field", patch: `[{"op":"remove","path":"/address"}]`, want: `{"a/b":1,"m~n":2,"name":"Ada","tags":["a","b"]}`},
+		{name: "remove array element", patch: `[{"op":"remove","path":"/tags/0"}]`, want: `{"a/b":1,"address":{"city":"London"},"m~n":2,"name":"Ada","tags":["b"]}`},
+		{name: "remove missing", patch: `[{"op":"remove","path":"/email"}]`, err: fiberhandler.ErrPatchPathNotFound},
+		{name: "replace nested", patch: `[{"op":"replace","path":"/address/city","value":"Paris"}]`, want: `{"a/b":1,"address":{"city":"Paris"},"m~n":2,"name":"Ada","tags":["a","b"]}`},
+		{name: "replace missing", patch: `[{"op":"replace","path":"/email","value":"x"}]`, err: fiberhandler.ErrPatchPathNotFound},
+		{name: "escaped pointers", patch: `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, want: `{"a/b":3,"address":{"city":"London"},"name":"Ada","tags":["a","b"]}`},
+		{name: "move", patch: `[{"op":"move","from":"/address/city","path":"/city"}]`, want: `{"a/b":1,"address":{},"city":"London","m~n":2,"name":"Ada","tags":["a","b"]}`},
+		{name: "copy", patch: `[{"op":"copy","from":"/tags","path":"/labels"},{"op":"add","path":"/labels/-","value":"c"}]`, want: `{"a/b":1,"address":{"city":"London"},"labels":["a","b","c"],"m~n":2,"name":"Ada","tags":["a","b"]}`},
+		{name: "copy missing", patch: `[{"op":"copy","from":"/email","path":"/contact"}]`, err: fiberhandler.ErrPatchPathNotFound},
+		{name: "test passes", patch: `[{"op":"test","path":"/tags","value":["a","b"]},{"op":"remove","path":"/tags"}]`, want: `{"a/b":1,"address":{"city":"London"},"m~n":2,"name":"Ada"}`},
+		{name: "test fails", patch: `[{"op":"test","path":"/name","value":"Grace"}]`, err: fiberhandler.ErrPatchTestFailed},
+		{name: "replace whole document", patch: `[{"op":"replace","path":"","value":{"id":1}}]`, want: `{"id":1}`},
+		{name: "leading zero index", patch: `[{"op":"remove","path":"/tags/01"}]`, err: fiberhandler.ErrPatchPathNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := fiberhandler.ParseJSONPatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := patch.Apply([]byte(document))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("document = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		index int
+	}{
+		{name: "unknown op", patch: `[{"op":"merge","path":"/a"}]`},
+		{name: "missing value", patch: `[{"op":"add","path":"/a"}]`},
+		{name: "invalid pointer", patch: `[{"op":"remove","path":"a"}]`},
+		{name: "invalid from", patch: `[{"op":"move","from":"a","path":"/b"}]`},
+		{name: "move into itself", patch: `[{"op":"move","from":"/a","path":"/a/b"}]`},
+		{name: "second operation", patch: `[{"op":"remove","path":"/a"},{"op":"copy","from":"b","path":"/c"}]`, index: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fiberhandler.ParseJSONPatch([]byte(tt.patch))
+			var patchErr *fiberhandler.JSONPatchError
+			if !errors.As(err, &patchErr) {
+				t.Fatalf("err = %v, want a JSONPatchError", err)
+			}
+			if patchErr.Index != tt.index || !errors.Is(err, fiberhandler.ErrInvalidPatch) {
+				t.Errorf("err = %v at %d, want ErrInvalidPatch at %d", err, patchErr.Index, tt.index)
+			}
+		})
+	}
+
+	if _, err := fiberhandler.ParseJSONPatch([]byte(`{"op":"remove","path":"/a"}`)); !errors.Is(err, fiberhandler.ErrBadRequest) {
+		t.Errorf("err = %v, want a BadRequestError for a patch that is not an array", err)
+	}
+}
+
+type patchedUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestJSONPatchRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		want        int
+		code        string
+		user        patchedUser
+	}{
+		{name: "applied", contentType: fiberhandler.MIMEApplicationJSONPatchJSON, patch: `[{"op":"replace","path":"/age","value":37}]`, want: http.StatusOK, user: patchedUser{Name: "Ada", Age: 37}},
+		{name: "all or nothing", contentType: fiberhandler.MIMEApplicationJSONPatchJSON, patch: `[{"op":"replace","path":"/age","value":37},{"op":"test","path":"/name","value":"Grace"}]`, want: http.StatusConflict, code: "CLE047", user: patchedUser{Name: "Ada", Age: 36}},
+		{name: "missing path", contentType: fiberhandler.MIMEApplicationJSONPatchJSON, patch: `[{"op":"remove","path":"/email"}]`, want: http.StatusUnprocessableEntity, code: "CLE046", user: patchedUser{Name: "Ada", Age: 36}},
+		{name: "invalid operation", contentType: fiberhandler.MIMEApplicationJSONPatchJSON, patch: `[{"op":"merge","path":"/age"}]`, want: http.StatusBadRequest, code: "CLE045", user: patchedUser{Name: "Ada", Age: 36}},
+		{name: "wrong type", contentType: fiberhandler.MIMEApplicationJSONPatchJSON, patch: `[{"op":"replace","path":"/age","value":"old"}]`, want: http.StatusUnprocessableEntity, user: patchedUser{Name: "Ada", Age: 36}},
+		{name: "unsupported media type", contentType: fiber.MIMETextPlain, patch: `[]`, want: http.StatusUnsupportedMediaType, user: patchedUser{Name: "Ada", Age: 36}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{})
+			user := patchedUser{Name: "Ada", Age: 36}
+			handler := func(c *fiber.Ctx) error {
+				patch := fiberhandler.JSONPatch{}
+				return handle.Do(c, &patch, true, func(ctx context.Context) (any, error) {
+					if err := patch.ApplyTo(&user); err != nil {
+						return nil, err
+					}
+					return user, nil
+				})
+			}
+
+			response := fiberhandlertest.Patch("/users/1").
+				Body(tt.contentType, []byte(tt.patch)).
+				Run(t, handler).
+				Status(tt.want)
+			if tt.code != "" {
+				response.Code(tt.code)
+			}
+			if user != tt.user {
+				t.Errorf("user = %+v, want %+v", user, tt.user)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package fiberhandler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/gopkg/core"
+)
+
+// WithLazyClaims parses the token of the TokenParser only when the claims are needed, so public
+// endpoints do not pay for decoding it. The claims are parsed before doFunc when the request implements
+// core.Request, the route requires authentication or is protected, or a tenant resolver is configured;
+// otherwise on the first ClaimsFromContext. A token failing to parse then fails the request with its
+// TokenError (401) once doFunc returns, ClaimsFromContext reports no claims meanwhile. Authenticators
+// are always called before doFunc.
+func WithLazyClaims() Option {
+	return func(o *options) {
+		o.lazyClaims = true
+	}
+}
+
+// lazyClaims parses the claims of the caller on their first use.
+type lazyClaims[T any] struct {
+	mu     sync.Mutex
+	parsed bool
+	parse  func(ctx context.Context) (*T, error)
+	claims *T
+	err    error
+}
+
+func (l *lazyClaims[T]) get(ctx context.Context) (*T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.parsed {
+		l.claims, l.err = l.parse(ctx)
+		l.parsed = true
+	}
+	return l.claims, l.err
+}
+
+// failed returns the error of the claims once they were parsed, nil before.
+func (l *lazyClaims[T]) failed() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// resolveClaims returns the claims of ctx, parsing lazy claims.
+func resolveClaims[T any](ctx context.Context) (*T, error) {
+	if lazy, ok := ctx.Value(claimsKey{}).(*lazyClaims[T]); ok {
+		return lazy.get(ctx)
+	}
+	claims, _ := ClaimsFromContext[T](ctx)
+	return claims, nil
+}
+
+// lazyClaimsError returns the error of the lazy claims of ctx when doFunc used them and they failed to
+// parse.
+func lazyClaimsError[T any](ctx context.Context) error {
+	if lazy, ok := ctx.Value(claimsKey{}).(*lazyClaims[T]); ok {
+		return lazy.failed()
+	}
+	return nil
+}
+
+// deferClaims stores the token of the request in its context to be parsed by ClaimsFromContext. It
+// returns false when the claims are needed before doFunc.
+func (h *apiHandler[T]) deferClaims(c *fiber.Ctx, requestPtr any) bool {
+	if !h.options.lazyClaims || h.Authenticator != nil {
+		return false
+	}
+	if _, ok := requestPtr.(core.Request[T]); ok {
+		return false
+	}
+	if h.options.tenantResolver != nil {
+		return false
+	}
+	route := CurrentRoute(c)
+	if h.requiresAuth(route) || (route != nil && route.Protected()) {
+		return false
+	}
+
+	// The token is read now, the fiber.Ctx is recycled before a job or a stream reads the claims
+	token := h.lookupToken(c)
+	if token == "" {
+		return true
+	}
+	lazy := &lazyClaims[T]{parse: func(ctx context.Context) (*T, error) {
+		return h.parseToken(ctx, token)
+	}}
+	c.SetUserContext(context.WithValue(c.UserContext(), claimsKey{}, lazy))
+	return true
+}
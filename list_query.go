@@ -0,0 +1,177 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FilterOp is the operator of a filter, the second bracket of filter[field][op].
+type FilterOp string
+
+const (
+	FilterEq   FilterOp = "eq"
+	FilterNe   FilterOp = "ne"
+	FilterGt   FilterOp = "gt"
+	FilterGte  FilterOp = "gte"
+	FilterLt   FilterOp = "lt"
+	FilterLte  FilterOp = "lte"
+	FilterIn   FilterOp = "in"
+	FilterLike FilterOp = "like"
+)
+
+var filterOps = []FilterOp{FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterIn, FilterLike}
+
+const (
+	defaultFilterQuery = "filter"
+	defaultSortQuery   = "sort"
+)
+
+// Filter is a condition of a list query, Values holds the comma separated values of FilterIn.
+type Filter struct {
+	Field  string   `json:"field"`
+	Op     FilterOp `json:"op"`
+	Value  string   `json:"value"`
+	Values []string `json:"values,omitempty"`
+}
+
+// ListSpec allowlists the filters and sorts of a list endpoint.
+type ListSpec struct {
+	// Filters maps the fields clients may filter on to their operators, all of them when empty.
+	Filters map[string][]FilterOp
+
+	// Sorts lists the fields clients may sort on, Sort applies when the client sends none, e.g. "-created_at".
+	Sorts []string
+	Sort  string
+}
+
+// ListRequest is implemented by requests of list endpoints, embed ListQuery and declare the ListSpec.
+type ListRequest interface {
+	ListSpec() ListSpec
+	SetListQuery(query ListQuery)
+}
+
+// ListQuery is embedded in requests to receive the filters and sort of the query,
+// ?filter[status]=active&filter[age][gte]=18&filter[role][in]=admin,owner&sort=-created_at,name:
+//
+//	type ListUsers struct {
+//		fiberhandler.Pageable
+//		fiberhandler.ListQuery
+//	}
+//
+//	func (ListUsers) ListSpec() fiberhandler.ListSpec {
+//		return fiberhandler.ListSpec{
+//			Filters: map[string][]fiberhandler.FilterOp{"status": {fiberhandler.FilterEq, fiberhandler.FilterIn}, "age": nil},
+//			Sorts:   []string{"created_at", "name"},
+//			Sort:    "-created_at",
+//		}
+//	}
+//
+// A filter or sort outside of the spec is answered with 400.
+type ListQuery struct {
+	Filters []Filter    `json:"-" query:"-" form:"-"`
+	Order   []SortField `json:"-" query:"-" form:"-"`
+}
+
+// SetListQuery implements ListRequest.
+func (q *ListQuery) SetListQuery(query ListQuery) {
+	*q = query
+}
+
+// Filter returns the filters on field.
+func (q *ListQuery) Filter(field string) []Filter {
+	var filters []Filter
+	for _, filter := range q.Filters {
+		if filter.Field == field {
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
+
+// ParseListQuery parses the filters and sort of the query of c allowed by spec, a BadRequestError names
+// the first one that is not.
+func ParseListQuery(c *fiber.Ctx, spec ListSpec) (ListQuery, error) {
+	query := ListQuery{}
+	var err error
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		if err != nil {
+			return
+		}
+		var filter Filter
+		var ok bool
+		if filter, ok, err = parseFilter(string(key), string(value)); ok && err == nil {
+			err = spec.allowFilter(filter)
+			query.Filters = append(query.Filters, filter)
+		}
+	})
+	if err != nil {
+		return ListQuery{}, err
+	}
+
+	sort := c.Query(defaultSortQuery)
+	if sort == "" {
+		sort = spec.Sort
+	}
+	query.Order = parseSortFields(sort)
+	for _, field := range query.Order {
+		if !slices.Contains(spec.Sorts, field.Field) {
+			return ListQuery{}, NewBadRequestError(fmt.Sprintf("Cannot sort by '%s'", field.Field))
+		}
+	}
+	return query, nil
+}
+
+// parseFilter parses a filter[field] or filter[field][op] parameter, it reports false for other keys.
+func parseFilter(key, value string) (Filter, bool, error) {
+	rest, ok := strings.CutPrefix(key, defaultFilterQuery+"[")
+	if !ok {
+		return Filter{}, false, nil
+	}
+	field, rest, ok := strings.Cut(rest, "]")
+	if !ok || field == "" {
+		return Filter{}, true, NewBadRequestError(fmt.Sprintf("Invalid filter '%s'", key))
+	}
+
+	filter := Filter{Field: field, Op: FilterEq, Value: value}
+	if rest != "" {
+		op := strings.TrimSuffix(strings.TrimPrefix(rest, "["), "]")
+		if rest != "["+op+"]" || !slices.Contains(filterOps, FilterOp(op)) {
+			return Filter{}, true, NewBadRequestError(fmt.Sprintf("Invalid filter operator in '%s'", key))
+		}
+		filter.Op = FilterOp(op)
+	}
+	if filter.Op == FilterIn {
+		for _, item := range strings.Split(value, ",") {
+			filter.Values = append(filter.Values, strings.TrimSpace(item))
+		}
+	}
+	return filter, true, nil
+}
+
+func (s ListSpec) allowFilter(filter Filter) error {
+	ops, ok := s.Filters[filter.Field]
+	if !ok {
+		return NewBadRequestError(fmt.Sprintf("Cannot filter by '%s'", filter.Field))
+	}
+	if len(ops) > 0 && !slices.Contains(ops, filter.Op) {
+		return NewBadRequestError(fmt.Sprintf("Cannot filter '%s' with '%s'", filter.Field, filter.Op))
+	}
+	return nil
+}
+
+// bindListQuery sets the filters and sort of requests implementing ListRequest.
+func bindListQuery(c *fiber.Ctx, requestPtr any) error {
+	listReq, ok := requestPtr.(ListRequest)
+	if !ok {
+		return nil
+	}
+	query, err := ParseListQuery(c, listReq.ListSpec())
+	if err != nil {
+		return err
+	}
+	listReq.SetListQuery(query)
+	return nil
+}
@@ -0,0 +1,132 @@
+package fiberhandler
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultLocaleQuery = "lang"
+
+// LocaleConfig configures WithLocales.
+type LocaleConfig struct {
+	// Supported lists the BCP 47 tags of the supported locales, e.g. "en", "th" or "pt-BR". Requests
+	// matching none of them get the first one.
+	Supported []string
+
+	// Query names the query parameter overriding Accept-Language, default "lang". "-" disables it.
+	Query string
+}
+
+type localeMatcher struct {
+	supported []string
+	query     string
+}
+
+// WithLocales detects the locale of every request from the query parameter of config, then the
+// Accept-Language header by quality, and normalizes it to one of the supported locales. doFunc and
+// error formatting read it with LocaleFromContext.
+func WithLocales(config LocaleConfig) Option {
+	matcher := &localeMatcher{supported: config.Supported, query: config.Query}
+	if matcher.query == "" {
+		matcher.query = defaultLocaleQuery
+	}
+	return func(o *options) {
+		o.locales = matcher
+	}
+}
+
+// bindLocale puts the locale of the request into the user context.
+func (h *apiHandler[T]) bindLocale(c *fiber.Ctx) {
+	matcher := h.options.locales
+	if matcher == nil || len(matcher.supported) == 0 {
+		return
+	}
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.SetUserContext(ContextWithLocale(c.UserContext(), matcher.detect(c)))
+}
+
+func (m *localeMatcher) detect(c *fiber.Ctx) string {
+	if m.query != "-" {
+		if locale, ok := m.match(c.Query(m.query)); ok {
+			return locale
+		}
+	}
+	for _, tag := range parseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)) {
+		if tag == "*" {
+			break
+		}
+		if locale, ok := m.match(tag); ok {
+			return locale
+		}
+	}
+	return m.supported[0]
+}
+
+// match returns the supported locale of tag: the same tag, its language, or a region of its language.
+func (m *localeMatcher) match(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return "", false
+	}
+	for _, locale := range m.supported {
+		if strings.EqualFold(locale, tag) {
+			return locale, true
+		}
+	}
+
+	language, _, _ := strings.Cut(tag, "-")
+	for _, locale := range m.supported {
+		if strings.EqualFold(locale, language) {
+			return locale, true
+		}
+	}
+	for _, locale := range m.supported {
+		supportedLanguage, _, _ := strings.Cut(locale, "-")
+		if strings.EqualFold(supportedLanguage, language) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// parseAcceptLanguage returns the tags of an Accept-Language header by decreasing quality, dropping
+// the tags with q=0.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+
+	slices.SortStableFunc(tags, func(a, b weighted) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
@@ -0,0 +1,178 @@
+package fiberhandler
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/goerror"
+)
+
+// MIMEApplicationMergePatchJSON is the media type of RFC 7386 JSON Merge Patch documents.
+const MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+
+// FieldMask lists the fields present in a merge patch by their dotted JSON path, e.g. "address.city".
+// A field absent from the mask was not provided, a field set to null is present and IsNull.
+type FieldMask struct {
+	// fields maps the path of every present field to whether it is null
+	fields map[string]bool
+}
+
+// Has reports whether the field at path was provided.
+func (m FieldMask) Has(path string) bool {
+	_, ok := m.fields[path]
+	return ok
+}
+
+// IsNull reports whether the field at path was set to null, to be removed.
+func (m FieldMask) IsNull(path string) bool {
+	return m.fields[path]
+}
+
+// Paths returns the sorted paths of the provided fields.
+func (m FieldMask) Paths() []string {
+	paths := make([]string, 0, len(m.fields))
+	for path := range m.fields {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func (m FieldMask) collect(patch map[string]any, prefix string) {
+	for key, value := range patch {
+		path := prefix + key
+		m.fields[path] = value == nil
+		if object, ok := value.(map[string]any); ok {
+			m.collect(object, path+".")
+		}
+	}
+}
+
+// DoPatch runs the pipeline of Do on a JSON Merge Patch (RFC 7386) body, application/merge-patch+json
+// or application/json. The request struct holds the provided values, FieldMaskFromContext tells doFunc
+// which fields were provided, set to null or left out, so a zero value is not mistaken for "unchanged":
+//
+//	return handle.DoPatch(c, &req, true, func(ctx context.Context) (any, error) {
+//		mask, _ := fiberhandler.FieldMaskFromContext(ctx)
+//		if mask.Has("nickname") {
+//			user.Nickname = req.Nickname
+//		}
+//		return users.Save(ctx, user)
+//	})
+//
+// Defaults are not applied and, with a *validator.Validate, only the provided fields are validated.
+func (h *apiHandler[T]) DoPatch(c *fiber.Ctx, requestPtr any, validateRequest bool, doFunc DoFunc) error {
+	if err := h.limitRate(c); err != nil {
+		return h.SendError(c, err)
+	}
+
+	mask, err := parseMergePatch(c)
+	if err != nil {
+		slog.Error("Invalid request", h.redactor().errorAttr(err))
+		return h.SendError(c, err)
+	}
+	c.SetUserContext(ContextWithFieldMask(c.UserContext(), mask))
+	return h.Do(c, requestPtr, validateRequest, doFunc)
+}
+
+func parseMergePatch(c *fiber.Ctx) (FieldMask, error) {
+	if !isJSON(c) {
+		return FieldMask{}, &ResponseError{
+			Body:   goerror.Body{Message: "Unsupported media type, use " + MIMEApplicationMergePatchJSON},
+			Status: http.StatusUnsupportedMediaType,
+		}
+	}
+
+	var patch map[string]any
+	if err := json.Unmarshal(c.Body(), &patch); err != nil || patch == nil {
+		return FieldMask{}, NewBadRequestError("Merge patch must be a JSON object")
+	}
+	mask := FieldMask{fields: map[string]bool{}}
+	mask.collect(patch, "")
+	return mask, nil
+}
+
+// ApplyMergePatch applies the RFC 7386 merge patch to the JSON document original: null removes a
+// member, objects are merged recursively and any other value replaces the target.
+func ApplyMergePatch(original, patch []byte) ([]byte, error) {
+	var target, changes any
+	if len(original) > 0 {
+		if err := json.Unmarshal(original, &target); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(target, changes))
+}
+
+func mergePatch(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+		object[key] = mergePatch(object[key], value)
+	}
+	return object
+}
+
+// partialValidator validates the named fields of a struct, *validator.Validate implements it.
+type partialValidator interface {
+	StructPartial(s any, fields ...string) error
+}
+
+// validateStruct validates requestPtr, only the fields provided in a merge patch with a partialValidator.
+func (h *apiHandler[T]) validateStruct(c *fiber.Ctx, requestPtr any) error {
+	mask, ok := FieldMaskFromContext(c.UserContext())
+	validate, partial := h.Validate.(partialValidator)
+	if !ok || !partial {
+		return h.Validate.Struct(requestPtr)
+	}
+
+	t := reflect.TypeOf(requestPtr)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var fields []string
+	for _, path := range mask.Paths() {
+		if namespace, ok := structNamespace(t, path); ok {
+			fields = append(fields, namespace)
+		}
+	}
+	return validate.StructPartial(requestPtr, fields...)
+}
+
+// structNamespace maps the dotted JSON path of a field to its Go namespace, e.g. "Address.City".
+func structNamespace(t reflect.Type, path string) (string, bool) {
+	var names []string
+	for _, key := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return "", false
+		}
+		field, ok := jsonField(t, key)
+		if !ok {
+			return "", false
+		}
+		names = append(names, field.Name)
+		t = field.Type
+	}
+	return strings.Join(names, "."), true
+}
@@ -0,0 +1,64 @@
+package fiberhandler
+
+import (
+	"crypto/x509"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CertificateIdentity is the identity of a client certificate, usable as claims.
+type CertificateIdentity struct {
+	CommonName         string   `json:"cn"`
+	Organization       []string `json:"o,omitempty"`
+	OrganizationalUnit []string `json:"ou,omitempty"`
+	DNSNames           []string `json:"dnsNames,omitempty"`
+	URIs               []string `json:"uris,omitempty"`
+	EmailAddresses     []string `json:"emailAddresses,omitempty"`
+	SerialNumber       string   `json:"serialNumber"`
+}
+
+// NewCertificateIdentity returns the identity of cert, e.g. to build the claims of CertificateAuthenticator.
+func NewCertificateIdentity(cert *x509.Certificate) *CertificateIdentity {
+	identity := &CertificateIdentity{
+		CommonName:         cert.Subject.CommonName,
+		Organization:       cert.Subject.Organization,
+		OrganizationalUnit: cert.Subject.OrganizationalUnit,
+		DNSNames:           cert.DNSNames,
+		EmailAddresses:     cert.EmailAddresses,
+		SerialNumber:       cert.SerialNumber.String(),
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
+
+// ClientCertificate returns the leaf certificate of the client when the TLS handshake verified its
+// chain, nil otherwise. The server must request client certificates, e.g. tls.Config.ClientAuth set
+// to tls.VerifyClientCertIfGiven.
+func ClientCertificate(c *fiber.Ctx) *x509.Certificate {
+	state := c.Context().TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// CertificateAuthenticator authenticates the callers of services running with mutual TLS by their
+// verified client certificate, claims builds the claims from it. Requests without one are anonymous.
+// Certificates presented to a proxy terminating TLS are not seen:
+//
+//	fiberhandler.NewWithComponents(fiberhandler.Components[fiberhandler.CertificateIdentity]{
+//		Authenticator: fiberhandler.CertificateAuthenticator(func(cert *x509.Certificate) (*fiberhandler.CertificateIdentity, error) {
+//			return fiberhandler.NewCertificateIdentity(cert), nil
+//		}),
+//	})
+func CertificateAuthenticator[T any](claims func(cert *x509.Certificate) (*T, error)) Authenticator[T] {
+	return AuthenticatorFunc[T](func(c *fiber.Ctx) (*T, error) {
+		cert := ClientCertificate(c)
+		if cert == nil {
+			return nil, nil
+		}
+		return claims(cert)
+	})
+}
@@ -0,0 +1,191 @@
+package fiberhandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const defaultMultipartMemory = 32 << 20
+
+type multipartFormKey struct{}
+
+// WithFileCommit calls commit for every file bound to the request once doFunc succeeded, e.g. to move
+// accepted files to permanent storage with MoveFile. Files not committed are removed after the response.
+func WithFileCommit(commit func(ctx context.Context, fieldName string, file *multipart.FileHeader) error) Option {
+	return func(o *options) {
+		o.fileCommit = commit
+	}
+}
+
+// FormFieldLimits caps the length of multipart form values in characters, see WithFormFieldLimits.
+type FormFieldLimits struct {
+	// Default applies to every field without its own limit, 0 leaves them unlimited.
+	Default int
+
+	// Fields sets the limit of single fields by name.
+	Fields map[string]int
+}
+
+// WithFormFieldLimits rejects multipart requests whose form values exceed their limit with a validation
+// error naming the field, before the value reaches the request struct.
+func WithFormFieldLimits(limits FormFieldLimits) Option {
+	return func(o *options) {
+		o.formFieldLimits = &limits
+	}
+}
+
+func (l *FormFieldLimits) check(fieldName, value string) error {
+	limit, ok := l.Fields[fieldName]
+	if !ok {
+		limit = l.Default
+	}
+	// Bytes bound the characters, count them only when the value may be too long
+	if limit <= 0 || len(value) <= limit || utf8.RuneCountInString(value) <= limit {
+		return nil
+	}
+
+	description := fmt.Sprintf("must be at most %d characters", limit)
+	dataInvalid := NewDataInvalidError().(*DataInvalidError)
+	dataInvalid.Message = fmt.Sprintf("Field '%s' %s", fieldName, description)
+	dataInvalid.Violations = []FieldViolation{{Field: fieldName, Description: description}}
+	return dataInvalid
+}
+
+// WithMultipartMemory limits the memory used to parse a multipart form to maxMemory bytes, larger
+// files spill to temporary files that are removed once the request is handled.
+//
+// The limit only bounds memory with fiber.Config.StreamRequestBody enabled, otherwise fasthttp has
+// buffered the whole body, up to fiber.Config.BodyLimit, before the handler runs and the form is
+// parsed from that buffer.
+//
+// Files spill to os.TempDir and are removed once the response is sent, set TMPDIR to move them to a
+// larger volume. There is no spill directory per handler or route. Although this path reads the parts
+// itself, files written elsewhere could not be bound: DoMultipart, the upload hooks and the storage sink
+// hand out *multipart.FileHeader, whose Open only reads the files mime/multipart created in
+// os.TempDir.
+func WithMultipartMemory(maxMemory int64) Option {
+	return func(o *options) {
+		o.multipartMemory = maxMemory
+	}
+}
+
+// parseMultipartForm parses the multipart form with the configured memory limit. The returned cleanup
+// removes the temporary files of the form and must always be called.
+func (h *apiHandler[T]) parseMultipartForm(c *fiber.Ctx) (*multipart.Form, func(), error) {
+	if h.options.multipartMemory <= 0 && h.options.uploadProgress == nil {
+		form, err := c.MultipartForm()
+		return form, c.Request().RemoveMultipartFormFiles, err
+	}
+
+	maxMemory := h.options.multipartMemory
+	if maxMemory <= 0 {
+		maxMemory = defaultMultipartMemory
+	}
+
+	boundary := string(c.Request().Header.MultipartFormBoundary())
+	if boundary == "" {
+		return nil, func() {}, fasthttp.ErrNoMultipartForm
+	}
+
+	var body io.Reader = c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	var progress *progressReader
+	if h.options.uploadProgress != nil {
+		progress = &progressReader{
+			reader: body,
+			progress: UploadProgress{
+				UploadID: uploadID(c),
+				Total:    int64(c.Request().Header.ContentLength()),
+			},
+			report: h.options.uploadProgress,
+		}
+		body = progress
+	}
+
+	form, err := multipart.NewReader(body, boundary).ReadForm(maxMemory)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	// The reader stops at the closing boundary, which may come before EOF
+	if progress != nil && !progress.progress.Done {
+		progress.progress.Done = true
+		progress.report(progress.progress)
+	}
+	c.Locals(multipartFormKey{}, form)
+
+	return form, func() {
+		_ = form.RemoveAll()
+	}, nil
+}
+
+// formValue returns the form value like fiber.Ctx.FormValue, reading the form parsed by
+// parseMultipartForm instead of letting fasthttp parse the body again.
+func (h *apiHandler[T]) formValue(c *fiber.Ctx, key string) string {
+	form, ok := c.Locals(multipartFormKey{}).(*multipart.Form)
+	if !ok {
+		return c.FormValue(key)
+	}
+	if value := c.Context().QueryArgs().Peek(key); value != nil {
+		return string(value)
+	}
+	if values := form.Value[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// formFile returns the first file of key like fiber.Ctx.FormFile.
+func (h *apiHandler[T]) formFile(c *fiber.Ctx, key string) (*multipart.FileHeader, error) {
+	form, ok := c.Locals(multipartFormKey{}).(*multipart.Form)
+	if !ok {
+		return c.FormFile(key)
+	}
+	if files := form.File[key]; len(files) > 0 {
+		return files[0], nil
+	}
+	return nil, fasthttp.ErrMissingFile
+}
+
+// MoveFile atomically moves an uploaded file to dst. Files spilled to disk are renamed when possible,
+// otherwise the content is written to a temporary file next to dst that is renamed once complete.
+func MoveFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if f, ok := src.(*os.File); ok {
+		if err := os.Rename(f.Name(), dst); err == nil {
+			return nil
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
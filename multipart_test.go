@@ -0,0 +1,76 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+type upload struct {
+	File *multipart.FileHeader `form:"file"`
+}
+
+func (u *upload) FormFields() map[string]any {
+	return map[string]any{}
+}
+
+func (u *upload) FileFields() map[string]**multipart.FileHeader {
+	return map[string]**multipart.FileHeader{"file": &u.File}
+}
+
+func TestMultipartMemorySpill(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", want: http.StatusOK},
+		{name: "failure", err: errors.New("failed"), want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+			spilled := func() int {
+				entries, err := os.ReadDir(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return len(entries)
+			}
+
+			handle := fiberhandler.NewWithComponents(
+				fiberhandler.Components[testClaims]{},
+				fiberhandler.WithMultipartMemory(1024),
+			)
+			fiberhandlertest.Post("/uploads").
+				File("file", "large.txt", []byte(strings.Repeat("a", 4096)), "text/plain").
+				Run(t, func(c *fiber.Ctx) error {
+					req := upload{}
+					return handle.DoMultipart(c, &req, false, []string{"text/plain"}, func(ctx context.Context) (any, error) {
+						if req.File == nil || req.File.Size != 4096 {
+							t.Fatalf("file = %+v", req.File)
+						}
+						if spilled() != 1 {
+							t.Errorf("spilled files = %d, want 1", spilled())
+						}
+						return nil, tt.err
+					})
+				}).
+				Status(tt.want)
+
+			if spilled() != 0 {
+				t.Errorf("spilled files left after the request = %d", spilled())
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package fiberhandler
+
+import (
+	"bufio"
+	"context"
+	"iter"
+	"log/slog"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// NDJSONResult streams items as newline-delimited JSON, flushing every item as it is produced.
+// An item that is an error is sent as {"error": "..."} and ends the stream.
+type NDJSONResult struct {
+	Items iter.Seq[any]
+}
+
+// NDJSON streams the values received from items until the channel is closed. The producer should stop
+// sending once the doFunc context is done, which is cancelled when the stream ends, fails to write or
+// the client is gone. HEAD requests cancel it without streaming.
+// A doFunc may also return a <-chan any directly.
+func NDJSON[T any](items <-chan T) *NDJSONResult {
+	return &NDJSONResult{
+		Items: func(yield func(any) bool) {
+			for item := range items {
+				if !yield(item) {
+					return
+				}
+			}
+		},
+	}
+}
+
+// NDJSONSeq streams the values of seq.
+func NDJSONSeq[T any](seq iter.Seq[T]) *NDJSONResult {
+	return &NDJSONResult{
+		Items: func(yield func(any) bool) {
+			for item := range seq {
+				if !yield(item) {
+					return
+				}
+			}
+		},
+	}
+}
+
+func (h *apiHandler[T]) sendNDJSON(c *fiber.Ctx, result *NDJSONResult) error {
+	c.Set(fiber.HeaderContentType, MIMEApplicationNDJSON)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+
+	release := takeStream(c)
+	if c.Context().IsHead() {
+		release()
+		return nil
+	}
+
+	// The fiber.Ctx is released once the handler returns, only the user context is used by the writer
+	ctx, cancel := context.WithCancel(c.UserContext())
+	items := bufferChan(ctx, cancel, h.options.streamBuffer, seqChan(ctx, result.Items))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer release()
+		defer cancel()
+
+		encoder := json.NewEncoder(w)
+		pump(items, h.options.heartbeat, func(item any) error {
+			if err, ok := item.(error); ok {
+				slog.Error("Failed to produce items", h.redactor().errorAttr(err))
+				_ = encoder.Encode(map[string]string{"error": err.Error()})
+				_ = w.Flush()
+				return err
+			}
+
+			// Encode terminates every value with a newline
+			if err := encoder.Encode(item); err != nil {
+				slog.Error("Failed to encode item", h.redactor().errorAttr(err))
+				return err
+			}
+			return w.Flush()
+		}, func() error {
+			if err := w.WriteByte('\n'); err != nil {
+				return err
+			}
+			return w.Flush()
+		}, func() {
+			// The producer may be blocked on the doFunc context, not on the items being read
+			cancel()
+			release()
+		})
+	})
+	return nil
+}
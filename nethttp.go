@@ -0,0 +1,139 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+type httpContextKey struct{}
+
+// HTTPConfig configures NewHTTPHandler.
+type HTTPConfig struct {
+	// Pattern is the Fiber route of the handler for its path parameters, e.g. "/users/:id", default
+	// any path.
+	Pattern string
+
+	// BodyLimit is the maximum size of a request body, default the 4MB of Fiber. Larger bodies are
+	// answered with 413.
+	BodyLimit int
+}
+
+// httpAdapter serves net/http requests through the routes of a Fiber app.
+type httpAdapter struct {
+	serve     fasthttp.RequestHandler
+	bodyLimit int64
+}
+
+// HTTPHandler adapts a fiber handler calling Do, DoMultipart or Invoke to net/http, so routes served by
+// chi or http.ServeMux share the request contract of the Fiber routes: binding, validation, claims,
+// envelope and errors. pattern is the Fiber route of the handler for its path parameters, e.g.
+// "/users/:id", default any path. See NewHTTPHandler.
+//
+//	router := chi.NewRouter()
+//	router.Method(http.MethodGet, "/users/{id}", fiberhandler.HTTPHandler(fiberhandler.Handle(handle, users.Get), "/users/:id"))
+func HTTPHandler(handler fiber.Handler, pattern ...string) http.Handler {
+	config := HTTPConfig{}
+	if len(pattern) > 0 {
+		config.Pattern = pattern[0]
+	}
+	return NewHTTPHandler(handler, config)
+}
+
+// NewHTTPHandler adapts a fiber handler to net/http with config. It is an adapter, not a transport of
+// its own: every request is copied into a fasthttp request and served by a Fiber app private to the
+// handler, so the fiber handler runs unchanged. The doFunc context derives from the context of the
+// http.Request.
+//
+// The adapter has the limits of that copy. The request body is read before the handler runs, up to
+// config.BodyLimit. Buffered responses are written once the handler returned. Stream results, NDJSON and
+// Server-Sent Events are written as they are produced and flushed after every write, but a client going
+// away is only noticed through the context of the http.Request, there is no connection to watch.
+func NewHTTPHandler(handler fiber.Handler, config HTTPConfig) http.Handler {
+	if config.Pattern == "" {
+		config.Pattern = "/*"
+	}
+	if config.BodyLimit <= 0 {
+		config.BodyLimit = fiber.DefaultBodyLimit
+	}
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true, BodyLimit: config.BodyLimit})
+	app.All(config.Pattern, func(c *fiber.Ctx) error {
+		if ctx, ok := c.Locals(httpContextKey{}).(context.Context); ok {
+			c.SetUserContext(ctx)
+		}
+		return handler(c)
+	})
+	return &httpAdapter{serve: app.Handler(), bodyLimit: int64(config.BodyLimit)}
+}
+
+// ServeHTTP implements http.Handler.
+func (a *httpAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL.RequestURI())
+	req.SetHost(r.Host)
+	for key, values := range r.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if r.Body != nil {
+		n, err := io.Copy(req.BodyWriter(), http.MaxBytesReader(w, r.Body, a.bodyLimit))
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		req.Header.SetContentLength(int(n))
+	}
+
+	var remoteAddr net.Addr
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		remoteAddr = addr
+	}
+
+	var fctx fasthttp.RequestCtx
+	fctx.Init(req, remoteAddr, nil)
+	fctx.SetUserValue(httpContextKey{}, r.Context())
+	a.serve(&fctx)
+
+	fctx.Response.Header.VisitAll(func(key, value []byte) {
+		w.Header().Add(string(key), string(value))
+	})
+	w.WriteHeader(fctx.Response.StatusCode())
+
+	if fctx.Response.IsBodyStream() {
+		// Streams are flushed as they are written, like fasthttp does for its own connections
+		if flusher, ok := w.(http.Flusher); ok {
+			_ = fctx.Response.BodyWriteTo(flushWriter{w: w, flusher: flusher})
+			return
+		}
+	}
+	_ = fctx.Response.BodyWriteTo(w)
+}
+
+// flushWriter flushes every write of a streamed response to the client.
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		f.flusher.Flush()
+	}
+	return n, err
+}
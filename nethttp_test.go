@@ -0,0 +1,107 @@
+package fiberhandler_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+)
+
+type getUser struct {
+	ID string `params:"id" validate:"required"`
+}
+
+func TestHTTPHandler(t *testing.T) {
+	handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{})
+	handler := fiberhandler.NewHTTPHandler(func(c *fiber.Ctx) error {
+		request := getUser{ID: c.Params("id")}
+		return handle.Do(c, &request, true, func(ctx context.Context) (any, error) {
+			return profile{Sub: request.ID}, nil
+		})
+	}, fiberhandler.HTTPConfig{Pattern: "/users/:id", BodyLimit: 16})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+		data   string
+	}{
+		{name: "path parameter", method: http.MethodGet, target: "/users/42", want: http.StatusOK, data: `"sub":"42"`},
+		{name: "body within limit", method: http.MethodPost, target: "/users/42", body: `{}`, want: http.StatusOK, data: `"sub":"42"`},
+		{name: "body over limit", method: http.MethodPost, target: "/users/42", body: strings.Repeat("x", 17), want: http.StatusRequestEntityTooLarge},
+		{name: "unknown route", method: http.MethodGet, target: "/orders/42", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", recorder.Code, tt.want, recorder.Body)
+			}
+			if !bytes.Contains(recorder.Body.Bytes(), []byte(tt.data)) {
+				t.Errorf("body = %s, want %s", recorder.Body, tt.data)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerStream(t *testing.T) {
+	handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{})
+	produced := make(chan struct{})
+	server := httptest.NewServer(fiberhandler.HTTPHandler(func(c *fiber.Ctx) error {
+		return handle.DoSSE(c, &struct{}{}, false, func(ctx context.Context, events chan<- fiberhandler.Event) error {
+			defer close(produced)
+			select {
+			case events <- fiberhandler.Event{Data: "first"}:
+			case <-ctx.Done():
+				return nil
+			}
+			// The first event must reach the client while the stream is still open
+			<-ctx.Done()
+			return nil
+		})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "data: first\n" {
+		t.Errorf("line = %q, want %q", line, "data: first\n")
+	}
+
+	// Closing the stream cancels the producer
+	cancel()
+	_, _ = io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+	select {
+	case <-produced:
+	case <-time.After(5 * time.Second):
+		t.Error("producer still running after the client left")
+	}
+}
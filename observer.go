@@ -0,0 +1,68 @@
+package fiberhandler
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Outcome describes a handled request for an Observer.
+type Outcome struct {
+	Method   string
+	Route    string
+	Status   int
+	Duration time.Duration
+
+	// Err is the error sent to the client, nil on success.
+	Err error
+
+	// Envelope is the envelope of the JSON body with WithEnvelope, empty otherwise.
+	Envelope EnvelopeVersion
+}
+
+// Observer receives the outcome of every handled request, e.g. to export metrics. The fiberprom module
+// implements it with Prometheus collectors.
+type Observer interface {
+	Observe(outcome Outcome)
+}
+
+// ObserverFunc adapts a function to Observer.
+type ObserverFunc func(outcome Outcome)
+
+// Observe implements Observer.
+func (f ObserverFunc) Observe(outcome Outcome) {
+	f(outcome)
+}
+
+type sentErrorKey struct{}
+
+// WithObserver reports the outcome of every request to observer.
+func WithObserver(observer Observer) Option {
+	return func(o *options) {
+		o.observers = append(slices.Clip(o.observers), observer)
+	}
+}
+
+// observe starts timing the request and returns the function reporting its outcome.
+func (h *apiHandler[T]) observe(c *fiber.Ctx) func() {
+	if len(h.options.observers) == 0 {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		outcome := Outcome{
+			Method:   c.Method(),
+			Route:    c.Route().Path,
+			Status:   c.Response().StatusCode(),
+			Duration: time.Since(start),
+		}
+		outcome.Err, _ = c.Locals(sentErrorKey{}).(error)
+		outcome.Envelope, _ = c.Locals(envelopeKey{}).(EnvelopeVersion)
+
+		for _, observer := range h.options.observers {
+			observer.Observe(outcome)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package fiberhandler
+
+import (
+	"context"
+	"mime/multipart"
+	"reflect"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Option configures the behavior of an ApiHandler.
+type Option func(*options)
+
+type options struct {
+	multipartJSONPart  string
+	watermark          *watermark
+	rawResponse        bool
+	csp                *CSPConfig
+	multipartMemory    int64
+	pageLimits         pageLimits
+	uploadProgress     func(progress UploadProgress)
+	fileCommit         func(ctx context.Context, fieldName string, file *multipart.FileHeader) error
+	pageLinks          bool
+	authorizer         func(claims any, route *Route) bool
+	errorFormat        ErrorFormat
+	streamBuffer       *StreamBufferConfig
+	heartbeat          time.Duration
+	streamCompression  *StreamCompressionConfig
+	responseCache      *responseCacheOption
+	idempotency        *idempotency
+	events             *eventPublisher
+	allocationBudget   *AllocationBudget
+	tracer             Tracer
+	panicClassifiers   []PanicClassifier
+	observers          []Observer
+	formFieldLimits    *FormFieldLimits
+	providers          map[reflect.Type]provider
+	redactor           *Redactor
+	auditor            *auditor
+	references         map[string]ReferenceSource
+	envelope           *EnvelopeConfig
+	timeout            time.Duration
+	disconnectInterval time.Duration
+	circuitBreaker     CircuitBreaker
+	htmlErrors         *HTMLErrorConfig
+	retry              func(doFunc DoFunc) DoFunc
+	rateLimiter        *rateLimiter
+	interceptors       []Interceptor
+	validationStatus   int
+	validationCode     string
+	batchLimit         int
+	jobQueue           *JobQueue
+	cookieAuth         *CookieAuthConfig
+	uploadHooks        []UploadHook
+	storageSink        *storageSink
+	tenantResolver     func(c *fiber.Ctx, claims any) (string, error)
+	locales            *localeMatcher
+	requireAuth        bool
+	tokenLookup        *TokenLookup
+	revocationChecker  RevocationChecker
+	fieldACL           *fieldACL
+	strictJSON         bool
+	jsonCodec          JSONCodec
+	sanitization       *sanitization
+	sparseFieldsets    *SparseFieldsetConfig
+	bandwidth          *bandwidth
+	lazyClaims         bool
+}
+
+// WithMultipartJSONPart decodes the named multipart part as JSON into the request
+// struct before the file fields are bound, e.g. a "metadata" part sent next to files.
+func WithMultipartJSONPart(name string) Option {
+	return func(o *options) {
+		o.multipartJSONPart = name
+	}
+}
+
+// WithRawResponse sends successful results as is, without the success envelope.
+func WithRawResponse() Option {
+	return func(o *options) {
+		o.rawResponse = true
+	}
+}
@@ -0,0 +1,194 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Pageable parses page, limit and sort from the query, embed it in a request struct:
+//
+//	type ListUsersRequest struct {
+//		fiberhandler.Pageable
+//		core.RequestInfo[Claims] `json:"-"`
+//	}
+//
+// Do fills in the defaults and rejects out of bounds values before validation.
+type Pageable struct {
+	Page  int    `json:"page" query:"page" form:"page"`
+	Limit int    `json:"limit" query:"limit" form:"limit"`
+	Sort  string `json:"sort" query:"sort" form:"sort"`
+}
+
+// SortField is one entry of a comma separated sort, a leading "-" sorts descending.
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+type pageLimits struct {
+	defaultLimit int
+	maxLimit     int
+}
+
+type pageRequest interface {
+	normalizePage(limits pageLimits) error
+}
+
+// WithPageLimits overrides the default and maximum page size, DefaultPageLimit and MaxPageLimit by default.
+func WithPageLimits(defaultLimit, maxLimit int) Option {
+	return func(o *options) {
+		o.pageLimits = pageLimits{defaultLimit: defaultLimit, maxLimit: maxLimit}
+	}
+}
+
+// Offset returns the number of items to skip.
+func (p *Pageable) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// SortFields returns the parsed sort, e.g. "name,-createdAt".
+func (p *Pageable) SortFields() []SortField {
+	return parseSortFields(p.Sort)
+}
+
+func parseSortFields(sort string) []SortField {
+	var fields []SortField
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimLeft(field, "+-")
+		if field == "" {
+			continue
+		}
+		fields = append(fields, SortField{Field: field, Desc: desc})
+	}
+	return fields
+}
+
+func (p *Pageable) normalizePage(limits pageLimits) error {
+	if limits.defaultLimit <= 0 {
+		limits.defaultLimit = DefaultPageLimit
+	}
+	if limits.maxLimit <= 0 {
+		limits.maxLimit = MaxPageLimit
+	}
+
+	if p.Page == 0 {
+		p.Page = 1
+	}
+	if p.Limit == 0 {
+		p.Limit = limits.defaultLimit
+	}
+
+	if p.Page < 1 {
+		return NewBadRequestError("Invalid value for field 'page': must be greater than 0")
+	}
+	if p.Limit < 1 || p.Limit > limits.maxLimit {
+		return NewBadRequestError(fmt.Sprintf("Invalid value for field 'limit': must be between 1 and %d", limits.maxLimit))
+	}
+	return nil
+}
+
+// Paged is a page of items with the metadata clients need to page through the list.
+type Paged[T any] struct {
+	Items      []T   `json:"items"`
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+}
+
+// NewPaged returns the items of the page requested by pageable out of total items.
+func NewPaged[T any](items []T, total int64, pageable Pageable) *Paged[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	totalPages := 0
+	if pageable.Limit > 0 {
+		totalPages = int((total + int64(pageable.Limit) - 1) / int64(pageable.Limit))
+	}
+
+	return &Paged[T]{
+		Items:      items,
+		Page:       pageable.Page,
+		Limit:      pageable.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// WithPageLinks emits RFC 5988 Link headers (first, prev, next, last) for Paged and CursorPage results,
+// built from the current request URL.
+func WithPageLinks() Option {
+	return func(o *options) {
+		o.pageLinks = true
+	}
+}
+
+type linkedPage interface {
+	links(link func(params ...string) string) []string
+}
+
+func (p *Paged[T]) links(link func(params ...string) string) []string {
+	page := func(n int) string {
+		return link("page", strconv.Itoa(n), "limit", strconv.Itoa(p.Limit))
+	}
+
+	lastPage := max(p.TotalPages, 1)
+	links := []string{formatLink(page(1), "first")}
+	if p.Page > 1 {
+		links = append(links, formatLink(page(min(p.Page-1, lastPage)), "prev"))
+	}
+	if p.Page < p.TotalPages {
+		links = append(links, formatLink(page(p.Page+1), "next"))
+	}
+	return append(links, formatLink(page(lastPage), "last"))
+}
+
+func (p *CursorPage[T]) links(link func(params ...string) string) []string {
+	if p.NextCursor == "" {
+		return nil
+	}
+	return []string{formatLink(link("cursor", p.NextCursor, "limit", strconv.Itoa(p.Limit)), "next")}
+}
+
+func formatLink(target, rel string) string {
+	return fmt.Sprintf(`<%s>; rel="%s"`, target, rel)
+}
+
+func setPageLinks(c *fiber.Ctx, data any) {
+	if result, ok := data.(*Result); ok {
+		data = result.Data
+	}
+	page, ok := data.(linkedPage)
+	if !ok {
+		return
+	}
+
+	base := c.BaseURL() + c.Path()
+	query := c.Request().URI().QueryArgs()
+	links := page.links(func(params ...string) string {
+		args := fasthttp.AcquireArgs()
+		defer fasthttp.ReleaseArgs(args)
+
+		query.CopyTo(args)
+		for i := 0; i+1 < len(params); i += 2 {
+			args.Set(params[i], params[i+1])
+		}
+		return base + "?" + args.String()
+	})
+
+	if len(links) > 0 {
+		c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+}
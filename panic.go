@@ -0,0 +1,26 @@
+package fiberhandler
+
+import "slices"
+
+// PanicClassifier converts a known panic value recovered from doFunc into the error sent to the client,
+// e.g. a driver panic into a 503 or a nil map write of legacy code into a 400. ok is false for values
+// the classifier does not know.
+type PanicClassifier func(value any) (err error, ok bool)
+
+// WithPanicClassifiers sends the error of the first classifier knowing the value of a recovered doFunc
+// panic. Unknown panic values are sent as an InternalError.
+func WithPanicClassifiers(classifiers ...PanicClassifier) Option {
+	return func(o *options) {
+		o.panicClassifiers = append(slices.Clip(o.panicClassifiers), classifiers...)
+	}
+}
+
+// classifyPanic returns the error of the first classifier knowing value.
+func (o *options) classifyPanic(value any) (error, bool) {
+	for _, classify := range o.panicClassifiers {
+		if err, ok := classify(value); ok {
+			return err, true
+		}
+	}
+	return nil, false
+}
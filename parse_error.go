@@ -0,0 +1,119 @@
+package fiberhandler
+
+import (
+	stdjson "encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseError returns the BadRequestError sent for a request body or query failing to decode. Its message
+// names the field with a wrong type or the offset of malformed JSON, so client developers can fix the
+// request themselves.
+func parseError(err error) error {
+	if detail := describeParseError(err); detail != "" {
+		return NewBadRequestError(detail)
+	}
+	return NewBadRequestError()
+}
+
+// describeParseError describes the decoding errors of encoding/json, goccy/go-json, encoding/xml and the
+// form and query parser of Fiber, or returns an empty string.
+func describeParseError(err error) string {
+	var (
+		stdTypeErr   *stdjson.UnmarshalTypeError
+		typeErr      *json.UnmarshalTypeError
+		stdSyntaxErr *stdjson.SyntaxError
+		syntaxErr    *json.SyntaxError
+		xmlSyntaxErr *xml.SyntaxError
+		multiErr     fiber.MultiError
+	)
+	switch {
+	case errors.As(err, &stdTypeErr):
+		return describeTypeError(stdTypeErr.Field, stdTypeErr.Type, stdTypeErr.Value)
+	case errors.As(err, &typeErr):
+		return describeTypeError(typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.As(err, &stdSyntaxErr):
+		return fmt.Sprintf("Malformed JSON at offset %d: %s", stdSyntaxErr.Offset, stdSyntaxErr.Error())
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("Malformed JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())
+	case errors.As(err, &xmlSyntaxErr):
+		return fmt.Sprintf("Malformed XML at line %d: %s", xmlSyntaxErr.Line, xmlSyntaxErr.Msg)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "Malformed JSON: unexpected end of input"
+	case errors.Is(err, fiber.ErrUnprocessableEntity):
+		return "Unsupported Content-Type"
+	case errors.As(err, &multiErr):
+		return describeFieldErrors(multiErr)
+	}
+	return ""
+}
+
+func describeTypeError(field string, t reflect.Type, value string) string {
+	if field == "" {
+		return fmt.Sprintf("Invalid request body: expected %s, got %s", jsonType(t), value)
+	}
+	return fmt.Sprintf("Invalid value for field '%s': expected %s, got %s", field, jsonType(t), value)
+}
+
+// describeFieldErrors describes the first field, in name order, the form or query parser rejected.
+func describeFieldErrors(multiErr fiber.MultiError) string {
+	keys := make([]string, 0, len(multiErr))
+	for key := range multiErr {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		var (
+			conversionErr fiber.ConversionError
+			emptyErr      fiber.EmptyFieldError
+			unknownErr    fiber.UnknownKeyError
+		)
+		switch err := multiErr[key]; {
+		case errors.As(err, &conversionErr):
+			field := conversionErr.Key
+			if conversionErr.Index >= 0 {
+				field = fmt.Sprintf("%s[%d]", field, conversionErr.Index)
+			}
+			return fmt.Sprintf("Invalid value for field '%s': expected %s", field, jsonType(conversionErr.Type))
+		case errors.As(err, &emptyErr):
+			return fmt.Sprintf("Missing value for field '%s'", emptyErr.Key)
+		case errors.As(err, &unknownErr):
+			return fmt.Sprintf("Unknown field '%s'", unknownErr.Key)
+		}
+	}
+	return ""
+}
+
+// jsonType names the JSON type decoded into t.
+func jsonType(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return strings.ToLower(t.Kind().String())
+}
@@ -0,0 +1,50 @@
+package fiberhandler
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxPooledBuffer is the largest token buffer returned to its pool, larger ones are left to the GC.
+const maxPooledBuffer = 4 << 10
+
+// tokenBuffers holds the buffers decoding the payload of JWTs.
+var tokenBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+func getTokenBuffer(size int) *[]byte {
+	buf := tokenBuffers.Get().(*[]byte)
+	if cap(*buf) < size {
+		*buf = make([]byte, size)
+	}
+	*buf = (*buf)[:size]
+	return buf
+}
+
+func putTokenBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+	tokenBuffers.Put(buf)
+}
+
+// tokenBodies holds the maps decoding the body of requests looked up for a custom token field.
+var tokenBodies = sync.Pool{New: func() any { return map[string]any{} }}
+
+// mimeSets caches the sets of allowed MIME types of DoMultipart by their list, the lists of a service
+// are a handful of literals.
+var mimeSets sync.Map
+
+// mimeSet returns the immutable set of allowedTypes.
+func mimeSet(allowedTypes []string) map[string]bool {
+	key := strings.Join(allowedTypes, "\x00")
+	if set, ok := mimeSets.Load(key); ok {
+		return set.(map[string]bool)
+	}
+
+	set := make(map[string]bool, len(allowedTypes))
+	for _, v := range allowedTypes {
+		set[v] = true
+	}
+	actual, _ := mimeSets.LoadOrStore(key, set)
+	return actual.(map[string]bool)
+}
@@ -0,0 +1,62 @@
+package fiberhandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/gopkg/streamx"
+)
+
+// sendRange serves a seekable stream honoring a single byte range, so downloads can be resumed and
+// seeked. Multiple ranges and a stale If-Range are answered with the whole stream.
+func (h *apiHandler[T]) sendRange(c *fiber.Ctx, streamData *StreamResult, seeker io.ReadSeeker) error {
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+
+	size, err := streamSize(streamData.Stream, seeker)
+	if err != nil {
+		return h.SendError(c, err)
+	}
+
+	if c.Get(fiber.HeaderRange) == "" || c.Method() != http.MethodGet || !rangeFresh(c, streamData) {
+		return c.SendStream(seeker, size)
+	}
+
+	ranges, err := c.Range(size)
+	if errors.Is(err, fiber.ErrRangeUnsatisfiable) {
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", size))
+		return c.SendStatus(http.StatusRequestedRangeNotSatisfiable)
+	}
+	if err != nil || ranges.Type != "bytes" || len(ranges.Ranges) != 1 {
+		return c.SendStream(seeker, size)
+	}
+
+	start, end := ranges.Ranges[0].Start, ranges.Ranges[0].End
+	if _, err := seeker.Seek(int64(start), io.SeekStart); err != nil {
+		return h.SendError(c, err)
+	}
+
+	length := end - start + 1
+	c.Set(fiber.HeaderContentRange, "bytes "+strconv.Itoa(start)+"-"+strconv.Itoa(end)+"/"+strconv.Itoa(size))
+	c.Status(http.StatusPartialContent)
+	return c.SendStream(io.LimitReader(seeker, int64(length)), length)
+}
+
+// streamSize returns the declared size of the stream, or measures it by seeking to the end.
+func streamSize(streamData *streamx.Stream, seeker io.ReadSeeker) (int, error) {
+	if streamData.Size != nil {
+		return *streamData.Size, nil
+	}
+
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return int(end), nil
+}
@@ -0,0 +1,72 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+	"github.com/prongbang/gopkg/streamx"
+)
+
+func TestRange(t *testing.T) {
+	const content = "0123456789"
+	const etag = `"v1"`
+
+	tests := []struct {
+		name         string
+		header       map[string]string
+		sized        bool
+		want         int
+		body         string
+		contentRange string
+	}{
+		{name: "whole file", want: http.StatusOK, body: content},
+		{name: "first bytes", header: map[string]string{"Range": "bytes=0-3"}, want: http.StatusPartialContent, body: "0123", contentRange: "bytes 0-3/10"},
+		{name: "middle", header: map[string]string{"Range": "bytes=4-6"}, want: http.StatusPartialContent, body: "456", contentRange: "bytes 4-6/10"},
+		{name: "open ended", header: map[string]string{"Range": "bytes=7-"}, want: http.StatusPartialContent, body: "789", contentRange: "bytes 7-9/10"},
+		{name: "suffix", header: map[string]string{"Range": "bytes=-2"}, want: http.StatusPartialContent, body: "89", contentRange: "bytes 8-9/10"},
+		{name: "end past size", header: map[string]string{"Range": "bytes=8-20"}, want: http.StatusPartialContent, body: "89", contentRange: "bytes 8-9/10"},
+		{name: "declared size", sized: true, header: map[string]string{"Range": "bytes=1-2"}, want: http.StatusPartialContent, body: "12", contentRange: "bytes 1-2/10"},
+		{name: "unsatisfiable", header: map[string]string{"Range": "bytes=10-"}, want: http.StatusRequestedRangeNotSatisfiable, contentRange: "bytes */10"},
+		{name: "multiple ranges", header: map[string]string{"Range": "bytes=0-1,4-5"}, want: http.StatusOK, body: content},
+		{name: "other unit", header: map[string]string{"Range": "items=0-1"}, want: http.StatusOK, body: content},
+		{name: "fresh If-Range", header: map[string]string{"Range": "bytes=0-1", "If-Range": etag}, want: http.StatusPartialContent, body: "01", contentRange: "bytes 0-1/10"},
+		{name: "stale If-Range", header: map[string]string{"Range": "bytes=0-1", "If-Range": `"v0"`}, want: http.StatusOK, body: content},
+		{name: "weak If-Range", header: map[string]string{"Range": "bytes=0-1", "If-Range": `W/"v1"`}, want: http.StatusOK, body: content},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{})
+			handler := func(c *fiber.Ctx) error {
+				return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+					var size []int
+					if tt.sized {
+						size = append(size, len(content))
+					}
+					stream := streamx.NewStream("digits.txt", "text/plain", strings.NewReader(content), size...)
+					return (&fiberhandler.StreamResult{Stream: stream}).WithETag(etag), nil
+				})
+			}
+
+			request := fiberhandlertest.Get("/digits.txt")
+			for key, value := range tt.header {
+				request.Header(key, value)
+			}
+			response := request.Run(t, handler).
+				Status(tt.want).
+				HasHeader(fiber.HeaderContentRange, tt.contentRange)
+			if tt.want == http.StatusRequestedRangeNotSatisfiable {
+				return
+			}
+			response.HasHeader(fiber.HeaderAcceptRanges, "bytes")
+			if string(response.Body) != tt.body {
+				t.Errorf("body = %q, want %q", response.Body, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package fiberhandler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderAPIKey             = "X-API-Key"
+	rateLimitPrefix          = "fiberhandler:ratelimit:"
+	defaultRateLimit         = 60
+	defaultRateLimitWindow   = time.Minute
+)
+
+// RateLimitConfig configures WithRateLimit.
+type RateLimitConfig struct {
+	// Limit is the number of requests a caller may send per Window, default 60 per minute.
+	Limit  int
+	Window time.Duration
+
+	// Store counts the requests, default an in-memory store. Share a Redis backed Store between
+	// instances to enforce the limit across them.
+	Store Store
+
+	// APIKeyHeader identifies callers without claims, default X-API-Key. Callers without claims nor an
+	// API key are limited by IP.
+	APIKeyHeader string
+
+	// ValidateAPIKey reports whether key is a known API key. Keys are only counted on their own when it
+	// accepts them, otherwise a caller could spread its requests over made up keys; without it API keys
+	// are ignored and the caller is limited by IP.
+	ValidateAPIKey func(ctx context.Context, key string) bool
+
+	// PerRoute counts the requests of every route separately, by default a caller shares its limit
+	// between the routes of the handler.
+	PerRoute bool
+
+	// TrustTokenClaims counts callers by the subject of the claims of the TokenParser, for parsers
+	// verifying the signature of the token. The default JWTParser only decodes it, anyone could pick
+	// their bucket with a made up subject, so by default only the claims of an Authenticator are.
+	TrustTokenClaims bool
+}
+
+type rateCountedKey struct{}
+
+type rateLimiter struct {
+	config  RateLimitConfig
+	subject func(claims any) string
+}
+
+// WithRateLimit limits the requests of every caller to config.Limit per window: by the subject returned
+// for its verified claims, by its API key accepted by config.ValidateAPIKey, or by IP otherwise. Claims
+// are verified when they come from an Authenticator, or from the TokenParser with
+// config.TrustTokenClaims. Requests are counted before they are parsed, so requests failing binding,
+// validation or authorization count too. Every response carries the RateLimit headers, requests over
+// the limit are answered with a TooManyRequestsError (429) and Retry-After.
+func WithRateLimit[T any](config RateLimitConfig, subject func(claims *T) string) Option {
+	if config.Limit <= 0 {
+		config.Limit = defaultRateLimit
+	}
+	if config.Window <= 0 {
+		config.Window = defaultRateLimitWindow
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.APIKeyHeader == "" {
+		config.APIKeyHeader = HeaderAPIKey
+	}
+
+	limiter := &rateLimiter{
+		config: config,
+		subject: func(claims any) string {
+			c, _ := claims.(*T)
+			if subject == nil || c == nil {
+				return ""
+			}
+			return subject(c)
+		},
+	}
+
+	return func(o *options) {
+		o.rateLimiter = limiter
+	}
+}
+
+// caller returns the key the requests of the caller are counted under.
+func (l *rateLimiter) caller(c *fiber.Ctx, claims any) string {
+	if subject := l.subject(claims); subject != "" {
+		return "sub:" + subject
+	}
+	if apiKey := c.Get(l.config.APIKeyHeader); apiKey != "" && l.config.ValidateAPIKey != nil &&
+		l.config.ValidateAPIKey(c.UserContext(), apiKey) {
+		// The key is a secret, the store only sees its hash
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+	return "ip:" + c.IP()
+}
+
+// limitRate counts the request once, before it is parsed. The caller is authenticated first when its
+// claims can be trusted, the result is kept for the pipeline.
+func (h *apiHandler[T]) limitRate(c *fiber.Ctx) error {
+	limiter := h.options.rateLimiter
+	if limiter == nil {
+		return nil
+	}
+	if counted, _ := c.Locals(rateCountedKey{}).(bool); counted {
+		return nil
+	}
+	c.Locals(rateCountedKey{}, true)
+
+	var claims *T
+	if h.Authenticator != nil || limiter.config.TrustTokenClaims {
+		// A rejected token is counted by IP, the pipeline answers it with 401
+		claims, _ = h.getUserRequestInfo(c)
+	}
+	return limiter.allow(c, claims)
+}
+
+// allow counts the request in the current window and sets the RateLimit headers.
+func (l *rateLimiter) allow(c *fiber.Ctx, claims any) error {
+	now := time.Now()
+	window := now.Truncate(l.config.Window)
+	reset := window.Add(l.config.Window).Sub(now)
+
+	key := rateLimitPrefix + l.caller(c, claims)
+	if l.config.PerRoute {
+		key += ":" + c.Method() + " " + c.Route().Path
+	}
+	key += ":" + strconv.FormatInt(window.Unix(), 10)
+
+	count, err := l.config.Store.Increment(c.UserContext(), key, 1, l.config.Window)
+	if err != nil {
+		// Fail open, the store being down must not take the API down
+		slog.Error("Failed to count rate limit", slog.String("error", err.Error()))
+		return nil
+	}
+
+	remaining := max(int64(l.config.Limit)-count, 0)
+	resetSeconds := strconv.Itoa(int(math.Ceil(reset.Seconds())))
+	c.Set(HeaderRateLimitLimit, strconv.Itoa(l.config.Limit))
+	c.Set(HeaderRateLimitRemaining, strconv.FormatInt(remaining, 10))
+	c.Set(HeaderRateLimitReset, resetSeconds)
+
+	if count > int64(l.config.Limit) {
+		return NewTooManyRequestsError(reset)
+	}
+	return nil
+}
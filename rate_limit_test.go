@@ -0,0 +1,139 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+type rateLimitedRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type rateLimitCall struct {
+	header  map[string]string
+	invalid bool
+}
+
+func bearer(t *testing.T, sub string) map[string]string {
+	t.Helper()
+	token, err := fiberhandlertest.SignToken(testClaims{Sub: sub}, fiberhandlertest.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func TestRateLimit(t *testing.T) {
+	subject := func(claims *testClaims) string { return claims.Sub }
+	validKey := func(ctx context.Context, key string) bool { return key == "valid" }
+
+	tests := []struct {
+		name          string
+		config        fiberhandler.RateLimitConfig
+		authenticator fiberhandler.Authenticator[testClaims]
+		calls         []rateLimitCall
+		want          []int
+	}{
+		{
+			name:  "by ip",
+			calls: []rateLimitCall{{}, {}, {}},
+			want:  []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:  "invalid requests count",
+			calls: []rateLimitCall{{invalid: true}, {invalid: true}, {}},
+			want:  []int{http.StatusBadRequest, http.StatusBadRequest, http.StatusTooManyRequests},
+		},
+		{
+			name:  "unverified subjects share the ip",
+			calls: []rateLimitCall{{header: bearer(t, "a")}, {header: bearer(t, "b")}, {header: bearer(t, "c")}},
+			want:  []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:   "trusted token subjects",
+			config: fiberhandler.RateLimitConfig{TrustTokenClaims: true},
+			calls:  []rateLimitCall{{header: bearer(t, "a")}, {header: bearer(t, "a")}, {header: bearer(t, "b")}, {header: bearer(t, "a")}},
+			want:   []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:          "authenticated subjects",
+			authenticator: headerAuthenticator,
+			calls:         []rateLimitCall{{header: map[string]string{"X-User": "ada"}}, {header: map[string]string{"X-User": "ada"}}, {header: map[string]string{"X-User": "bob"}}, {}, {}},
+			want:          []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:   "validated api keys",
+			config: fiberhandler.RateLimitConfig{ValidateAPIKey: validKey},
+			calls:  []rateLimitCall{{header: map[string]string{"X-API-Key": "valid"}}, {header: map[string]string{"X-API-Key": "valid"}}, {}, {}, {header: map[string]string{"X-API-Key": "valid"}}},
+			want:   []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:   "made up api keys share the ip",
+			config: fiberhandler.RateLimitConfig{ValidateAPIKey: validKey},
+			calls:  []rateLimitCall{{header: map[string]string{"X-API-Key": "x"}}, {header: map[string]string{"X-API-Key": "y"}}, {header: map[string]string{"X-API-Key": "z"}}},
+			want:   []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Limit = 2
+			handle := fiberhandler.NewWithComponents(
+				fiberhandler.Components[testClaims]{Authenticator: tt.authenticator},
+				fiberhandler.WithRateLimit(config, subject),
+			)
+			handler := func(c *fiber.Ctx) error {
+				return handle.Do(c, &rateLimitedRequest{}, true, func(ctx context.Context) (any, error) {
+					return nil, nil
+				})
+			}
+
+			for i, call := range tt.calls {
+				body := rateLimitedRequest{Name: "Ada"}
+				if call.invalid {
+					body.Name = ""
+				}
+				request := fiberhandlertest.Post("/orders").JSON(body)
+				for key, value := range call.header {
+					request.Header(key, value)
+				}
+				response := request.Run(t, handler)
+				if response.StatusCode != tt.want[i] {
+					t.Errorf("request %d: status = %d, want %d", i, response.StatusCode, tt.want[i])
+				}
+				if response.Header.Get(fiberhandler.HeaderRateLimitLimit) != strconv.Itoa(config.Limit) {
+					t.Errorf("request %d: missing %s header", i, fiberhandler.HeaderRateLimitLimit)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	handle := fiberhandler.NewWithComponents(
+		fiberhandler.Components[testClaims]{},
+		fiberhandler.WithRateLimit[testClaims](fiberhandler.RateLimitConfig{Limit: 1}, nil),
+	)
+	handler := func(c *fiber.Ctx) error {
+		return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			return nil, nil
+		})
+	}
+
+	fiberhandlertest.Get("/orders").Run(t, handler).
+		Status(http.StatusOK).
+		HasHeader(fiberhandler.HeaderRateLimitRemaining, "0")
+	response := fiberhandlertest.Get("/orders").Run(t, handler).
+		Status(http.StatusTooManyRequests).
+		HasHeader(fiberhandler.HeaderRateLimitRemaining, "0")
+	if response.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Error("missing Retry-After")
+	}
+}
@@ -0,0 +1,247 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	// TagLog is the struct tag controlling how a field is logged: "redact" masks the value, "-" omits it.
+	TagLog = "log"
+
+	defaultRedactionMask = "[REDACTED]"
+)
+
+// DefaultRedactedKeys are the field, header and parameter names whose values are masked by default.
+var DefaultRedactedKeys = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token", "id_token",
+	"authorization", "api_key", "apikey", "cookie", "client_secret", "otp", "pin",
+}
+
+// Redactor masks secrets before request and error details are logged.
+type Redactor struct {
+	// Keys are the names whose values are masked, in struct fields, maps and key=value or "key":"value"
+	// pairs of logged strings. Matched case-insensitively, default DefaultRedactedKeys.
+	Keys []string
+
+	// Patterns match secrets anywhere in logged strings, default bearer tokens and JWTs.
+	Patterns []*regexp.Regexp
+
+	// Mask replaces the redacted values, default "[REDACTED]".
+	Mask string
+
+	keys  map[string]struct{}
+	pairs *regexp.Regexp
+}
+
+var (
+	defaultRedactionPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9\-._~+/]+=*`),
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	}
+
+	defaultRedactor = NewRedactor(Redactor{})
+)
+
+// NewRedactor returns config with the defaults applied.
+func NewRedactor(config Redactor) *Redactor {
+	r := &config
+	if r.Keys == nil {
+		r.Keys = DefaultRedactedKeys
+	}
+	if r.Patterns == nil {
+		r.Patterns = defaultRedactionPatterns
+	}
+	if r.Mask == "" {
+		r.Mask = defaultRedactionMask
+	}
+
+	r.keys = make(map[string]struct{}, len(r.Keys))
+	quoted := make([]string, 0, len(r.Keys))
+	for _, key := range r.Keys {
+		r.keys[strings.ToLower(key)] = struct{}{}
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+	if len(quoted) > 0 {
+		r.pairs = regexp.MustCompile(`(?i)("?\b(?:` + strings.Join(quoted, "|") + `)"?\s*[:=]\s*)("[^"]*"|[^\s,&;"}]+)`)
+	}
+	return r
+}
+
+// WithLogRedaction replaces the default redaction of the details the handler logs, e.g. to mask
+// additional keys. Handlers redact with the defaults of NewRedactor otherwise.
+func WithLogRedaction(config Redactor) Option {
+	redactor := NewRedactor(config)
+	return func(o *options) {
+		o.redactor = redactor
+	}
+}
+
+// Redacted returns v as a slog value with secrets masked by the default redactor, for logging requests
+// in doFunc:
+//
+//	slog.Info("Create post", slog.Any("request", fiberhandler.Redacted(req)))
+func Redacted(v any) slog.LogValuer {
+	return defaultRedactor.LogValuer(v)
+}
+
+// LogValuer returns v as a slog value with secrets masked.
+func (r *Redactor) LogValuer(v any) slog.LogValuer {
+	return redactedValue{redactor: r, value: v}
+}
+
+type redactedValue struct {
+	redactor *Redactor
+	value    any
+}
+
+// LogValue implements slog.LogValuer.
+func (v redactedValue) LogValue() slog.Value {
+	return v.redactor.Value(v.value)
+}
+
+// String masks the secrets in s.
+func (r *Redactor) String(s string) string {
+	for _, pattern := range r.Patterns {
+		s = pattern.ReplaceAllString(s, r.Mask)
+	}
+	if r.pairs != nil {
+		s = r.pairs.ReplaceAllString(s, "${1}"+r.Mask)
+	}
+	return s
+}
+
+// Value returns v as a slog value: struct fields tagged log:"redact" and fields and map entries named by
+// Keys are masked, fields tagged log:"-" are omitted and strings are masked by String.
+func (r *Redactor) Value(v any) slog.Value {
+	return r.value(reflect.ValueOf(v), 0)
+}
+
+// maxRedactionDepth bounds the walk of cyclic or deeply nested values.
+const maxRedactionDepth = 8
+
+func (r *Redactor) value(v reflect.Value, depth int) slog.Value {
+	if !v.IsValid() {
+		return slog.AnyValue(nil)
+	}
+	if depth > maxRedactionDepth {
+		return slog.StringValue("...")
+	}
+
+	if v.CanInterface() {
+		switch value := v.Interface().(type) {
+		case error:
+			if v.Kind() != reflect.Pointer || !v.IsNil() {
+				return slog.StringValue(r.String(value.Error()))
+			}
+		case fmt.Stringer:
+			// Structs with exported fields are walked, others such as time.Time are logged as strings
+			if !hasExportedFields(v.Type()) && (v.Kind() != reflect.Pointer || !v.IsNil()) {
+				return slog.StringValue(r.String(value.String()))
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		return r.value(v.Elem(), depth+1)
+	case reflect.String:
+		return slog.StringValue(r.String(v.String()))
+	case reflect.Struct:
+		attrs := make([]slog.Attr, 0, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag := field.Tag.Get(TagLog)
+			if tag == "-" {
+				continue
+			}
+			name := fieldName(field)
+			if tag == "redact" || r.sensitive(name) || r.sensitive(field.Name) {
+				attrs = append(attrs, slog.String(name, r.Mask))
+				continue
+			}
+			attrs = append(attrs, slog.Attr{Key: name, Value: r.value(v.Field(i), depth+1)})
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return slog.AnyValue(v.Interface())
+		}
+		attrs := make([]slog.Attr, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if r.sensitive(key) {
+				attrs = append(attrs, slog.String(key, r.Mask))
+				continue
+			}
+			attrs = append(attrs, slog.Attr{Key: key, Value: r.value(iter.Value(), depth+1)})
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return slog.AnyValue(v.Interface())
+		}
+		items := make([]any, v.Len())
+		for i := range v.Len() {
+			items[i] = r.value(v.Index(i), depth+1).Resolve().Any()
+		}
+		return slog.AnyValue(items)
+	}
+
+	if v.CanInterface() {
+		return slog.AnyValue(v.Interface())
+	}
+	return slog.StringValue(v.String())
+}
+
+func (r *Redactor) sensitive(name string) bool {
+	_, ok := r.keys[strings.ToLower(name)]
+	return ok
+}
+
+// errorAttr returns err as the error attribute of a log record with secrets masked.
+func (r *Redactor) errorAttr(err error) slog.Attr {
+	return slog.String("error", r.String(err.Error()))
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName returns the JSON name of a struct field.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// redactor returns the redactor of the details the handler logs.
+func (h *apiHandler[T]) redactor() *Redactor {
+	if h.options.redactor != nil {
+		return h.options.redactor
+	}
+	return defaultRedactor
+}
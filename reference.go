@@ -0,0 +1,196 @@
+package fiberhandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReferenceConfig configures a ReferenceCache.
+type ReferenceConfig struct {
+	// TTL is how long a loaded value is served before it is reloaded in the background, the stale value
+	// is served until the reload succeeded. Default never, the value changes on Invalidate only.
+	TTL time.Duration
+}
+
+// ReferenceSource is a reference data set registered with WithReferenceData, see ReferenceCache.
+type ReferenceSource interface {
+	Name() string
+
+	current(ctx context.Context) (any, error)
+}
+
+// ReferenceCache holds static reference data such as countries, currencies or feature matrices in
+// memory, loaded once and shared by all requests. Invalidate starts a new generation: the next lookup
+// reloads the data while requests in flight keep the value they already read.
+type ReferenceCache[V any] struct {
+	name   string
+	load   func(ctx context.Context) (V, error)
+	config ReferenceConfig
+
+	generation atomic.Uint64
+	snapshot   atomic.Pointer[referenceSnapshot[V]]
+	loading    sync.Mutex
+	refreshing atomic.Bool
+}
+
+type referenceSnapshot[V any] struct {
+	value      V
+	generation uint64
+	loadedAt   time.Time
+}
+
+// NewReferenceCache returns a cache named name of the data returned by load. Nothing is loaded before
+// the first lookup, call Refresh to warm the cache at startup.
+func NewReferenceCache[V any](name string, load func(ctx context.Context) (V, error), config ...ReferenceConfig) *ReferenceCache[V] {
+	cache := &ReferenceCache[V]{name: name, load: load}
+	if len(config) > 0 {
+		cache.config = config[0]
+	}
+	return cache
+}
+
+// Name returns the name the data is looked up by in the doFunc context.
+func (r *ReferenceCache[V]) Name() string {
+	return r.name
+}
+
+// Generation returns the current generation, incremented by every Invalidate.
+func (r *ReferenceCache[V]) Generation() uint64 {
+	return r.generation.Load()
+}
+
+// Invalidate drops the loaded value, the next lookup reloads it.
+func (r *ReferenceCache[V]) Invalidate() {
+	r.generation.Add(1)
+}
+
+// Get returns the value of the current generation, loading it when needed.
+func (r *ReferenceCache[V]) Get(ctx context.Context) (V, error) {
+	snapshot := r.snapshot.Load()
+	if snapshot == nil || snapshot.generation != r.generation.Load() {
+		return r.reload(ctx)
+	}
+
+	if r.config.TTL > 0 && time.Since(snapshot.loadedAt) > r.config.TTL && r.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer r.refreshing.Store(false)
+			if err := r.Refresh(context.WithoutCancel(ctx)); err != nil {
+				slog.Error("Failed to refresh reference data", slog.String("name", r.name), slog.String("error", err.Error()))
+			}
+		}()
+	}
+	return snapshot.value, nil
+}
+
+// Refresh loads the value now, e.g. to warm the cache at startup. The previous value is kept when
+// loading fails.
+func (r *ReferenceCache[V]) Refresh(ctx context.Context) error {
+	r.loading.Lock()
+	defer r.loading.Unlock()
+
+	_, err := r.store(ctx)
+	return err
+}
+
+// reload loads the value once for all the lookups waiting on it.
+func (r *ReferenceCache[V]) reload(ctx context.Context) (V, error) {
+	r.loading.Lock()
+	defer r.loading.Unlock()
+
+	if snapshot := r.snapshot.Load(); snapshot != nil && snapshot.generation == r.generation.Load() {
+		return snapshot.value, nil
+	}
+	return r.store(ctx)
+}
+
+func (r *ReferenceCache[V]) store(ctx context.Context) (V, error) {
+	generation := r.generation.Load()
+	value, err := r.load(ctx)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	r.snapshot.Store(&referenceSnapshot[V]{value: value, generation: generation, loadedAt: time.Now()})
+	return value, nil
+}
+
+func (r *ReferenceCache[V]) current(ctx context.Context) (any, error) {
+	return r.Get(ctx)
+}
+
+// WithReferenceData exposes sources to doFunc, read them with ReferenceFromContext. A request reads each
+// source at most once, so its lookups stay consistent while the source is refreshed.
+func WithReferenceData(sources ...ReferenceSource) Option {
+	return func(o *options) {
+		references := maps.Clone(o.references)
+		if references == nil {
+			references = map[string]ReferenceSource{}
+		}
+		for _, source := range sources {
+			references[source.Name()] = source
+		}
+		o.references = references
+	}
+}
+
+// ReferenceFromContext returns the reference data registered under name with WithReferenceData.
+func ReferenceFromContext[V any](ctx context.Context, name string) (V, error) {
+	var zero V
+
+	scope, ok := ctx.Value(referenceKey{}).(*referenceScope)
+	if !ok {
+		return zero, fmt.Errorf("reference data %q: the handler has no reference data", name)
+	}
+	value, err := scope.get(ctx, name)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := value.(V)
+	if !ok {
+		return zero, fmt.Errorf("reference data %q is %T, not %T", name, value, zero)
+	}
+	return typed, nil
+}
+
+// referenceScope pins the reference data read by a request.
+type referenceScope struct {
+	mu      sync.Mutex
+	sources map[string]ReferenceSource
+	pinned  map[string]any
+}
+
+func (s *referenceScope) get(ctx context.Context, name string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value, ok := s.pinned[name]; ok {
+		return value, nil
+	}
+	source, ok := s.sources[name]
+	if !ok {
+		return nil, fmt.Errorf("reference data %q is not registered", name)
+	}
+	value, err := source.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.pinned[name] = value
+	return value, nil
+}
+
+// bindReferences puts the reference data of the handler into the user context.
+func (h *apiHandler[T]) bindReferences(c *fiber.Ctx) {
+	if len(h.options.references) == 0 {
+		return
+	}
+	scope := &referenceScope{sources: h.options.references, pinned: map[string]any{}}
+	c.SetUserContext(context.WithValue(c.UserContext(), referenceKey{}, scope))
+}
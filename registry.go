@@ -0,0 +1,308 @@
+package fiberhandler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Route describes a route registered through a Registry.
+type Route struct {
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	Name     string   `json:"name,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Policies []string `json:"policies,omitempty"`
+
+	// Auth overrides whether the handler tolerates anonymous requests on the route.
+	Auth AuthMode `json:"auth,omitempty"`
+
+	// SampleRate overrides the global tracing sample rate for the route, nil keeps the global sampler.
+	SampleRate *float64 `json:"sample_rate,omitempty"`
+
+	// Event declares the entity changed by the route for WithEventPublisher.
+	Event *RouteEvent `json:"event,omitempty"`
+}
+
+// RouteOption declares metadata of a route registered through a Registry.
+type RouteOption func(*Route)
+
+type routeKey struct{}
+
+// Name names the route.
+func Name(name string) RouteOption {
+	return func(r *Route) {
+		r.Name = name
+	}
+}
+
+// Scopes requires the caller to hold all of scopes.
+func Scopes(scopes ...string) RouteOption {
+	return func(r *Route) {
+		r.Scopes = append(r.Scopes, scopes...)
+	}
+}
+
+// Roles requires the caller to hold one of roles.
+func Roles(roles ...string) RouteOption {
+	return func(r *Route) {
+		r.Roles = append(r.Roles, roles...)
+	}
+}
+
+// Policies requires the caller to satisfy all of the named policies.
+func Policies(policies ...string) RouteOption {
+	return func(r *Route) {
+		r.Policies = append(r.Policies, policies...)
+	}
+}
+
+// AuthMode tells whether a route tolerates anonymous requests.
+type AuthMode string
+
+const (
+	// AuthRequired rejects anonymous requests with 401.
+	AuthRequired AuthMode = "required"
+
+	// AuthAnonymous accepts anonymous requests even when the handler uses WithRequireAuth.
+	AuthAnonymous AuthMode = "anonymous"
+)
+
+// Authenticated rejects anonymous requests to the route with 401.
+func Authenticated() RouteOption {
+	return func(r *Route) {
+		r.Auth = AuthRequired
+	}
+}
+
+// Anonymous accepts anonymous requests to the route, e.g. a public route of a group using WithRequireAuth.
+// The claims are still parsed when a token is sent.
+func Anonymous() RouteOption {
+	return func(r *Route) {
+		r.Auth = AuthAnonymous
+	}
+}
+
+// Protected reports whether the route declares any authorization requirement.
+func (r *Route) Protected() bool {
+	return len(r.Scopes) > 0 || len(r.Roles) > 0 || len(r.Policies) > 0
+}
+
+// CurrentRoute returns the registry route handling the request, nil for routes registered on fiber directly.
+func CurrentRoute(c *fiber.Ctx) *Route {
+	route, _ := c.Locals(routeKey{}).(*Route)
+	return route
+}
+
+// Registry registers fiber routes and keeps track of the methods each path supports, so requests
+// with an unsupported method get 405 with an Allow header and OPTIONS is answered automatically.
+// All methods of a path must be registered through the registry.
+type Registry struct {
+	router  fiber.Router
+	handler ApiHandler
+	prefix  string
+	table   *routeTable
+}
+
+type routeTable struct {
+	mu      sync.RWMutex
+	routes  []Route
+	methods map[string][]string
+
+	// implicitHead holds the paths whose HEAD is answered by their GET handler
+	implicitHead map[string]bool
+}
+
+func (r *Registry) Get(path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	return r.Add(http.MethodGet, path, handler, opts...)
+}
+
+func (r *Registry) Head(path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	return r.Add(http.MethodHead, path, handler, opts...)
+}
+
+func (r *Registry) Post(path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	return r.Add(http.MethodPost, path, handler, opts...)
+}
+
+func (r *Registry) Put(path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	return r.Add(http.MethodPut, path, handler, opts...)
+}
+
+func (r *Registry) Patch(path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	return r.Add(http.MethodPatch, path, handler, opts...)
+}
+
+func (r *Registry) Delete(path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	return r.Add(http.MethodDelete, path, handler, opts...)
+}
+
+func (r *Registry) Options(path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	return r.Add(http.MethodOptions, path, handler, opts...)
+}
+
+// Add registers handler for method and path with the route metadata declared by opts.
+func (r *Registry) Add(method, path string, handler fiber.Handler, opts ...RouteOption) *Registry {
+	route := Route{Method: strings.ToUpper(method), Path: joinPath(r.prefix, path)}
+	for _, opt := range opts {
+		opt(&route)
+	}
+
+	r.router.Add(route.Method, path, withRoute(route, handler))
+	first := r.table.add(route)
+
+	// Answer HEAD for GET routes like fiber's Get does, unless the path has its own HEAD route. One
+	// registered later takes over, the requests are passed on to it.
+	if route.Method == http.MethodGet && r.table.addImplicitHead(route) {
+		head := route
+		head.Method = http.MethodHead
+		implicit := withRoute(head, handler)
+		r.router.Add(http.MethodHead, path, func(c *fiber.Ctx) error {
+			if !r.table.hasImplicitHead(head.Path) {
+				return c.Next()
+			}
+			return implicit(c)
+		})
+	}
+
+	if first {
+		r.router.All(path, r.fallback(route.Path))
+	}
+	return r
+}
+
+func withRoute(route Route, handler fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(routeKey{}, &route)
+		if route.SampleRate != nil {
+			c.SetUserContext(context.WithValue(c.UserContext(), sampleRateKey{}, *route.SampleRate))
+		}
+		return handler(c)
+	}
+}
+
+// Group returns a registry for routes under prefix sharing the same route table.
+func (r *Registry) Group(prefix string, handlers ...fiber.Handler) *Registry {
+	return &Registry{
+		router:  r.router.Group(prefix, handlers...),
+		handler: r.handler,
+		prefix:  joinPath(r.prefix, prefix),
+		table:   r.table,
+	}
+}
+
+// Routes returns the registered routes in registration order.
+func (r *Registry) Routes() []Route {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	return slices.Clone(r.table.routes)
+}
+
+// Export returns the registered routes with their scopes, roles and policies as JSON, e.g. for an API
+// gateway or security review tooling.
+func (r *Registry) Export() ([]byte, error) {
+	return json.MarshalIndent(r.Routes(), "", "  ")
+}
+
+// Allowed returns the methods registered for path, as written at registration.
+func (r *Registry) Allowed(path string) []string {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	return slices.Clone(r.table.methods[path])
+}
+
+// fallback is registered once per path after its first route. Requests for methods registered
+// later on the same path are passed on, anything else is answered with the allowed methods.
+func (r *Registry) fallback(path string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		allowed := r.Allowed(path)
+		if slices.Contains(allowed, c.Method()) {
+			return c.Next()
+		}
+
+		if !slices.Contains(allowed, http.MethodOptions) {
+			allowed = append(allowed, http.MethodOptions)
+		}
+		c.Set(fiber.HeaderAllow, strings.Join(allowed, ", "))
+
+		if c.Method() == http.MethodOptions {
+			return c.SendStatus(fiber.StatusNoContent)
+		}
+		return r.handler.SendError(c, NewMethodNotAllowedError())
+	}
+}
+
+func (t *routeTable) add(route Route) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	methods, exists := t.methods[route.Path]
+	if route.Method == http.MethodHead && t.implicitHead[route.Path] {
+		// An explicit HEAD replaces the one answered by GET
+		delete(t.implicitHead, route.Path)
+		for i, registered := range t.routes {
+			if registered.Method == http.MethodHead && registered.Path == route.Path {
+				t.routes[i] = route
+			}
+		}
+		return false
+	}
+	if slices.Contains(methods, route.Method) {
+		return false
+	}
+	t.routes = append(t.routes, route)
+	t.methods[route.Path] = append(methods, route.Method)
+	return !exists
+}
+
+// addImplicitHead adds the HEAD answered by the GET route unless the path has a HEAD route, and reports
+// whether it did.
+func (t *routeTable) addImplicitHead(get Route) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if slices.Contains(t.methods[get.Path], http.MethodHead) {
+		return false
+	}
+	head := get
+	head.Method = http.MethodHead
+	t.routes = append(t.routes, head)
+	t.methods[get.Path] = append(t.methods[get.Path], http.MethodHead)
+	t.implicitHead[get.Path] = true
+	return true
+}
+
+func (t *routeTable) hasImplicitHead(path string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.implicitHead[path]
+}
+
+func joinPath(prefix, path string) string {
+	if path == "" {
+		return prefix
+	}
+	if path[0] != '/' {
+		path = "/" + path
+	}
+	return strings.TrimRight(prefix, "/") + path
+}
+
+func NewRegistry(router fiber.Router, handler ApiHandler) *Registry {
+	return &Registry{
+		router:  router,
+		handler: handler,
+		table: &routeTable{
+			methods:      map[string][]string{},
+			implicitHead: map[string]bool{},
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package fiberhandler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ResourceService implements the operations of a REST resource of items T identified by a string ID.
+// The claims of the caller are available with ClaimsFromContext.
+type ResourceService[T any] interface {
+	List(ctx context.Context, page Pageable) (items []T, total int64, err error)
+	Get(ctx context.Context, id string) (*T, error)
+	Create(ctx context.Context, item *T) (*T, error)
+	Update(ctx context.Context, id string, item *T) (*T, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ResourceConfig declares the route metadata of the operations registered by Resource.
+type ResourceConfig struct {
+	// Read applies to List and Get, e.g. Scopes("users:read").
+	Read []RouteOption
+
+	// Write applies to Create, Update and Delete, e.g. Scopes("users:write").
+	Write []RouteOption
+}
+
+// ResourceListRequest is the request of the List operation of a Resource.
+type ResourceListRequest struct {
+	Pageable
+}
+
+// Resource registers the CRUD routes of service under path on registry:
+//
+//	GET    path      List, a Paged result
+//	POST   path      Create, the item is bound from the body and validated, 201 Created
+//	GET    path/:id  Get
+//	PUT    path/:id  Update, the item is bound from the body and validated
+//	DELETE path/:id  Delete, 204 No Content
+//
+// Return an error with a status for missing items, e.g. &ResponseError{Status: http.StatusNotFound}.
+func Resource[T any](registry *Registry, path string, service ResourceService[T], config ...ResourceConfig) *Registry {
+	cfg := ResourceConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	h := registry.handler
+	itemPath := joinPath(path, ":id")
+
+	registry.Get(path, func(c *fiber.Ctx) error {
+		req := &ResourceListRequest{}
+		return h.Do(c, req, true, func(ctx context.Context) (any, error) {
+			items, total, err := service.List(ctx, req.Pageable)
+			if err != nil {
+				return nil, err
+			}
+			return NewPaged(items, total, req.Pageable), nil
+		})
+	}, cfg.Read...)
+
+	registry.Post(path, func(c *fiber.Ctx) error {
+		item := new(T)
+		return h.Do(c, item, true, func(ctx context.Context) (any, error) {
+			created, err := service.Create(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			return Created(created), nil
+		})
+	}, cfg.Write...)
+
+	registry.Get(itemPath, func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		return h.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			return service.Get(ctx, id)
+		})
+	}, cfg.Read...)
+
+	registry.Put(itemPath, func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		item := new(T)
+		return h.Do(c, item, true, func(ctx context.Context) (any, error) {
+			return service.Update(ctx, id, item)
+		})
+	}, cfg.Write...)
+
+	registry.Delete(itemPath, func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		return h.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			if err := service.Delete(ctx, id); err != nil {
+				return nil, err
+			}
+			return NoContent(), nil
+		})
+	}, cfg.Write...)
+
+	return registry
+}
@@ -0,0 +1,225 @@
+package fiberhandler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	responseCachePrefix     = "fiberhandler:cache:"
+	responseCacheGeneration = responseCachePrefix + "generation"
+)
+
+// ResponseCache stores successful GET responses in a Store, see WithResponseCache.
+type ResponseCache struct {
+	store Store
+}
+
+// CacheConfig configures the responses cached by WithResponseCache.
+type CacheConfig struct {
+	// TTL is how long a response is served from the cache, 0 keeps it until invalidated.
+	TTL time.Duration
+
+	// Key returns the cache key of the request, default the original URL with its query string and
+	// a hash of the claims of the caller, from the TokenParser or the Authenticator, and of the
+	// Authorization and Cookie headers, so callers never share an entry. A custom key must include the
+	// caller when the response depends on it.
+	Key func(c *fiber.Ctx) string
+}
+
+type cachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag"`
+	Body        []byte `json:"body"`
+}
+
+type cacheState struct {
+	cache *ResponseCache
+	key   string
+	ttl   time.Duration
+}
+
+// WithResponseCache caches the successful GET responses of the handler in cache. Authorization and
+// validation run on every request, only doFunc is skipped on a hit. Every cacheable response gets an
+// ETag hashed from its body and matching If-None-Match requests are answered with 304 Not Modified.
+//
+// The cached body is the one sent, after WithFieldACL and WithWatermark, so the cache is disabled on
+// handlers using either of them.
+func WithResponseCache(cache *ResponseCache, config ...CacheConfig) Option {
+	cfg := CacheConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	return func(o *options) {
+		o.responseCache = &responseCacheOption{cache: cache, config: cfg}
+	}
+}
+
+// defaultCacheKey returns the original URL followed by a hash of the identity and the credentials of
+// the caller.
+func defaultCacheKey(c *fiber.Ctx, caller string) string {
+	authorization := c.Get(fiber.HeaderAuthorization)
+	cookie := c.Get(fiber.HeaderCookie)
+	if caller == "" && authorization == "" && cookie == "" {
+		return c.OriginalURL()
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(caller))
+	hash.Write([]byte{0})
+	hash.Write([]byte(authorization))
+	hash.Write([]byte{0})
+	hash.Write([]byte(cookie))
+	return c.OriginalURL() + "#" + base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// dropPersonalizedCache disables the response cache when the sent body depends on the claims.
+func (o *options) dropPersonalizedCache() {
+	if o.responseCache == nil || (o.fieldACL == nil && o.watermark == nil) {
+		return
+	}
+	slog.Warn("Response cache disabled, responses are filtered or watermarked per caller")
+	o.responseCache = nil
+}
+
+type responseCacheOption struct {
+	cache  *ResponseCache
+	config CacheConfig
+}
+
+// Invalidate removes the response cached under key, as returned by CacheConfig.Key.
+func (r *ResponseCache) Invalidate(ctx context.Context, key string) error {
+	generation, err := r.generation(ctx)
+	if err != nil {
+		return err
+	}
+	return r.store.Delete(ctx, r.entryKey(generation, key))
+}
+
+// Purge invalidates every cached response. Entries are not deleted from the store, they are no longer
+// read and expire with their TTL.
+func (r *ResponseCache) Purge(ctx context.Context) error {
+	_, err := r.store.Increment(ctx, responseCacheGeneration, 1, 0)
+	return err
+}
+
+func (r *ResponseCache) generation(ctx context.Context) (string, error) {
+	generation, err := r.store.Get(ctx, responseCacheGeneration)
+	if errors.Is(err, ErrKeyNotFound) {
+		return "0", nil
+	}
+	return string(generation), err
+}
+
+func (r *ResponseCache) entryKey(generation, key string) string {
+	return responseCachePrefix + generation + ":" + key
+}
+
+// serveCached answers the request from the cache and reports whether it did. On a miss it returns the
+// state used to store the response once it has been sent. caller identifies the caller for the default
+// key.
+func (o *responseCacheOption) serveCached(c *fiber.Ctx, caller string) (*cacheState, bool, error) {
+	if c.Method() != http.MethodGet && c.Method() != http.MethodHead {
+		return nil, false, nil
+	}
+
+	ctx := c.UserContext()
+	generation, err := o.cache.generation(ctx)
+	if err != nil {
+		slog.Error("Failed to read response cache", slog.String("error", err.Error()))
+		return nil, false, nil
+	}
+
+	key := defaultCacheKey(c, caller)
+	if o.config.Key != nil {
+		key = o.config.Key(c)
+	}
+	state := &cacheState{cache: o.cache, key: o.cache.entryKey(generation, key), ttl: o.config.TTL}
+	value, err := o.cache.store.Get(ctx, state.key)
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) {
+			slog.Error("Failed to read response cache", slog.String("error", err.Error()))
+		}
+		return state, false, nil
+	}
+
+	var cached cachedResponse
+	if err := json.Unmarshal(value, &cached); err != nil {
+		slog.Error("Failed to decode cached response", slog.String("error", err.Error()))
+		return state, false, nil
+	}
+
+	c.Set(fiber.HeaderETag, cached.ETag)
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), cached.ETag) {
+		return nil, true, c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, cached.ContentType)
+	return nil, true, c.Status(cached.Status).Send(cached.Body)
+}
+
+// store caches the sent response when it is a complete 200 OK body, and answers with 304 when the
+// request already holds it.
+func (s *cacheState) store(c *fiber.Ctx) {
+	response := c.Response()
+	if response.StatusCode() != fiber.StatusOK || response.IsBodyStream() {
+		return
+	}
+
+	body := response.Body()
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	c.Set(fiber.HeaderETag, etag)
+
+	value, err := json.Marshal(cachedResponse{
+		Status:      response.StatusCode(),
+		ContentType: string(response.Header.ContentType()),
+		ETag:        etag,
+		Body:        body,
+	})
+	if err == nil {
+		err = s.cache.store.Set(c.UserContext(), s.key, value, s.ttl)
+	}
+	if err != nil {
+		slog.Error("Failed to write response cache", slog.String("error", err.Error()))
+	}
+
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		response.ResetBody()
+		c.Status(fiber.StatusNotModified)
+	}
+}
+
+// etagMatches compares If-None-Match against etag with the weak comparison of RFC 9110.
+func etagMatches(noneMatch, etag string) bool {
+	if noneMatch == "" || etag == "" {
+		return false
+	}
+	for _, tag := range strings.Split(noneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func NewResponseCache(store ...Store) *ResponseCache {
+	cache := &ResponseCache{}
+	if len(store) > 0 {
+		cache.store = store[0]
+	} else {
+		cache.store = NewMemoryStore()
+	}
+	return cache
+}
@@ -0,0 +1,80 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+type profile struct {
+	Sub string `json:"sub"`
+}
+
+func TestResponseCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  string
+		second string
+		calls  int
+	}{
+		{name: "same caller", first: "ada", second: "ada", calls: 1},
+		{name: "other caller", first: "ada", second: "bob", calls: 2},
+		{name: "anonymous", first: "", second: "", calls: 1},
+		{name: "anonymous then caller", first: "", second: "ada", calls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := fiberhandler.NewWithComponents(
+				fiberhandler.Components[testClaims]{Authenticator: headerAuthenticator},
+				fiberhandler.WithResponseCache(fiberhandler.NewResponseCache()),
+			)
+			calls := 0
+			handler := func(c *fiber.Ctx) error {
+				return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+					calls++
+					claims, _ := fiberhandler.ClaimsFromContext[testClaims](ctx)
+					if claims == nil {
+						return profile{}, nil
+					}
+					return profile{Sub: claims.Sub}, nil
+				})
+			}
+			get := func(user string) profile {
+				var got profile
+				fiberhandlertest.Get("/me").Header("X-User", user).Run(t, handler).Status(http.StatusOK).Data(&got)
+				return got
+			}
+
+			get(tt.first)
+			if got := get(tt.second); got.Sub != tt.second {
+				t.Errorf("sub = %q, want %q", got.Sub, tt.second)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestResponseCacheNotModified(t *testing.T) {
+	handle := fiberhandler.NewWithComponents(
+		fiberhandler.Components[testClaims]{},
+		fiberhandler.WithResponseCache(fiberhandler.NewResponseCache()),
+	)
+	handler := func(c *fiber.Ctx) error {
+		return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+			return profile{Sub: "ada"}, nil
+		})
+	}
+
+	etag := fiberhandlertest.Get("/me").Run(t, handler).Status(http.StatusOK).Header.Get(fiber.HeaderETag)
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	fiberhandlertest.Get("/me").Header(fiber.HeaderIfNoneMatch, etag).Run(t, handler).Status(http.StatusNotModified)
+}
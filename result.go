@@ -0,0 +1,212 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Result sends Data in the success envelope with a custom HTTP status, headers and cookies,
+// e.g. a Location header on create or Set-Cookie on login.
+type Result struct {
+	Status  int
+	Data    any
+	Headers map[string]string
+	Cookies []*fiber.Cookie
+}
+
+// WithHeader sets a response header and returns the result for chaining.
+func (r *Result) WithHeader(key, value string) *Result {
+	if r.Headers == nil {
+		r.Headers = map[string]string{}
+	}
+	r.Headers[key] = value
+	return r
+}
+
+// WithETag sets the ETag header, a quoted entity tag. GET and HEAD requests whose If-None-Match matches
+// it are answered with 304 Not Modified without encoding Data.
+func (r *Result) WithETag(tag string) *Result {
+	return r.WithHeader(fiber.HeaderETag, tag)
+}
+
+// WithCookie adds a response cookie and returns the result for chaining.
+func (r *Result) WithCookie(cookie *fiber.Cookie) *Result {
+	r.Cookies = append(r.Cookies, cookie)
+	return r
+}
+
+// OK returns data with 200 OK.
+func OK(data any) *Result {
+	return &Result{Status: fiber.StatusOK, Data: data}
+}
+
+// Created returns data with 201 Created.
+func Created(data any) *Result {
+	return &Result{Status: fiber.StatusCreated, Data: data}
+}
+
+// Accepted returns data with 202 Accepted.
+func Accepted(data any) *Result {
+	return &Result{Status: fiber.StatusAccepted, Data: data}
+}
+
+// NoContent returns an empty body with 204 No Content.
+func NoContent() *Result {
+	return &Result{Status: fiber.StatusNoContent}
+}
+
+// NotModified returns an empty body with 304 Not Modified, e.g. after checking a version sent by the client.
+func NotModified() *Result {
+	return &Result{Status: fiber.StatusNotModified}
+}
+
+// Redirect redirects to location with 302 Found or the given redirect status, e.g. 303 See Other after a
+// form post. It panics on a status other than 301, 302, 303, 307 and 308.
+func Redirect(location string, status ...int) *Result {
+	code := fiber.StatusFound
+	if len(status) > 0 {
+		code = status[0]
+	}
+	switch code {
+	case fiber.StatusMovedPermanently, fiber.StatusFound, fiber.StatusSeeOther,
+		fiber.StatusTemporaryRedirect, fiber.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("fiberhandler: invalid redirect status %d", code))
+	}
+	return (&Result{Status: code}).WithHeader(fiber.HeaderLocation, location)
+}
+
+func (h *apiHandler[T]) sendStatusResult(c *fiber.Ctx, result *Result) error {
+	status := result.Status
+	if status == 0 {
+		status = fiber.StatusOK
+	}
+
+	for key, value := range result.Headers {
+		c.Set(key, value)
+	}
+	for _, cookie := range result.Cookies {
+		c.Cookie(cookie)
+	}
+
+	if !bodyAllowed(status) || isRedirect(status) {
+		c.Status(status)
+		return nil
+	}
+
+	var err error
+	if h.options.rawResponse {
+		err = h.sendRaw(c, result.Data)
+	} else {
+		err = h.sendEnvelope(c, result.Data)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Status(status)
+	return nil
+}
+
+// bodylessStatus returns the status of a response without body: a Result with 204 or 304, a Result
+// whose ETag matches If-None-Match, or a 204 or 304 already set on the response. It is checked before
+// the data is watermarked or encoded, so no work is spent on a body that would be discarded.
+func bodylessStatus(c *fiber.Ctx, data any) (int, bool) {
+	result, ok := data.(*Result)
+	if !ok {
+		status := c.Response().StatusCode()
+		return status, !bodyAllowed(status)
+	}
+
+	if !bodyAllowed(result.Status) {
+		return result.Status, true
+	}
+	method := c.Method()
+	if (method == fiber.MethodGet || method == fiber.MethodHead) && result.Status < fiber.StatusMultipleChoices &&
+		etagMatches(c.Get(fiber.HeaderIfNoneMatch), result.Headers[fiber.HeaderETag]) {
+		return fiber.StatusNotModified, true
+	}
+	return 0, false
+}
+
+// sendBodyless sends the status, headers and cookies of data without body.
+func sendBodyless(c *fiber.Ctx, data any, status int) error {
+	if result, ok := data.(*Result); ok {
+		for key, value := range result.Headers {
+			c.Set(key, value)
+		}
+		for _, cookie := range result.Cookies {
+			c.Cookie(cookie)
+		}
+	}
+	c.Response().ResetBody()
+	c.Status(status)
+	return nil
+}
+
+func isRedirect(status int) bool {
+	return status >= fiber.StatusMultipleChoices && status < fiber.StatusBadRequest && status != fiber.StatusNotModified
+}
+
+// bodyAllowed reports whether a response with status may carry a body.
+func bodyAllowed(status int) bool {
+	return status != fiber.StatusNoContent && status != fiber.StatusNotModified
+}
+
+// RawResult is sent as is, without the success envelope.
+type RawResult struct {
+	Data any
+}
+
+// Raw returns data without the success envelope, e.g. for webhook callbacks or spec-mandated response shapes.
+// A []byte or string is written verbatim, nil leaves the body empty and anything else is encoded as JSON.
+func Raw(data any) *RawResult {
+	return &RawResult{Data: data}
+}
+
+// RenderResult renders an HTML template through the fiber views engine.
+type RenderResult struct {
+	Name    string
+	Bind    any
+	Layouts []string
+}
+
+// Render returns a result rendering the named template with bind.
+func Render(name string, bind any, layouts ...string) *RenderResult {
+	return &RenderResult{Name: name, Bind: bind, Layouts: layouts}
+}
+
+func (h *apiHandler[T]) sendRender(c *fiber.Ctx, result *RenderResult) error {
+	bind := result.Bind
+	if h.options.csp != nil {
+		var err error
+		bind, err = h.options.csp.apply(c, bind)
+		if err != nil {
+			slog.Error("Failed to generate CSP nonce", slog.String("error", err.Error()))
+			return h.SendError(c, err)
+		}
+	}
+	return c.Render(result.Name, bind, result.Layouts...)
+}
+
+func (h *apiHandler[T]) sendRaw(c *fiber.Ctx, data any) error {
+	switch payload := data.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return c.Send(payload)
+	case string:
+		return c.SendString(payload)
+	}
+	if h.options.jsonCodec != nil {
+		body, err := h.options.jsonCodec.Marshal(data)
+		if err != nil {
+			return h.SendError(c, err)
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(body)
+	}
+	return c.JSON(data)
+}
@@ -0,0 +1,50 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+func TestRedirect(t *testing.T) {
+	tests := []struct {
+		status int
+		panics bool
+	}{
+		{status: http.StatusMovedPermanently},
+		{status: http.StatusFound},
+		{status: http.StatusSeeOther},
+		{status: http.StatusTemporaryRedirect},
+		{status: http.StatusPermanentRedirect},
+		{status: http.StatusMultipleChoices, panics: true},
+		{status: http.StatusNotModified, panics: true},
+		{status: http.StatusUseProxy, panics: true},
+		{status: 306, panics: true},
+		{status: http.StatusOK, panics: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			defer func() {
+				if recovered := recover(); (recovered != nil) != tt.panics {
+					t.Errorf("panic = %v, want panic %v", recovered, tt.panics)
+				}
+			}()
+			result := fiberhandler.Redirect("/login", tt.status)
+
+			handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{})
+			fiberhandlertest.Get("/").
+				Run(t, func(c *fiber.Ctx) error {
+					return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+						return result, nil
+					})
+				}).
+				Status(tt.status).
+				HasHeader(fiber.HeaderLocation, "/login")
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 2 * time.Second
+	defaultRetryMultiplier = 2
+)
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// MaxAttempts is the number of calls including the first one, default 3.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, default 100ms. It grows by Multiplier, default 2,
+	// up to MaxBackoff, default 2s, with a random jitter of up to a half.
+	Backoff    time.Duration
+	Multiplier float64
+	MaxBackoff time.Duration
+
+	// Retryable reports whether a call failing with err may be retried. Default server errors (see
+	// CircuitBreakerConfig.IsFailure) and rate limits, except timeouts.
+	Retryable func(err error) bool
+}
+
+// Retry decorates doFunc to retry its failed calls with exponential backoff while policy allows it,
+// for doFuncs calling flaky downstream services. Only decorate idempotent calls: a retried doFunc
+// runs its side effects again. The Retry-After of a TooManyRequestsError or an UnavailableError is
+// waited when it is longer than the backoff and within MaxBackoff. Retries stop with the context.
+func Retry(doFunc DoFunc, policy ...RetryPolicy) DoFunc {
+	p := RetryPolicy{}
+	if len(policy) > 0 {
+		p = policy[0]
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = defaultRetryBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if p.Retryable == nil {
+		p.Retryable = isRetryable
+	}
+
+	return func(ctx context.Context) (any, error) {
+		backoff := p.Backoff
+		for attempt := 1; ; attempt++ {
+			data, err := doFunc(ctx)
+			if err == nil || attempt >= p.MaxAttempts {
+				return data, err
+			}
+			translated := translateDownstreamError(err)
+			if !p.Retryable(translated) {
+				return data, err
+			}
+
+			delay := backoff/2 + rand.N(backoff/2+1)
+			if retryAfter := retryAfterOf(translated); retryAfter > delay && retryAfter <= p.MaxBackoff {
+				delay = retryAfter
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return data, err
+			case <-timer.C:
+			}
+
+			backoff = min(time.Duration(float64(backoff)*p.Multiplier), p.MaxBackoff)
+		}
+	}
+}
+
+// WithRetry decorates the doFunc of GET, HEAD and OPTIONS requests with Retry.
+func WithRetry(policy ...RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = func(doFunc DoFunc) DoFunc {
+			return Retry(doFunc, policy...)
+		}
+	}
+}
+
+// retried returns doFunc decorated with the retry policy of the handler for idempotent requests.
+func (h *apiHandler[T]) retried(c *fiber.Ctx, doFunc DoFunc) DoFunc {
+	if h.options.retry == nil {
+		return doFunc
+	}
+	switch c.Method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return h.options.retry(doFunc)
+	}
+	return doFunc
+}
+
+func isRetryable(err error) bool {
+	if IsTimeout(err) {
+		return false
+	}
+	return IsTooManyRequests(err) || isServerFailure(err)
+}
+
+func retryAfterOf(err error) time.Duration {
+	if tooMany, ok := AsTooManyRequests(err); ok {
+		return tooMany.RetryAfter
+	}
+	if unavailable, ok := AsUnavailable(err); ok {
+		return unavailable.RetryAfter
+	}
+	var retryErr RetryAfterer
+	if errors.As(err, &retryErr) {
+		return retryErr.RetryAfter()
+	}
+	return 0
+}
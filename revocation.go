@@ -0,0 +1,129 @@
+package fiberhandler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const revocationPrefix = "fiberhandler:revoked:"
+
+// RevokedToken identifies a token checked by a RevocationChecker.
+type RevokedToken struct {
+	// ID is the jti claim of a JWT, "" when the token has none.
+	ID string
+
+	// Hash is the hex encoded SHA-256 of the raw token, for tokens without ID.
+	Hash string
+}
+
+// NewRevokedToken identifies the raw token by its jti claim and its hash.
+func NewRevokedToken(token string) RevokedToken {
+	sum := sha256.Sum256([]byte(token))
+	return RevokedToken{ID: tokenID(token), Hash: hex.EncodeToString(sum[:])}
+}
+
+// key returns the key the token is revoked under, its ID when it has one.
+func (t RevokedToken) key() string {
+	if t.ID != "" {
+		return "jti:" + t.ID
+	}
+	return "sha256:" + t.Hash
+}
+
+// tokenID returns the jti claim of a JWT.
+func tokenID(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		ID string `json:"jti"`
+	}
+	_ = json.Unmarshal(payload, &claims)
+	return claims.ID
+}
+
+// RevocationChecker reports whether a token was revoked, e.g. at logout or after a compromise.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, token RevokedToken) (bool, error)
+}
+
+// RevocationCheckerFunc adapts a function to RevocationChecker.
+type RevocationCheckerFunc func(ctx context.Context, token RevokedToken) (bool, error)
+
+// IsRevoked implements RevocationChecker.
+func (f RevocationCheckerFunc) IsRevoked(ctx context.Context, token RevokedToken) (bool, error) {
+	return f(ctx, token)
+}
+
+// WithRevocationChecker consults checker once the token of the default Authenticator is parsed.
+// Revoked tokens are rejected with a TokenError (401, CLE044) even before they expire. The request
+// fails when the checker does, a revoked token must not pass while the checker is down.
+func WithRevocationChecker(checker RevocationChecker) Option {
+	return func(o *options) {
+		o.revocationChecker = checker
+	}
+}
+
+func (h *apiHandler[T]) checkRevocation(ctx context.Context, token string) error {
+	if h.options.revocationChecker == nil {
+		return nil
+	}
+
+	revoked, err := h.options.revocationChecker.IsRevoked(ctx, NewRevokedToken(token))
+	if err != nil {
+		slog.Error("Failed to check token revocation", h.redactor().errorAttr(err))
+		return NewUnavailableError(0)
+	}
+	if revoked {
+		return NewTokenError(ErrTokenRevoked)
+	}
+	return nil
+}
+
+// StoreRevocation is a RevocationChecker keeping the revoked tokens in a Store.
+type StoreRevocation struct {
+	store Store
+}
+
+// NewStoreRevocation returns a RevocationChecker backed by store, default an in-memory store. Share a
+// Redis backed Store between instances so a token revoked on one is rejected by all.
+func NewStoreRevocation(store ...Store) *StoreRevocation {
+	s := &StoreRevocation{}
+	if len(store) > 0 && store[0] != nil {
+		s.store = store[0]
+	} else {
+		s.store = NewMemoryStore()
+	}
+	return s
+}
+
+// Revoke revokes the raw token until it expires, there is no need to remember it afterwards.
+func (s *StoreRevocation) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.store.Set(ctx, revocationPrefix+NewRevokedToken(token).key(), []byte("1"), ttl)
+}
+
+// IsRevoked implements RevocationChecker.
+func (s *StoreRevocation) IsRevoked(ctx context.Context, token RevokedToken) (bool, error) {
+	_, err := s.store.Get(ctx, revocationPrefix+token.key())
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
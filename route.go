@@ -0,0 +1,59 @@
+package fiberhandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestFunc handles a bound and validated request.
+type RequestFunc[R any] func(ctx context.Context, req *R) (any, error)
+
+// Handle returns a fiber handler allocating a request R per call, binding its fields tagged params from
+// the route parameters, then binding and validating it with Do before calling fn. It plugs into a
+// Registry or any fiber router:
+//
+//	registry.Get("/users/:id", fiberhandler.Handle(handle, users.Get), fiberhandler.Scopes("users:read"))
+func Handle[R any](h ApiHandler, fn RequestFunc[R]) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(R)
+		if len(c.Route().Params) > 0 {
+			if err := c.ParamsParser(req); err != nil {
+				return h.SendError(c, parseError(err))
+			}
+		}
+		return h.Do(c, req, true, func(ctx context.Context) (any, error) {
+			return fn(ctx, req)
+		})
+	}
+}
+
+// Get registers fn for GET requests to path on router:
+//
+//	fiberhandler.Get(app, "/users/:id", handle, func(ctx context.Context, req *GetUserRequest) (any, error) {
+//		return users.Find(ctx, req.ID)
+//	})
+func Get[R any](router fiber.Router, path string, h ApiHandler, fn RequestFunc[R]) fiber.Router {
+	return router.Add(http.MethodGet, path, Handle(h, fn))
+}
+
+// Post registers fn for POST requests to path on router.
+func Post[R any](router fiber.Router, path string, h ApiHandler, fn RequestFunc[R]) fiber.Router {
+	return router.Add(http.MethodPost, path, Handle(h, fn))
+}
+
+// Put registers fn for PUT requests to path on router.
+func Put[R any](router fiber.Router, path string, h ApiHandler, fn RequestFunc[R]) fiber.Router {
+	return router.Add(http.MethodPut, path, Handle(h, fn))
+}
+
+// Patch registers fn for PATCH requests to path on router.
+func Patch[R any](router fiber.Router, path string, h ApiHandler, fn RequestFunc[R]) fiber.Router {
+	return router.Add(http.MethodPatch, path, Handle(h, fn))
+}
+
+// Delete registers fn for DELETE requests to path on router.
+func Delete[R any](router fiber.Router, path string, h ApiHandler, fn RequestFunc[R]) fiber.Router {
+	return router.Add(http.MethodDelete, path, Handle(h, fn))
+}
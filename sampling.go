@@ -0,0 +1,52 @@
+package fiberhandler
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+)
+
+type sampleRateKey struct{}
+
+// SampleRate overrides the tracing sample rate of the route, from 0 (never) to 1 (always), e.g. 1 on
+// payment routes and 0.01 on health checks. Tracers pick it up through RouteSampleRate or SampleRoute.
+func SampleRate(rate float64) RouteOption {
+	rate = math.Max(0, math.Min(1, rate))
+	return func(r *Route) {
+		r.SampleRate = &rate
+	}
+}
+
+// RouteSampleRate returns the sample rate declared by the route handling the request of ctx, ok is
+// false when the route does not override the global sampler.
+func RouteSampleRate(ctx context.Context) (rate float64, ok bool) {
+	rate, ok = ctx.Value(sampleRateKey{}).(float64)
+	return rate, ok
+}
+
+// SampleRoute decides whether the trace should be sampled under the sample rate of the route in ctx.
+// The decision is derived from the trace id like OpenTelemetry's TraceIDRatioBased sampler, so every
+// service sampling at the same rate keeps the same traces. ok is false when the route has no override
+// and the decision belongs to the global sampler, e.g. from a custom sdktrace.Sampler:
+//
+//	func (s routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+//		if sampled, ok := fiberhandler.SampleRoute(p.ParentContext, p.TraceID); ok {
+//			...
+//		}
+//		return s.fallback.ShouldSample(p)
+//	}
+func SampleRoute(ctx context.Context, traceID [16]byte) (sampled bool, ok bool) {
+	rate, ok := RouteSampleRate(ctx)
+	if !ok {
+		return false, false
+	}
+	return traceIDBelow(traceID, rate), true
+}
+
+func traceIDBelow(traceID [16]byte, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	bound := uint64(rate * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:16])>>1 < bound
+}
@@ -0,0 +1,224 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TagSanitize is the struct tag listing the sanitizers of a request field in order, e.g.
+// `sanitize:"trim,lower"`. "-" leaves the field as sent, e.g. a password.
+const TagSanitize = "sanitize"
+
+// Sanitizer cleans a string bound from the request.
+type Sanitizer func(value string) string
+
+// builtinSanitizers are the sanitizers available to every handler.
+var builtinSanitizers = map[string]Sanitizer{
+	"trim":  strings.TrimSpace,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"nfc":   norm.NFC.String,
+	"nfkc":  norm.NFKC.String,
+	"html":  html.EscapeString,
+}
+
+// SanitizeConfig configures WithSanitization.
+type SanitizeConfig struct {
+	// TrimSpace, Normalize (Unicode NFC) and EscapeHTML apply to every string field not tagged
+	// sanitize:"-", before and after the sanitizers of its tag.
+	TrimSpace  bool
+	Normalize  bool
+	EscapeHTML bool
+
+	// Sanitizers are the custom sanitizers named in tags, next to trim, lower, upper, nfc, nfkc and html.
+	Sanitizers map[string]Sanitizer
+}
+
+// sanitizePlan lists the fields of a struct type holding strings to sanitize. It is compiled at the
+// first use of the type, fields without anything to sanitize are left out so they are not walked.
+type sanitizePlan struct {
+	fields []sanitizeField
+}
+
+type sanitizeField struct {
+	index int
+	chain []Sanitizer
+
+	// nested is the plan of the structs held by the field, nil when they have nothing to sanitize
+	nested *sanitizePlan
+}
+
+type cachedSanitizePlan struct {
+	plan *sanitizePlan
+	err  error
+}
+
+type sanitization struct {
+	config SanitizeConfig
+	types  sync.Map
+}
+
+// defaultSanitization runs the sanitize tags of handlers without WithSanitization.
+var defaultSanitization = &sanitization{}
+
+// WithSanitization cleans the strings of every request between binding and validation, so validation
+// and doFunc see what will be stored:
+//
+//	fiberhandler.WithSanitization(fiberhandler.SanitizeConfig{
+//		TrimSpace:  true,
+//		Sanitizers: map[string]fiberhandler.Sanitizer{"slug": slug.Make},
+//	})
+//
+// Without it, only the built-in sanitizers named in tags run.
+func WithSanitization(config SanitizeConfig) Option {
+	return func(o *options) {
+		o.sanitization = &sanitization{config: config}
+	}
+}
+
+func (h *apiHandler[T]) sanitize(requestPtr any) error {
+	s := h.options.sanitization
+	if s == nil {
+		s = defaultSanitization
+	}
+
+	v := reflect.ValueOf(requestPtr)
+	if !v.IsValid() {
+		return nil
+	}
+	t := elemType(v.Type())
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	plan, err := s.planOf(t)
+	if err != nil || plan == nil {
+		return err
+	}
+	s.value(v, nil, plan)
+	return nil
+}
+
+// elemType returns the type held by t through pointers, slices and arrays.
+func elemType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// value sanitizes the strings held by v with chain, the fields of structs with their plan.
+func (s *sanitization) value(v reflect.Value, chain []Sanitizer, plan *sanitizePlan) {
+	switch v.Kind() {
+	case reflect.String:
+		if len(chain) > 0 && v.CanSet() {
+			value := v.String()
+			for _, sanitize := range chain {
+				value = sanitize(value)
+			}
+			v.SetString(value)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			s.value(v.Elem(), chain, plan)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			s.value(v.Index(i), chain, plan)
+		}
+	case reflect.Struct:
+		if plan == nil {
+			return
+		}
+		for _, field := range plan.fields {
+			s.value(v.Field(field.index), field.chain, field.nested)
+		}
+	}
+}
+
+// planOf returns the plan of struct type t, nil when it has nothing to sanitize.
+func (s *sanitization) planOf(t reflect.Type) (*sanitizePlan, error) {
+	if cached, ok := s.types.Load(t); ok {
+		return cached.(cachedSanitizePlan).plan, cached.(cachedSanitizePlan).err
+	}
+	plan, err := s.compile(t, map[reflect.Type]*sanitizePlan{})
+	s.types.Store(t, cachedSanitizePlan{plan: plan, err: err})
+	return plan, err
+}
+
+func (s *sanitization) compile(t reflect.Type, visiting map[reflect.Type]*sanitizePlan) (*sanitizePlan, error) {
+	if plan, ok := visiting[t]; ok {
+		return plan, nil
+	}
+	plan := &sanitizePlan{}
+	visiting[t] = plan
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		chain, err := s.chain(field.Tag.Get(TagSanitize))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+
+		held := elemType(field.Type)
+		var nested *sanitizePlan
+		if held.Kind() == reflect.Struct {
+			if nested, err = s.compile(held, visiting); err != nil {
+				return nil, err
+			}
+		}
+		if nested == nil && (held.Kind() != reflect.String || len(chain) == 0) {
+			continue
+		}
+		plan.fields = append(plan.fields, sanitizeField{index: i, chain: chain, nested: nested})
+	}
+
+	if len(plan.fields) == 0 {
+		visiting[t] = nil
+		return nil, nil
+	}
+	return plan, nil
+}
+
+func (s *sanitization) chain(tag string) ([]Sanitizer, error) {
+	if tag == "-" {
+		return nil, nil
+	}
+
+	var chain []Sanitizer
+	if s.config.Normalize {
+		chain = append(chain, norm.NFC.String)
+	}
+	if s.config.TrimSpace {
+		chain = append(chain, strings.TrimSpace)
+	}
+	if tag != "" {
+		for _, name := range strings.Split(tag, ",") {
+			name = strings.TrimSpace(name)
+			sanitize, ok := s.config.Sanitizers[name]
+			if !ok {
+				sanitize, ok = builtinSanitizers[name]
+			}
+			if !ok {
+				return nil, fmt.Errorf("unknown sanitizer %q", name)
+			}
+			chain = append(chain, sanitize)
+		}
+	}
+	// Escaping last, the other sanitizers would see the entities
+	if s.config.EscapeHTML {
+		chain = append(chain, html.EscapeString)
+	}
+	return chain, nil
+}
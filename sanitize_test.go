@@ -0,0 +1,67 @@
+package fiberhandler
+
+import (
+	"reflect"
+	"testing"
+)
+
+type benchAddress struct {
+	Street string `json:"street" sanitize:"trim"`
+	City   string `json:"city" sanitize:"trim,upper"`
+	Zip    string `json:"zip"`
+}
+
+type benchItem struct {
+	SKU      string   `json:"sku" sanitize:"upper"`
+	Quantity int      `json:"quantity"`
+	Tags     []string `json:"tags" sanitize:"lower"`
+}
+
+type benchOrder struct {
+	Customer string        `json:"customer" sanitize:"trim"`
+	Email    string        `json:"email" sanitize:"trim,lower"`
+	Note     string        `json:"note"`
+	Address  benchAddress  `json:"address"`
+	Billing  *benchAddress `json:"billing"`
+	Items    []benchItem   `json:"items"`
+}
+
+func newBenchOrder() *benchOrder {
+	order := &benchOrder{
+		Customer: " Ada Lovelace ",
+		Email:    " Ada@Example.com ",
+		Address:  benchAddress{Street: " 12 Analytical Row ", City: "london"},
+		Billing:  &benchAddress{Street: " 1 Engine Lane ", City: "london"},
+	}
+	for range 5 {
+		order.Items = append(order.Items, benchItem{SKU: "sku-42", Quantity: 2, Tags: []string{"Gift", "Fragile"}})
+	}
+	return order
+}
+
+// BenchmarkSanitize compares the plan compiled once per type with compiling it on every request.
+func BenchmarkSanitize(b *testing.B) {
+	s := &sanitization{config: SanitizeConfig{TrimSpace: true}}
+	t := reflect.TypeFor[benchOrder]()
+
+	b.Run("Cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			plan, err := s.planOf(t)
+			if err != nil {
+				b.Fatal(err)
+			}
+			s.value(reflect.ValueOf(newBenchOrder()), nil, plan)
+		}
+	})
+	b.Run("Uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			plan, err := s.compile(t, map[reflect.Type]*sanitizePlan{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			s.value(reflect.ValueOf(newBenchOrder()), nil, plan)
+		}
+	})
+}
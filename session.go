@@ -0,0 +1,167 @@
+package fiberhandler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	DefaultSessionCookie = "session_id"
+	defaultSessionPrefix = "fiberhandler:session:"
+	defaultSessionTTL    = 24 * time.Hour
+)
+
+// SessionConfig configures NewSessions.
+type SessionConfig struct {
+	// Cookie holds the session ID, default session_id.
+	Cookie string
+
+	// Store keeps the principals by session ID, default an in-memory store. Share a Redis backed Store
+	// between instances. Prefix namespaces the keys, default "fiberhandler:session:".
+	Store  Store
+	Prefix string
+
+	// TTL is the lifetime of a session, default 24h. Sliding extends it on every authenticated request,
+	// in the store and in the Expires of the cookie, which is sent again.
+	TTL     time.Duration
+	Sliding bool
+}
+
+// Sessions authenticates requests by a session ID cookie resolved against a Store holding the principal
+// P of every session. It is the Authenticator of handlers whose claims are P, SessionAuthenticator maps
+// P to other claims. Combine it with WithCookieAuth to validate the CSRF token of unsafe requests.
+type Sessions[P any] struct {
+	config SessionConfig
+}
+
+// NewSessions returns the sessions of principals P.
+func NewSessions[P any](config ...SessionConfig) *Sessions[P] {
+	cfg := SessionConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Cookie == "" {
+		cfg.Cookie = DefaultSessionCookie
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultSessionPrefix
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultSessionTTL
+	}
+	return &Sessions[P]{config: cfg}
+}
+
+// Create starts a session of principal, e.g. at login, and sets its cookie. A new ID is issued on
+// every call so a session ID planted before the login cannot be reused.
+func (s *Sessions[P]) Create(c *fiber.Ctx, principal *P) (string, error) {
+	value, err := json.Marshal(principal)
+	if err != nil {
+		return "", err
+	}
+
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	sessionID := base64.RawURLEncoding.EncodeToString(id)
+	if err := s.config.Store.Set(c.UserContext(), s.config.Prefix+sessionID, value, s.config.TTL); err != nil {
+		return "", err
+	}
+
+	s.setCookie(c, sessionID)
+	return sessionID, nil
+}
+
+// setCookie sets the cookie of the session, expiring with it.
+func (s *Sessions[P]) setCookie(c *fiber.Ctx, sessionID string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     s.config.Cookie,
+		Value:    sessionID,
+		Path:     "/",
+		Expires:  time.Now().Add(s.config.TTL),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// Destroy ends the session of the request, e.g. at logout, and expires its cookie.
+func (s *Sessions[P]) Destroy(c *fiber.Ctx) error {
+	if sessionID := c.Cookies(s.config.Cookie); sessionID != "" {
+		if err := s.config.Store.Delete(c.UserContext(), s.config.Prefix+sessionID); err != nil {
+			return err
+		}
+	}
+	c.ClearCookie(s.config.Cookie)
+	return nil
+}
+
+// Get returns the principal of the session, nil when it does not exist or has expired.
+func (s *Sessions[P]) Get(ctx context.Context, sessionID string) (*P, error) {
+	key := s.config.Prefix + sessionID
+	value, err := s.config.Store.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	principal := new(P)
+	if err := json.Unmarshal(value, principal); err != nil {
+		return nil, err
+	}
+	if s.config.Sliding {
+		if err := s.config.Store.Set(ctx, key, value, s.config.TTL); err != nil {
+			return nil, err
+		}
+	}
+	return principal, nil
+}
+
+// Authenticate implements Authenticator, requests without a valid session are anonymous. A sliding
+// session gets its cookie again with the extended expiry.
+func (s *Sessions[P]) Authenticate(c *fiber.Ctx) (*P, error) {
+	sessionID := c.Cookies(s.config.Cookie)
+	if sessionID == "" {
+		return nil, nil
+	}
+	principal, err := s.Get(c.UserContext(), sessionID)
+	if principal != nil {
+		c.Locals(cookieAuthKey{}, true)
+		if s.config.Sliding {
+			s.setCookie(c, sessionID)
+		}
+	}
+	return principal, err
+}
+
+// SessionAuthenticator authenticates requests by their session, claims maps the stored principal to the
+// claims of the handler:
+//
+//	sessions := fiberhandler.NewSessions[User](fiberhandler.SessionConfig{Store: redisStore})
+//	handle := fiberhandler.NewWithComponents(fiberhandler.Components[Claims]{
+//		Authenticator: fiberhandler.SessionAuthenticator(sessions, func(user *User) (*Claims, error) {
+//			return &Claims{Sub: user.ID, Roles: user.Roles}, nil
+//		}),
+//	})
+func SessionAuthenticator[P, T any](sessions *Sessions[P], claims func(principal *P) (*T, error)) Authenticator[T] {
+	return AuthenticatorFunc[T](func(c *fiber.Ctx) (*T, error) {
+		principal, err := sessions.Authenticate(c)
+		if principal == nil || err != nil {
+			return nil, err
+		}
+		return claims(principal)
+	})
+}
@@ -0,0 +1,149 @@
+package fiberhandler
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/gopkg/streamx"
+)
+
+const defaultFieldsQuery = "fields"
+
+// SparseFieldsetConfig configures WithSparseFieldsets.
+type SparseFieldsetConfig struct {
+	// Query is the query parameter listing the fields, default "fields".
+	Query string
+
+	// Always lists the fields kept whether requested or not, e.g. "id".
+	Always []string
+}
+
+// fieldTree holds the requested fields by name, a nil subtree keeps the whole field.
+type fieldTree map[string]fieldTree
+
+// WithSparseFieldsets prunes successful responses to the comma separated JSON fields of the fields query
+// parameter, dotted for nested objects, e.g. ?fields=id,name,address.city. Arrays are pruned item by
+// item, Paged and CursorPage results item by item. Unknown fields are ignored, responses without the
+// parameter are sent whole.
+func WithSparseFieldsets(config ...SparseFieldsetConfig) Option {
+	cfg := SparseFieldsetConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Query == "" {
+		cfg.Query = defaultFieldsQuery
+	}
+
+	return func(o *options) {
+		o.sparseFieldsets = &cfg
+	}
+}
+
+// fieldset returns the fields requested by the client, nil for the whole response.
+func (s *SparseFieldsetConfig) fieldset(c *fiber.Ctx) fieldTree {
+	query := strings.TrimSpace(c.Query(s.Query))
+	if query == "" {
+		return nil
+	}
+
+	tree := fieldTree{}
+	for _, path := range append(strings.Split(query, ","), s.Always...) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		node := tree
+		names := strings.Split(path, ".")
+		for i, name := range names {
+			child, exists := node[name]
+			if exists && child == nil {
+				// The whole field is already requested
+				break
+			}
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+			if child == nil {
+				child = fieldTree{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+func (s *SparseFieldsetConfig) apply(c *fiber.Ctx, data any) (any, error) {
+	fields := s.fieldset(c)
+	if fields == nil || data == nil {
+		return data, nil
+	}
+	return fields.apply(data)
+}
+
+func (t fieldTree) apply(data any) (any, error) {
+	switch result := data.(type) {
+	case *Result:
+		payload, err := t.apply(result.Data)
+		if err != nil {
+			return nil, err
+		}
+		pruned := *result
+		pruned.Data = payload
+		return &pruned, nil
+	case *RawResult:
+		payload, err := t.apply(result.Data)
+		return Raw(payload), err
+	case *RenderResult, *NDJSONResult, <-chan any, *streamx.Stream, *StreamResult:
+		return result, nil
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if _, ok := data.(linkedPage); ok {
+		// The fields apply to the items, the page keeps its counters
+		if page, ok := value.(map[string]any); ok {
+			page["items"] = t.prune(page["items"])
+		}
+	} else {
+		value = t.prune(value)
+	}
+
+	pruned, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(pruned), nil
+}
+
+func (t fieldTree) prune(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			subtree, ok := t[key]
+			switch {
+			case !ok:
+				delete(v, key)
+			case subtree != nil:
+				v[key] = subtree.prune(item)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = t.prune(item)
+		}
+	}
+	return value
+}
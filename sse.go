@@ -0,0 +1,140 @@
+package fiberhandler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Event is a Server-Sent Event. Data is written as is when it is a string or []byte and JSON encoded otherwise,
+// one data field per line. ID and Event are single fields, an event whose ID or Event holds a CR or LF
+// is rejected and ends the stream.
+type Event struct {
+	ID    string
+	Event string
+	Data  any
+	Retry time.Duration
+}
+
+// SSEFunc produces events until it returns. ctx is cancelled once the client is gone, so producers must
+// select on ctx.Done() when sending.
+type SSEFunc func(ctx context.Context, events chan<- Event) error
+
+// DoSSE runs the request pipeline of Do and streams the events produced by sseFunc as text/event-stream.
+// An error returned by sseFunc after the stream started is sent as an "error" event.
+func (h *apiHandler[T]) DoSSE(c *fiber.Ctx, requestPtr any, validateRequest bool, sseFunc SSEFunc) error {
+	bindContext(c)
+	h.bindLocale(c)
+	defer h.startTrace(c)()
+	defer h.observe(c)()
+	defer h.audit(c)()
+
+	if err := h.limitRate(c); err != nil {
+		return h.SendError(c, err)
+	}
+
+	if err := h.beforeParse(c); err != nil {
+		return h.SendError(c, err)
+	}
+
+	err := h.trace(c, SpanParse, func() error {
+		return h.requestParserIfNeeded(c, requestPtr)
+	})
+	if err != nil {
+		return h.SendError(c, err)
+	}
+
+	if _, err := h.prepareRequest(c, requestPtr, validateRequest); err != nil {
+		return h.SendError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The fiber.Ctx is released once the handler returns, only the user context is used by the writer
+	ctx, cancel := context.WithCancel(c.UserContext())
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		events := make(chan Event)
+		done := make(chan error, 1)
+		go func() {
+			defer close(events)
+			done <- sseFunc(ctx, events)
+		}()
+
+		pump(bufferChan(ctx, cancel, h.options.streamBuffer, events), h.options.heartbeat, func(event Event) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := writeEvent(w, event); err != nil {
+				slog.Error("Failed to send event", h.redactor().errorAttr(err))
+				return err
+			}
+			return nil
+		}, func() error {
+			if _, err := w.WriteString(": ping\n\n"); err != nil {
+				return err
+			}
+			return w.Flush()
+		}, cancel)
+
+		if err := <-done; err != nil && ctx.Err() == nil {
+			slog.Error("Failed to produce events", h.redactor().errorAttr(err))
+			_ = writeEvent(w, Event{Event: "error", Data: err.Error()})
+		}
+	})
+	return nil
+}
+
+// errInvalidEvent rejects the fields that would end their line and inject fields into the stream.
+var errInvalidEvent = errors.New("sse: event id and name must not contain CR or LF")
+
+// sseLines splits the data of an event on the line endings of text/event-stream: CRLF, CR and LF.
+var sseLines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func writeEvent(w *bufio.Writer, event Event) error {
+	if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Event, "\r\n") {
+		return errInvalidEvent
+	}
+	if event.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(w, "event: %s\n", event.Event)
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n", event.Retry.Milliseconds())
+	}
+
+	var data string
+	switch payload := event.Data.(type) {
+	case string:
+		data = payload
+	case []byte:
+		data = string(payload)
+	default:
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	for _, line := range strings.Split(sseLines.Replace(data), "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+
+	if _, err := w.WriteString("\n"); err != nil {
+		return err
+	}
+	return w.Flush()
+}
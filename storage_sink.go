@@ -0,0 +1,190 @@
+package fiberhandler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StorageSink stores uploaded files as objects, implement it with an S3, GCS or local adapter.
+type StorageSink interface {
+	// Put streams size bytes of file under key.
+	Put(ctx context.Context, key string, file io.Reader, size int64, contentType string) error
+
+	// Delete removes the object stored under key, it is called when the request fails after Put.
+	Delete(ctx context.Context, key string) error
+}
+
+// StoredFile is an uploaded file stored by the StorageSink of WithStorageSink.
+type StoredFile struct {
+	Key         string `json:"key"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// StorageRequest is implemented by multipart requests receiving files as stored objects. DoMultipart
+// streams the files of the fields to the sink and sets their StoredFile, instead of binding the
+// *multipart.FileHeader:
+//
+//	func (r *UploadAvatar) StorageFields() map[string]*fiberhandler.StoredFile {
+//		return map[string]*fiberhandler.StoredFile{"avatar": &r.Avatar}
+//	}
+type StorageRequest interface {
+	StorageFields() map[string]*StoredFile
+}
+
+// StorageKeyFunc returns the object key of an uploaded file.
+type StorageKeyFunc func(ctx context.Context, fieldName string, file *multipart.FileHeader) (string, error)
+
+type storageSink struct {
+	sink StorageSink
+	key  StorageKeyFunc
+}
+
+// WithStorageSink stores the files of StorageRequest fields with sink once the request is validated,
+// authorized and inspected by the upload hooks, before doFunc runs. Objects are deleted when the
+// request fails. Keys are returned by key, default "fieldName/random.ext".
+func WithStorageSink(sink StorageSink, key ...StorageKeyFunc) Option {
+	s := &storageSink{sink: sink, key: defaultStorageKey}
+	if len(key) > 0 && key[0] != nil {
+		s.key = key[0]
+	}
+	return func(o *options) {
+		o.storageSink = s
+	}
+}
+
+func defaultStorageKey(_ context.Context, fieldName string, file *multipart.FileHeader) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate storage key: %w", err)
+	}
+	// The extension is client input, keep it only when it is plain
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if len(ext) > 16 || strings.IndexFunc(ext[min(len(ext), 1):], func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) >= 0 {
+		ext = ""
+	}
+	return path.Join(fieldName, hex.EncodeToString(id)+ext), nil
+}
+
+// storeUploads puts files with the sink and sets the StoredFile of their field, it returns the stored
+// keys. Already stored objects are deleted when a file fails.
+func (h *apiHandler[T]) storeUploads(ctx context.Context, req StorageRequest, files map[string]*multipart.FileHeader) ([]string, error) {
+	s := h.options.storageSink
+	if s == nil {
+		return nil, errors.New("DoMultipart: no storage sink, use WithStorageSink")
+	}
+
+	fields := req.StorageFields()
+	keys := make([]string, 0, len(files))
+	for fieldName, file := range files {
+		stored, err := s.put(ctx, fieldName, file)
+		if err != nil {
+			h.discardUploads(ctx, keys)
+			return nil, err
+		}
+		keys = append(keys, stored.Key)
+		if target := fields[fieldName]; target != nil {
+			*target = stored
+		}
+	}
+	return keys, nil
+}
+
+func (s *storageSink) put(ctx context.Context, fieldName string, file *multipart.FileHeader) (StoredFile, error) {
+	key, err := s.key(ctx, fieldName, file)
+	if err != nil {
+		return StoredFile{}, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return StoredFile{}, err
+	}
+	defer src.Close()
+
+	contentType := file.Header.Get(fiber.HeaderContentType)
+	if err := s.sink.Put(ctx, key, src, file.Size, contentType); err != nil {
+		return StoredFile{}, err
+	}
+	return StoredFile{Key: key, Filename: file.Filename, ContentType: contentType, Size: file.Size}, nil
+}
+
+func (h *apiHandler[T]) discardUploads(ctx context.Context, keys []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := h.options.storageSink.sink.Delete(ctx, key); err != nil {
+			slog.Error("Failed to delete stored file", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// LocalSink is a StorageSink writing objects to files under its directory.
+type LocalSink struct {
+	Dir string
+}
+
+// NewLocalSink returns a StorageSink storing objects under dir.
+func NewLocalSink(dir string) *LocalSink {
+	return &LocalSink{Dir: dir}
+}
+
+func (s *LocalSink) path(key string) (string, error) {
+	name := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(name, filepath.Clean(s.Dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return name, nil
+}
+
+// Put implements StorageSink, the file appears once complete.
+func (s *LocalSink) Put(_ context.Context, key string, file io.Reader, _ int64, _ string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Delete implements StorageSink.
+func (s *LocalSink) Delete(_ context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
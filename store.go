@@ -0,0 +1,160 @@
+package fiberhandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound is returned by Store.Get when the key does not exist or has expired.
+var ErrKeyNotFound = errors.New("key not found")
+
+// Store is the key-value storage used by the handler subsystems that keep state
+// across requests. A ttl <= 0 means the key never expires.
+type Store interface {
+	// Get returns the value of key or ErrKeyNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key, replacing any existing value and expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// CompareAndSwap replaces the value of key with new only if the current value equals old.
+	// A nil old means the key must not exist. It reports whether the swap happened.
+	CompareAndSwap(ctx context.Context, key string, old, new []byte, ttl time.Duration) (bool, error)
+
+	// Increment atomically adds delta to the decimal counter stored under key and returns the
+	// new value. A missing key starts at zero and gets ttl; an existing key keeps its expiry.
+	Increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryStore is an in-process Store suitable for single instance deployments and tests. The zero value
+// is an empty store ready to use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	writes  int
+}
+
+const memoryStoreSweepInterval = 1024
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(key, time.Now())
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return bytes.Clone(entry.value), nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(key, bytes.Clone(value), expiresAt(time.Now(), ttl))
+	return nil
+}
+
+func (s *MemoryStore) CompareAndSwap(_ context.Context, key string, old, new []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.lookup(key, now)
+	if old == nil && ok {
+		return false, nil
+	}
+	if old != nil && (!ok || !bytes.Equal(entry.value, old)) {
+		return false, nil
+	}
+
+	s.put(key, bytes.Clone(new), expiresAt(now, ttl))
+	return true, nil
+}
+
+func (s *MemoryStore) Increment(_ context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.lookup(key, now)
+	if !ok {
+		s.put(key, strconv.AppendInt(nil, delta, 10), expiresAt(now, ttl))
+		return delta, nil
+	}
+
+	current, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	current += delta
+	s.put(key, strconv.AppendInt(nil, current, 10), entry.expiresAt)
+	return current, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) lookup(key string, now time.Time) (memoryEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if entry.expired(now) {
+		delete(s.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *MemoryStore) put(key string, value []byte, expiresAt time.Time) {
+	if s.entries == nil {
+		s.entries = map[string]memoryEntry{}
+	}
+	s.entries[key] = memoryEntry{value: value, expiresAt: expiresAt}
+
+	// Expired keys are removed lazily on access, sweep from time to time for keys never read again
+	s.writes++
+	if s.writes%memoryStoreSweepInterval == 0 {
+		now := time.Now()
+		for k, e := range s.entries {
+			if e.expired(now) {
+				delete(s.entries, k)
+			}
+		}
+	}
+}
+
+func expiresAt(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]memoryEntry{},
+	}
+}
@@ -0,0 +1,20 @@
+package fiberhandler_test
+
+import (
+	"testing"
+
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func() fiberhandler.Store {
+		return fiberhandler.NewMemoryStore()
+	})
+}
+
+func TestMemoryStoreZeroValue(t *testing.T) {
+	storetest.Run(t, func() fiberhandler.Store {
+		return &fiberhandler.MemoryStore{}
+	})
+}
@@ -0,0 +1,262 @@
+// Package storetest provides a conformance test suite for fiberhandler.Store implementations.
+//
+//	func TestRedisStore(t *testing.T) {
+//		storetest.Run(t, func() fiberhandler.Store {
+//			return NewRedisStore(client)
+//		})
+//	}
+package storetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prongbang/fiberhandler"
+)
+
+// TTL is the expiry used by the TTL cases, adapters with a coarse expiry resolution can raise it.
+var TTL = 200 * time.Millisecond
+
+// Run verifies the semantics the handler relies on against stores returned by factory.
+// Every case calls factory once and uses keys unique to the run, so a shared backend is fine.
+func Run(t *testing.T, factory func() fiberhandler.Store) {
+	t.Helper()
+
+	prefix := fmt.Sprintf("storetest:%d:", time.Now().UnixNano())
+	cases := []struct {
+		name string
+		run  func(t *testing.T, store fiberhandler.Store, key string)
+	}{
+		{"GetMissing", testGetMissing},
+		{"SetGet", testSetGet},
+		{"SetOverwrite", testSetOverwrite},
+		{"GetReturnsCopy", testGetReturnsCopy},
+		{"SetTTL", testSetTTL},
+		{"SetWithoutTTL", testSetWithoutTTL},
+		{"Delete", testDelete},
+		{"DeleteMissing", testDeleteMissing},
+		{"CompareAndSwapAbsent", testCompareAndSwapAbsent},
+		{"CompareAndSwapMatch", testCompareAndSwapMatch},
+		{"CompareAndSwapMismatch", testCompareAndSwapMismatch},
+		{"CompareAndSwapExpired", testCompareAndSwapExpired},
+		{"CompareAndSwapConcurrent", testCompareAndSwapConcurrent},
+		{"IncrementMissing", testIncrementMissing},
+		{"IncrementExisting", testIncrementExisting},
+		{"IncrementKeepsExpiry", testIncrementKeepsExpiry},
+		{"IncrementConcurrent", testIncrementConcurrent},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, factory(), prefix+tc.name)
+		})
+	}
+}
+
+func testGetMissing(t *testing.T, store fiberhandler.Store, key string) {
+	expectNotFound(t, store, key)
+}
+
+func testSetGet(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("value"), 0)
+	expectValue(t, store, key, []byte("value"))
+}
+
+func testSetOverwrite(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("first"), 0)
+	mustSet(t, store, key, []byte("second"), 0)
+	expectValue(t, store, key, []byte("second"))
+}
+
+func testGetReturnsCopy(t *testing.T, store fiberhandler.Store, key string) {
+	value := []byte("value")
+	mustSet(t, store, key, value, 0)
+	value[0] = 'X'
+
+	got, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got[0] = 'Y'
+	expectValue(t, store, key, []byte("value"))
+}
+
+func testSetTTL(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("value"), TTL)
+	expectValue(t, store, key, []byte("value"))
+
+	time.Sleep(2 * TTL)
+	expectNotFound(t, store, key)
+}
+
+func testSetWithoutTTL(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("value"), TTL)
+	mustSet(t, store, key, []byte("value"), 0)
+
+	time.Sleep(2 * TTL)
+	expectValue(t, store, key, []byte("value"))
+}
+
+func testDelete(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("value"), 0)
+	if err := store.Delete(context.Background(), key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	expectNotFound(t, store, key)
+}
+
+func testDeleteMissing(t *testing.T, store fiberhandler.Store, key string) {
+	if err := store.Delete(context.Background(), key); err != nil {
+		t.Fatalf("Delete of a missing key: %v", err)
+	}
+}
+
+func testCompareAndSwapAbsent(t *testing.T, store fiberhandler.Store, key string) {
+	expectSwap(t, store, key, nil, []byte("first"), true)
+	expectSwap(t, store, key, nil, []byte("second"), false)
+	expectValue(t, store, key, []byte("first"))
+}
+
+func testCompareAndSwapMatch(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("old"), 0)
+	expectSwap(t, store, key, []byte("old"), []byte("new"), true)
+	expectValue(t, store, key, []byte("new"))
+}
+
+func testCompareAndSwapMismatch(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("current"), 0)
+	expectSwap(t, store, key, []byte("other"), []byte("new"), false)
+	expectValue(t, store, key, []byte("current"))
+
+	expectSwap(t, store, key+":missing", []byte("other"), []byte("new"), false)
+	expectNotFound(t, store, key+":missing")
+}
+
+func testCompareAndSwapExpired(t *testing.T, store fiberhandler.Store, key string) {
+	mustSet(t, store, key, []byte("value"), TTL)
+	time.Sleep(2 * TTL)
+	expectSwap(t, store, key, nil, []byte("fresh"), true)
+	expectValue(t, store, key, []byte("fresh"))
+}
+
+func testCompareAndSwapConcurrent(t *testing.T, store fiberhandler.Store, key string) {
+	const workers = 16
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := store.CompareAndSwap(context.Background(), key, nil, []byte(fmt.Sprint(i)), 0)
+			if err != nil {
+				t.Errorf("CompareAndSwap: %v", err)
+				return
+			}
+			if ok {
+				wins.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := wins.Load(); got != 1 {
+		t.Fatalf("CompareAndSwap on an absent key succeeded %d times, want exactly 1", got)
+	}
+}
+
+func testIncrementMissing(t *testing.T, store fiberhandler.Store, key string) {
+	expectIncrement(t, store, key, 5, 0, 5)
+	expectValue(t, store, key, []byte("5"))
+}
+
+func testIncrementExisting(t *testing.T, store fiberhandler.Store, key string) {
+	expectIncrement(t, store, key, 1, 0, 1)
+	expectIncrement(t, store, key, 2, 0, 3)
+	expectIncrement(t, store, key, -4, 0, -1)
+}
+
+func testIncrementKeepsExpiry(t *testing.T, store fiberhandler.Store, key string) {
+	expectIncrement(t, store, key, 1, TTL, 1)
+	time.Sleep(TTL / 2)
+	expectIncrement(t, store, key, 1, 10*TTL, 2)
+
+	time.Sleep(TTL)
+	expectNotFound(t, store, key)
+	expectIncrement(t, store, key, 1, TTL, 1)
+}
+
+func testIncrementConcurrent(t *testing.T, store fiberhandler.Store, key string) {
+	const workers, increments = 8, 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				if _, err := store.Increment(context.Background(), key, 1, 0); err != nil {
+					t.Errorf("Increment: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	expectValue(t, store, key, []byte(fmt.Sprint(workers*increments)))
+}
+
+func mustSet(t *testing.T, store fiberhandler.Store, key string, value []byte, ttl time.Duration) {
+	t.Helper()
+	if err := store.Set(context.Background(), key, value, ttl); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+}
+
+func expectValue(t *testing.T, store fiberhandler.Store, key string, want []byte) {
+	t.Helper()
+	got, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Get = %q, want %q", got, want)
+	}
+}
+
+func expectNotFound(t *testing.T, store fiberhandler.Store, key string) {
+	t.Helper()
+	got, err := store.Get(context.Background(), key)
+	if !errors.Is(err, fiberhandler.ErrKeyNotFound) {
+		t.Fatalf("Get = %q, %v, want ErrKeyNotFound", got, err)
+	}
+}
+
+func expectSwap(t *testing.T, store fiberhandler.Store, key string, old, new []byte, want bool) {
+	t.Helper()
+	ok, err := store.CompareAndSwap(context.Background(), key, old, new, 0)
+	if err != nil {
+		t.Fatalf("CompareAndSwap: %v", err)
+	}
+	if ok != want {
+		t.Fatalf("CompareAndSwap(%q, %q) = %v, want %v", old, new, ok, want)
+	}
+}
+
+func expectIncrement(t *testing.T, store fiberhandler.Store, key string, delta int64, ttl time.Duration, want int64) {
+	t.Helper()
+	got, err := store.Increment(context.Background(), key, delta, ttl)
+	if err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Increment(%d) = %d, want %d", delta, got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package fiberhandler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/gopkg/streamx"
+)
+
+// Disposition is the Content-Disposition type of a streamed file.
+type Disposition string
+
+const (
+	// DispositionAttachment makes browsers download the file.
+	DispositionAttachment Disposition = "attachment"
+
+	// DispositionInline lets browsers render the file, e.g. a PDF or an image.
+	DispositionInline Disposition = "inline"
+)
+
+// StreamResult streams a file with an explicit Content-Disposition and cache validators. A plain
+// *streamx.Stream is always sent as an attachment.
+type StreamResult struct {
+	*streamx.Stream
+	Disposition Disposition
+
+	// ETag and LastModified validate conditional requests, which are answered with 304 Not Modified
+	// without reading the stream.
+	ETag         string
+	LastModified time.Time
+}
+
+// WithETag sets the entity tag of the file, quoted when needed, and returns the result for chaining.
+func (r *StreamResult) WithETag(etag string) *StreamResult {
+	if !strings.HasSuffix(etag, `"`) {
+		etag = `"` + etag + `"`
+	}
+	r.ETag = etag
+	return r
+}
+
+// WithLastModified sets the modification time of the file and returns the result for chaining.
+func (r *StreamResult) WithLastModified(modTime time.Time) *StreamResult {
+	r.LastModified = modTime
+	return r
+}
+
+// Inline streams the file to be rendered in the browser.
+func Inline(stream *streamx.Stream) *StreamResult {
+	return &StreamResult{Stream: stream, Disposition: DispositionInline}
+}
+
+// Attachment streams the file to be downloaded.
+func Attachment(stream *streamx.Stream) *StreamResult {
+	return &StreamResult{Stream: stream, Disposition: DispositionAttachment}
+}
+
+func setStreamHeaders(c *fiber.Ctx, result *StreamResult) {
+	if result.Disposition == "" {
+		streamx.AttachmentHeader(c, result.ContentType, result.Filename)
+		return
+	}
+
+	if result.ContentType != "" {
+		c.Set(fiber.HeaderContentType, result.ContentType)
+	}
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(result.Disposition, result.Filename))
+}
+
+func setValidatorHeaders(c *fiber.Ctx, result *StreamResult) {
+	if result.ETag != "" {
+		c.Set(fiber.HeaderETag, result.ETag)
+	}
+	if !result.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, result.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when absent, against the validators of
+// the result as RFC 9110 specifies for GET and HEAD.
+func notModified(c *fiber.Ctx, result *StreamResult) bool {
+	if c.Method() != http.MethodGet && c.Method() != http.MethodHead {
+		return false
+	}
+
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		return etagMatches(noneMatch, result.ETag)
+	}
+
+	modifiedSince := c.Get(fiber.HeaderIfModifiedSince)
+	if modifiedSince == "" || result.LastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(modifiedSince)
+	if err != nil {
+		return false
+	}
+	return !result.LastModified.Truncate(time.Second).After(since)
+}
+
+// rangeFresh reports whether If-Range, when sent, still matches the file so a partial response is safe.
+// Only strong entity tags and exact dates match.
+func rangeFresh(c *fiber.Ctx, result *StreamResult) bool {
+	ifRange := c.Get(fiber.HeaderIfRange)
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasSuffix(ifRange, `"`) {
+		return !strings.HasPrefix(ifRange, "W/") && ifRange == result.ETag
+	}
+	date, err := http.ParseTime(ifRange)
+	return err == nil && !result.LastModified.IsZero() && result.LastModified.Truncate(time.Second).Equal(date)
+}
+
+// contentDisposition formats the header with an ASCII filename for old clients and the exact filename
+// encoded per RFC 5987 in filename*.
+func contentDisposition(disposition Disposition, filename string) string {
+	if filename == "" {
+		return string(disposition)
+	}
+
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		switch {
+		case r < 0x20 || r > 0x7e || r == '"' || r == '\\':
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	const hex = "0123456789ABCDEF"
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+			continue
+		}
+		encoded.WriteByte('%')
+		encoded.WriteByte(hex[b>>4])
+		encoded.WriteByte(hex[b&0x0f])
+	}
+
+	if fallback.String() == encoded.String() {
+		return string(disposition) + `; filename="` + fallback.String() + `"`
+	}
+	return string(disposition) + `; filename="` + fallback.String() + `"; filename*=UTF-8''` + encoded.String()
+}
+
+// isAttrChar reports whether b may appear unencoded in an RFC 5987 value.
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
@@ -0,0 +1,124 @@
+package fiberhandler
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+)
+
+const defaultStreamBufferSize = 64
+
+// BufferPolicy decides what happens when the stream buffer is full because the client reads slower than
+// the producer sends.
+type BufferPolicy int
+
+const (
+	// BufferBlock makes the producer wait until the client caught up.
+	BufferBlock BufferPolicy = iota
+
+	// BufferDropNewest discards the item being sent, e.g. for progress updates where gaps are fine.
+	BufferDropNewest
+
+	// BufferDropOldest discards the oldest buffered item to make room, e.g. for live prices.
+	BufferDropOldest
+
+	// BufferClose ends the stream and cancels the producer context.
+	BufferClose
+)
+
+type StreamBufferConfig struct {
+	// Size is the number of items buffered between the producer and the client, default 64.
+	Size int
+
+	// Policy applies once the buffer is full, default BufferBlock.
+	Policy BufferPolicy
+}
+
+// WithStreamBuffer buffers SSE events and NDJSON items in a bounded queue between the producer and the
+// client, so a slow client applies backpressure instead of growing memory without bound.
+func WithStreamBuffer(config StreamBufferConfig) Option {
+	if config.Size <= 0 {
+		config.Size = defaultStreamBufferSize
+	}
+	return func(o *options) {
+		o.streamBuffer = &config
+	}
+}
+
+// bufferChan returns a bounded queue fed from in under the policy of config, or in itself when config is
+// nil. in is always drained, cancel is called when the BufferClose policy ends the stream.
+func bufferChan[E any](ctx context.Context, cancel context.CancelFunc, config *StreamBufferConfig, in <-chan E) <-chan E {
+	if config == nil {
+		return in
+	}
+
+	out := make(chan E, config.Size)
+	go func() {
+		defer close(out)
+
+		var dropped int
+		closed := false
+		for item := range in {
+			if closed {
+				continue
+			}
+
+			switch config.Policy {
+			case BufferDropNewest:
+				select {
+				case out <- item:
+				default:
+					dropped++
+				}
+			case BufferDropOldest:
+				for sent := false; !sent; {
+					select {
+					case out <- item:
+						sent = true
+					default:
+						select {
+						case <-out:
+							dropped++
+						default:
+						}
+					}
+				}
+			case BufferClose:
+				select {
+				case out <- item:
+				default:
+					slog.Warn("Stream buffer is full, closing the stream", slog.Int("size", config.Size))
+					closed = true
+					cancel()
+				}
+			default:
+				select {
+				case out <- item:
+				case <-ctx.Done():
+					closed = true
+				}
+			}
+		}
+
+		if dropped > 0 {
+			slog.Warn("Stream buffer dropped items", slog.Int("dropped", dropped))
+		}
+	}()
+	return out
+}
+
+// seqChan runs seq in its own goroutine and sends its items on the returned channel until ctx is done.
+func seqChan[E any](ctx context.Context, seq iter.Seq[E]) <-chan E {
+	items := make(chan E)
+	go func() {
+		defer close(items)
+		for item := range seq {
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return items
+}
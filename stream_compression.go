@@ -0,0 +1,163 @@
+package fiberhandler
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultCompressionMinSize = 1024
+
+// DefaultCompressibleTypes are the content types compressed when StreamCompressionConfig.ContentTypes is
+// empty. Already compressed formats such as images, videos, archives and PDFs are left out.
+var DefaultCompressibleTypes = []string{
+	"text/*",
+	"application/json",
+	"application/xml",
+	"application/javascript",
+	"application/x-ndjson",
+	"image/svg+xml",
+}
+
+type StreamCompressionConfig struct {
+	// MinSize skips streams with a known size below it, default 1024 bytes.
+	MinSize int
+
+	// ContentTypes lists the media types compressed, "type/*" matches a whole type, default DefaultCompressibleTypes.
+	ContentTypes []string
+}
+
+// encodings in order of preference when the client accepts several with the same weight.
+var encodings = map[string]int{"deflate": 1, "gzip": 2, "br": 3}
+
+// WithStreamCompression compresses stream results with brotli, gzip or deflate as negotiated by
+// Accept-Encoding. Compressed responses are sent whole, without range support, and with a weak ETag.
+func WithStreamCompression(config ...StreamCompressionConfig) Option {
+	cfg := StreamCompressionConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = defaultCompressionMinSize
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = DefaultCompressibleTypes
+	}
+
+	return func(o *options) {
+		o.streamCompression = &cfg
+	}
+}
+
+// sendCompressed compresses the stream when the configuration and the client allow it, and reports
+// whether the response was handled.
+func (h *apiHandler[T]) sendCompressed(c *fiber.Ctx, streamData *StreamResult) bool {
+	config := h.options.streamCompression
+	if config == nil {
+		return false
+	}
+
+	c.Vary(fiber.HeaderAcceptEncoding)
+	if len(c.Response().Header.ContentEncoding()) > 0 {
+		return false
+	}
+	if streamData.Size != nil && *streamData.Size < config.MinSize {
+		return false
+	}
+	if !compressible(string(c.Response().Header.ContentType()), config.ContentTypes) {
+		return false
+	}
+
+	encoding := negotiateEncoding(c.Get(fiber.HeaderAcceptEncoding))
+	if encoding == "" {
+		return false
+	}
+
+	// The encoded representation differs byte for byte from the file
+	if etag := c.GetRespHeader(fiber.HeaderETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+		c.Set(fiber.HeaderETag, "W/"+etag)
+	}
+	c.Set(fiber.HeaderContentEncoding, encoding)
+
+	data := streamData.Data
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if closer, ok := data.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		encoder := newEncoder(encoding, w)
+		if _, err := io.Copy(encoder, data); err != nil {
+			slog.Error("Failed to compress stream", slog.String("error", err.Error()))
+		}
+		if err := encoder.Close(); err != nil {
+			slog.Error("Failed to compress stream", slog.String("error", err.Error()))
+		}
+	})
+	return true
+}
+
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	switch encoding {
+	case "br":
+		return brotli.NewWriter(w)
+	case "gzip":
+		return gzip.NewWriter(w)
+	}
+	encoder, _ := flate.NewWriter(w, flate.DefaultCompression)
+	return encoder
+}
+
+// negotiateEncoding returns the supported encoding with the highest weight in Accept-Encoding, empty when
+// none is acceptable.
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestWeight := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if encodings[name] == 0 {
+			continue
+		}
+
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if weight, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		if weight > bestWeight || (weight == bestWeight && encodings[name] > encodings[best]) {
+			best, bestWeight = name, weight
+		}
+	}
+	return best
+}
+
+func compressible(contentType string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WithStrictJSON rejects JSON bodies holding fields the request struct does not declare with a 400
+// listing them, e.g. "Unknown fields: 'nmae', 'address.zipp'", so client typos do not go unnoticed.
+// The top-level token field of the default Authenticator, "token" or the Field of WithTokenLookup, is
+// allowed.
+func WithStrictJSON() Option {
+	return func(o *options) {
+		o.strictJSON = true
+	}
+}
+
+// checkUnknownFields returns a BadRequestError listing the fields of a JSON body unknown to requestPtr.
+// Malformed bodies are left to the body parser.
+func (h *apiHandler[T]) checkUnknownFields(c *fiber.Ctx, requestPtr any) error {
+	if !h.options.strictJSON {
+		return nil
+	}
+	if !isJSON(c) {
+		return nil
+	}
+
+	var body any
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return nil
+	}
+	if object, ok := body.(map[string]any); ok && h.Authenticator == nil {
+		// The token of requests without header is read from the body
+		delete(object, h.tokenField())
+	}
+	unknown := unknownFields(body, reflect.TypeOf(requestPtr), "")
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(unknown))
+	for i, field := range unknown {
+		quoted[i] = "'" + field + "'"
+	}
+	return NewBadRequestError(fmt.Sprintf("Unknown fields: %s", strings.Join(quoted, ", ")))
+}
+
+// unknownFields returns the paths of the object keys of value that t does not declare, in key order.
+func unknownFields(value any, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		if t.Implements(jsonUnmarshalerType) {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		var unknown []string
+		for i, item := range v {
+			unknown = append(unknown, unknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return unknown
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		var unknown []string
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			switch t.Kind() {
+			case reflect.Map:
+				unknown = append(unknown, unknownFields(v[key], t.Elem(), fieldPath)...)
+			case reflect.Struct:
+				field, ok := jsonField(t, key)
+				if !ok {
+					unknown = append(unknown, fieldPath)
+					continue
+				}
+				unknown = append(unknown, unknownFields(v[key], field.Type, fieldPath)...)
+			}
+		}
+		return unknown
+	}
+	return nil
+}
+
+// jsonKey is a field of a struct decoded from JSON, embedded is the struct promoting its fields for
+// untagged embedded structs.
+type jsonKey struct {
+	name     string
+	field    reflect.StructField
+	embedded reflect.Type
+}
+
+// jsonKeys caches the jsonKeys of struct types in field order.
+var jsonKeys sync.Map
+
+func jsonKeysOf(t reflect.Type) []jsonKey {
+	if cached, ok := jsonKeys.Load(t); ok {
+		return cached.([]jsonKey)
+	}
+
+	var keys []jsonKey
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				keys = append(keys, jsonKey{embedded: embedded})
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		keys = append(keys, jsonKey{name: name, field: field})
+	}
+
+	jsonKeys.Store(t, keys)
+	return keys
+}
+
+// jsonField returns the field of struct t decoding the object key, matched like encoding/json: by
+// exact name first, then case-insensitively, including the fields promoted from embedded structs.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var folded *reflect.StructField
+	for _, candidate := range jsonKeysOf(t) {
+		if candidate.embedded != nil {
+			if promoted, ok := jsonField(candidate.embedded, key); ok {
+				return promoted, true
+			}
+			continue
+		}
+		if candidate.name == key {
+			return candidate.field, true
+		}
+		if folded == nil && strings.EqualFold(candidate.name, key) {
+			folded = &candidate.field
+		}
+	}
+	if folded != nil {
+		return *folded, true
+	}
+	return reflect.StructField{}, false
+}
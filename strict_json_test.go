@@ -0,0 +1,40 @@
+package fiberhandler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+// BenchmarkUnknownFields compares the JSON field lookups cached per type with listing the fields of
+// the types on every request.
+func BenchmarkUnknownFields(b *testing.B) {
+	content, err := json.Marshal(newBenchOrder())
+	if err != nil {
+		b.Fatal(err)
+	}
+	var body any
+	if err := json.Unmarshal(content, &body); err != nil {
+		b.Fatal(err)
+	}
+	t := reflect.TypeFor[*benchOrder]()
+
+	b.Run("Cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if unknown := unknownFields(body, t, ""); len(unknown) > 0 {
+				b.Fatal(unknown)
+			}
+		}
+	})
+	b.Run("Uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			jsonKeys.Clear()
+			if unknown := unknownFields(body, t, ""); len(unknown) > 0 {
+				b.Fatal(unknown)
+			}
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package fiberhandler
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderTenantID is the header read by TenantFromHeader by default.
+const HeaderTenantID = "X-Tenant-ID"
+
+// Errors returned by a TenantResolver to reject the request with 403.
+var (
+	ErrUnknownTenant   = errors.New("unknown tenant")
+	ErrTenantSuspended = errors.New("tenant suspended")
+)
+
+// TenantResolver returns the tenant of the request, "" for a request without tenant. It rejects unknown
+// and suspended tenants with ErrUnknownTenant and ErrTenantSuspended, other errors are sent as is.
+type TenantResolver[T any] interface {
+	ResolveTenant(c *fiber.Ctx, claims *T) (string, error)
+}
+
+// TenantResolverFunc adapts a function to TenantResolver.
+type TenantResolverFunc[T any] func(c *fiber.Ctx, claims *T) (string, error)
+
+// ResolveTenant implements TenantResolver.
+func (f TenantResolverFunc[T]) ResolveTenant(c *fiber.Ctx, claims *T) (string, error) {
+	return f(c, claims)
+}
+
+// TenantFromClaims resolves the tenant returned by tenant for the claims of authenticated callers.
+func TenantFromClaims[T any](tenant func(claims *T) string) TenantResolver[T] {
+	return TenantResolverFunc[T](func(_ *fiber.Ctx, claims *T) (string, error) {
+		if claims == nil {
+			return "", nil
+		}
+		return tenant(claims), nil
+	})
+}
+
+// TenantFromHeader resolves the tenant sent in header, default X-Tenant-ID.
+func TenantFromHeader[T any](header ...string) TenantResolver[T] {
+	name := HeaderTenantID
+	if len(header) > 0 {
+		name = header[0]
+	}
+	return TenantResolverFunc[T](func(c *fiber.Ctx, _ *T) (string, error) {
+		return c.Get(name), nil
+	})
+}
+
+// TenantFromSubdomain resolves the tenant from the leftmost subdomain of the host, e.g. acme for
+// acme.example.com. offset is the number of labels of the domain, default 2.
+func TenantFromSubdomain[T any](offset ...int) TenantResolver[T] {
+	return TenantResolverFunc[T](func(c *fiber.Ctx, _ *T) (string, error) {
+		subdomains := c.Subdomains(offset...)
+		if len(subdomains) == 0 {
+			return "", nil
+		}
+		return subdomains[0], nil
+	})
+}
+
+// FirstTenant resolves the tenant of the first resolver returning one.
+func FirstTenant[T any](resolvers ...TenantResolver[T]) TenantResolver[T] {
+	return TenantResolverFunc[T](func(c *fiber.Ctx, claims *T) (string, error) {
+		for _, resolver := range resolvers {
+			tenant, err := resolver.ResolveTenant(c, claims)
+			if err != nil || tenant != "" {
+				return tenant, err
+			}
+		}
+		return "", nil
+	})
+}
+
+// ValidTenant checks the tenant of resolver with check, e.g. against the tenant registry, which returns
+// ErrUnknownTenant or ErrTenantSuspended to reject it.
+func ValidTenant[T any](resolver TenantResolver[T], check func(c *fiber.Ctx, tenant string) error) TenantResolver[T] {
+	return TenantResolverFunc[T](func(c *fiber.Ctx, claims *T) (string, error) {
+		tenant, err := resolver.ResolveTenant(c, claims)
+		if err != nil || tenant == "" {
+			return tenant, err
+		}
+		return tenant, check(c, tenant)
+	})
+}
+
+// TenantRequest is implemented by requests receiving the tenant, embed Tenant to implement it.
+type TenantRequest interface {
+	SetTenant(tenant string)
+}
+
+// Tenant is embedded in requests to receive the tenant of the request.
+type Tenant struct {
+	TenantID string `json:"-" query:"-" form:"-"`
+}
+
+// SetTenant implements TenantRequest.
+func (t *Tenant) SetTenant(tenant string) {
+	t.TenantID = tenant
+}
+
+// WithTenantResolver resolves the tenant of every request once the caller is authenticated, before
+// authorization. The tenant is put in the context, read it with TenantFromContext, and set on requests
+// implementing TenantRequest:
+//
+//	fiberhandler.WithTenantResolver(fiberhandler.FirstTenant(
+//		fiberhandler.TenantFromClaims(func(claims *Claims) string { return claims.Org }),
+//		fiberhandler.TenantFromSubdomain[Claims](),
+//	))
+func WithTenantResolver[T any](resolver TenantResolver[T]) Option {
+	return func(o *options) {
+		o.tenantResolver = func(c *fiber.Ctx, claims any) (string, error) {
+			cl, _ := claims.(*T)
+			return resolver.ResolveTenant(c, cl)
+		}
+	}
+}
+
+func (h *apiHandler[T]) resolveTenant(c *fiber.Ctx, requestPtr any, claims *T) error {
+	if h.options.tenantResolver == nil {
+		return nil
+	}
+
+	tenant, err := h.options.tenantResolver(c, claims)
+	switch {
+	case errors.Is(err, ErrUnknownTenant):
+		err = NewForbiddenError("Unknown tenant")
+	case errors.Is(err, ErrTenantSuspended):
+		err = NewForbiddenError("Tenant suspended")
+	}
+	if err != nil {
+		slog.Error("Rejected tenant", slog.String("tenant", tenant), h.redactor().errorAttr(err))
+		return err
+	}
+	if tenant == "" {
+		return nil
+	}
+
+	c.SetUserContext(ContextWithTenant(c.UserContext(), tenant))
+	if tenantReq, ok := requestPtr.(TenantRequest); ok {
+		tenantReq.SetTenant(tenant)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamReleaseKey holds the function releasing the doFunc context of a lazy result until its stream ends.
+type streamReleaseKey struct{}
+
+// WithTimeout bounds doFunc with a deadline of d, carried by its context with DeadlineServer as source.
+// A doFunc failing with context.DeadlineExceeded once the deadline passed is answered with a
+// TimeoutError (504). The deadline is cooperative: doFunc must pass the context on to its calls.
+// Use With to set the timeout of a route group or a single call:
+//
+//	return handle.With(fiberhandler.WithTimeout(2*time.Second)).Do(c, &req, true, doFunc)
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// startTimeout returns ctx with the deadline of the handler and the function releasing it.
+func (h *apiHandler[T]) startTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if h.options.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ContextWithDeadlineSource(ctx, DeadlineServer), h.options.timeout)
+}
+
+// timeoutError returns a TimeoutError for an error of doFunc caused by the deadline of ctx.
+func timeoutError(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return NewTimeoutError()
+}
+
+// lazyResult reports whether data is produced after doFunc returned, so its context must outlive doFunc.
+func lazyResult(data any) bool {
+	switch data.(type) {
+	case *NDJSONResult, <-chan any:
+		return true
+	}
+	return false
+}
+
+// holdStream keeps release until the lazy result of the request is streamed.
+func holdStream(c *fiber.Ctx, release func()) {
+	c.Locals(streamReleaseKey{}, release)
+}
+
+// takeStream returns the function releasing the doFunc context of the lazy result, which the caller
+// must call once the stream ends. It returns a no-op when there is none.
+func takeStream(c *fiber.Ctx) func() {
+	release, ok := c.Locals(streamReleaseKey{}).(func())
+	if !ok {
+		return func() {}
+	}
+	c.Locals(streamReleaseKey{}, nil)
+	return release
+}
@@ -0,0 +1,86 @@
+package fiberhandler
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/gopkg/core"
+)
+
+const defaultTokenField = "token"
+
+// TokenLookup configures where the default Authenticator finds the token, see WithTokenLookup.
+type TokenLookup struct {
+	// Header holds the token, default Authorization.
+	Header string
+
+	// Scheme precedes the token in the header, e.g. "Bearer" or "Token", compared case-insensitively.
+	// Empty reads the whole header value, e.g. for X-Api-Token.
+	Scheme string
+
+	// Field names the multipart form field, the WebSocket query parameter and the body field holding
+	// the token of requests without header, default "token".
+	Field string
+}
+
+// WithTokenLookup reads the token from the header and field of lookup instead of the bearer token of
+// the Authorization header and the token field:
+//
+//	fiberhandler.WithTokenLookup(fiberhandler.TokenLookup{Header: "X-Api-Token"})
+//	fiberhandler.WithTokenLookup(fiberhandler.TokenLookup{Scheme: "Token", Field: "access_token"})
+func WithTokenLookup(lookup TokenLookup) Option {
+	if lookup.Header == "" {
+		lookup.Header = fiber.HeaderAuthorization
+	}
+	if lookup.Field == "" {
+		lookup.Field = defaultTokenField
+	}
+	return func(o *options) {
+		o.tokenLookup = &lookup
+	}
+}
+
+// headerToken returns the token of the request header.
+func (h *apiHandler[T]) headerToken(c *fiber.Ctx) string {
+	lookup := h.options.tokenLookup
+	if lookup == nil {
+		return core.ExtractToken(core.Authorization(c))
+	}
+
+	value := strings.TrimSpace(c.Get(lookup.Header))
+	if lookup.Scheme == "" {
+		return value
+	}
+	scheme, token, ok := strings.Cut(value, " ")
+	if !ok || !strings.EqualFold(scheme, lookup.Scheme) {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// tokenField returns the name of the form, query and body field holding the token.
+func (h *apiHandler[T]) tokenField() string {
+	if h.options.tokenLookup == nil {
+		return defaultTokenField
+	}
+	return h.options.tokenLookup.Field
+}
+
+// bodyToken returns the token field of the request body.
+func (h *apiHandler[T]) bodyToken(c *fiber.Ctx) string {
+	field := h.tokenField()
+	if field == defaultTokenField {
+		accessToken := core.AccessToken{}
+		_ = c.BodyParser(&accessToken)
+		return accessToken.Token
+	}
+
+	body := tokenBodies.Get().(map[string]any)
+	defer func() {
+		clear(body)
+		tokenBodies.Put(body)
+	}()
+	_ = c.BodyParser(&body)
+	token, _ := body[field].(string)
+	return token
+}
@@ -0,0 +1,75 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+type renameRequest struct {
+	Name string `json:"name"`
+}
+
+func TestStrictJSONTokenField(t *testing.T) {
+	token, err := fiberhandlertest.SignToken(testClaims{Sub: "42"}, fiberhandlertest.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		options       []fiberhandler.Option
+		authenticator fiberhandler.Authenticator[testClaims]
+		body          map[string]any
+		want          int
+	}{
+		{
+			name: "default field",
+			body: map[string]any{"name": "Ada", "token": token},
+			want: http.StatusOK,
+		},
+		{
+			name:    "configured field",
+			options: []fiberhandler.Option{fiberhandler.WithTokenLookup(fiberhandler.TokenLookup{Field: "access_token"})},
+			body:    map[string]any{"name": "Ada", "access_token": token},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "default field once configured",
+			options: []fiberhandler.Option{fiberhandler.WithTokenLookup(fiberhandler.TokenLookup{Field: "access_token"})},
+			body:    map[string]any{"name": "Ada", "token": token},
+			want:    http.StatusBadRequest,
+		},
+		{
+			name:          "custom authenticator",
+			authenticator: headerAuthenticator,
+			body:          map[string]any{"name": "Ada", "token": token},
+			want:          http.StatusBadRequest,
+		},
+		{
+			name: "other fields",
+			body: map[string]any{"name": "Ada", "token": token, "nmae": "Ada"},
+			want: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := fiberhandler.NewWithComponents(
+				fiberhandler.Components[testClaims]{Authenticator: tt.authenticator},
+				append(tt.options, fiberhandler.WithStrictJSON())...,
+			)
+			fiberhandlertest.Post("/profile").JSON(tt.body).
+				Run(t, func(c *fiber.Ctx) error {
+					return handle.Do(c, &renameRequest{}, false, func(ctx context.Context) (any, error) {
+						return nil, nil
+					})
+				}).
+				Status(tt.want)
+		})
+	}
+}
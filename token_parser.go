@@ -3,7 +3,9 @@ package fiberhandler
 import (
 	"encoding/base64"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/prongbang/gopkg/core"
@@ -13,25 +15,103 @@ type TokenParser[T any] interface {
 	ParseToken(tokenString string) (*T, error)
 }
 
-type JWTParser[T any] struct{}
+// JWTValidation configures the validation of the registered claims of NewJWTParser. Failures are
+// TokenErrors rejecting the request with 401 and a code telling the client to refresh or log in again.
+type JWTValidation struct {
+	// Issuers lists the accepted iss, empty accepts any.
+	Issuers []string
 
-func (f *JWTParser[T]) ParseToken(tokenString string) (*T, error) {
-	parts := strings.Split(tokenString, ".")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("invalid JWT format")
+	// Audiences lists the accepted aud, the token must hold one of them. Empty accepts any.
+	Audiences []string
+
+	// RequireExpiry rejects tokens without exp. exp and nbf are checked whenever present.
+	RequireExpiry bool
+
+	// ClockSkew tolerates clocks drifting between the issuer and the service.
+	ClockSkew time.Duration
+
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// registeredClaims are the claims checked by JWTValidation, aud is a string or an array.
+type registeredClaims struct {
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+}
+
+func (v *JWTValidation) validate(payload []byte) error {
+	var claims registeredClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("failed to unmarshal JWT registered claims: %w", err)
 	}
 
-	// Decode payload (second part)
-	payload := parts[1]
-	// Add padding if needed
-	if m := len(payload) % 4; m != 0 {
-		payload += strings.Repeat("=", 4-m)
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if claims.ExpiresAt == nil && v.RequireExpiry {
+		return NewTokenError(ErrTokenExpired)
+	}
+	if claims.ExpiresAt != nil && !now.Before(unixTime(*claims.ExpiresAt).Add(v.ClockSkew)) {
+		return NewTokenError(ErrTokenExpired)
+	}
+	if claims.NotBefore != nil && now.Add(v.ClockSkew).Before(unixTime(*claims.NotBefore)) {
+		return NewTokenError(ErrTokenNotYetValid)
 	}
 
-	decoded, err := base64.URLEncoding.DecodeString(payload)
+	if len(v.Issuers) > 0 && !slices.Contains(v.Issuers, claims.Issuer) {
+		return NewTokenError(ErrInvalidIssuer)
+	}
+	if len(v.Audiences) > 0 {
+		var audiences []string
+		if err := json.Unmarshal(claims.Audience, &audiences); err != nil {
+			var audience string
+			_ = json.Unmarshal(claims.Audience, &audience)
+			audiences = []string{audience}
+		}
+		if !slices.ContainsFunc(audiences, func(audience string) bool {
+			return slices.Contains(v.Audiences, audience)
+		}) {
+			return NewTokenError(ErrInvalidAudience)
+		}
+	}
+	return nil
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+type JWTParser[T any] struct {
+	validation *JWTValidation
+}
+
+func (f *JWTParser[T]) ParseToken(tokenString string) (*T, error) {
+	_, rest, ok := strings.Cut(tokenString, ".")
+	payload, signature, ok2 := strings.Cut(rest, ".")
+	if !ok || !ok2 || strings.Contains(signature, ".") {
+		return nil, fmt.Errorf("invalid JWT format")
+	}
+
+	// Decode payload (second part), with or without padding, into a pooled buffer
+	payload = strings.TrimRight(payload, "=")
+	buf := getTokenBuffer(len(payload) + base64.RawURLEncoding.DecodedLen(len(payload)))
+	defer putTokenBuffer(buf)
+	src := (*buf)[:copy(*buf, payload)]
+	n, err := base64.RawURLEncoding.Decode((*buf)[len(src):], src)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode JWT payload: %w", err)
 	}
+	decoded := (*buf)[len(src) : len(src)+n]
+
+	if f.validation != nil {
+		if err := f.validation.validate(decoded); err != nil {
+			return nil, err
+		}
+	}
 
 	var claims core.Model[T]
 	if err := json.Unmarshal(decoded, &claims.Type); err != nil {
@@ -41,6 +121,12 @@ func (f *JWTParser[T]) ParseToken(tokenString string) (*T, error) {
 	return &claims.Type, nil
 }
 
-func NewJWTParser[T any]() TokenParser[T] {
-	return &JWTParser[T]{}
+// NewJWTParser returns a TokenParser decoding the payload of a JWT into T. With validation, its iss,
+// aud, exp and nbf claims are checked first.
+func NewJWTParser[T any](validation ...JWTValidation) TokenParser[T] {
+	parser := &JWTParser[T]{}
+	if len(validation) > 0 {
+		parser.validation = &validation[0]
+	}
+	return parser
 }
@@ -0,0 +1,113 @@
+package fiberhandler_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+func TestJWTValidation(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	at := func(d time.Duration) int64 { return now.Add(d).Unix() }
+
+	validation := fiberhandler.JWTValidation{
+		Issuers:   []string{"https://issuer.example"},
+		Audiences: []string{"api", "admin"},
+		ClockSkew: 30 * time.Second,
+		Now:       func() time.Time { return now },
+	}
+	valid := func(claims map[string]any) map[string]any {
+		token := map[string]any{"sub": "42", "iss": "https://issuer.example", "aud": "api", "exp": at(time.Hour)}
+		for key, value := range claims {
+			if value == nil {
+				delete(token, key)
+				continue
+			}
+			token[key] = value
+		}
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		validation fiberhandler.JWTValidation
+		claims     map[string]any
+		want       error
+	}{
+		{name: "valid", validation: validation, claims: valid(nil)},
+		{name: "expired", validation: validation, claims: valid(map[string]any{"exp": at(-time.Minute)}), want: fiberhandler.ErrTokenExpired},
+		{name: "expired within skew", validation: validation, claims: valid(map[string]any{"exp": at(-10 * time.Second)})},
+		{name: "expires now", validation: validation, claims: valid(map[string]any{"exp": at(-30 * time.Second)}), want: fiberhandler.ErrTokenExpired},
+		{name: "fractional exp", validation: validation, claims: valid(map[string]any{"exp": float64(at(time.Minute)) + 0.5})},
+		{name: "not yet valid", validation: validation, claims: valid(map[string]any{"nbf": at(time.Minute)}), want: fiberhandler.ErrTokenNotYetValid},
+		{name: "not yet valid within skew", validation: validation, claims: valid(map[string]any{"nbf": at(10 * time.Second)})},
+		{name: "without exp", validation: validation, claims: valid(map[string]any{"exp": nil})},
+		{
+			name:       "required exp",
+			validation: fiberhandler.JWTValidation{RequireExpiry: true, Now: validation.Now},
+			claims:     valid(map[string]any{"exp": nil}),
+			want:       fiberhandler.ErrTokenExpired,
+		},
+		{name: "other issuer", validation: validation, claims: valid(map[string]any{"iss": "https://other.example"}), want: fiberhandler.ErrInvalidIssuer},
+		{name: "without issuer", validation: validation, claims: valid(map[string]any{"iss": nil}), want: fiberhandler.ErrInvalidIssuer},
+		{name: "audience array", validation: validation, claims: valid(map[string]any{"aud": []string{"web", "admin"}})},
+		{name: "other audience", validation: validation, claims: valid(map[string]any{"aud": "web"}), want: fiberhandler.ErrInvalidAudience},
+		{name: "other audiences", validation: validation, claims: valid(map[string]any{"aud": []string{"web", "mobile"}}), want: fiberhandler.ErrInvalidAudience},
+		{name: "without audience", validation: validation, claims: valid(map[string]any{"aud": nil}), want: fiberhandler.ErrInvalidAudience},
+		{
+			name:       "any issuer and audience",
+			validation: fiberhandler.JWTValidation{Now: validation.Now},
+			claims:     valid(map[string]any{"iss": "https://other.example", "aud": "web"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := fiberhandlertest.SignToken(tt.claims, fiberhandlertest.Secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := fiberhandler.NewJWTParser[testClaims](tt.validation).ParseToken(token)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("err = %v, want %v", err, tt.want)
+				}
+				if !errors.Is(err, fiberhandler.ErrUnauthorized) {
+					t.Errorf("err = %v, want an unauthorized TokenError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if claims.Sub != "42" {
+				t.Errorf("sub = %q, want 42", claims.Sub)
+			}
+		})
+	}
+}
+
+func TestJWTParserMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "two segments", token: "a.b"},
+		{name: "four segments", token: "a.b.c.d"},
+		{name: "invalid base64", token: "a.!!!.c"},
+		{name: "invalid json", token: "a.bm90IGpzb24.c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fiberhandler.NewJWTParser[testClaims]().ParseToken(tt.token); err == nil {
+				t.Error("err = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package fiberhandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Span names of the handler stages, children of the request span.
+const (
+	SpanParse    = "fiberhandler.parse"
+	SpanValidate = "fiberhandler.validate"
+	SpanToken    = "fiberhandler.token"
+	SpanDo       = "fiberhandler.do"
+)
+
+// SpanAttribute is a span attribute, Value is a string, bool, int, int64 or float64.
+type SpanAttribute struct {
+	Key   string
+	Value any
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...SpanAttribute)
+
+	// RecordError records err on the span and marks the span as failed.
+	RecordError(err error)
+
+	End()
+}
+
+// Tracer is the tracing backend of WithTracer. The fiberotel module implements it on an OpenTelemetry
+// TracerProvider.
+type Tracer interface {
+	// Extract returns ctx carrying the trace context propagated in the request headers, e.g. W3C traceparent.
+	Extract(ctx context.Context, header func(key string) string) context.Context
+
+	// Start starts a span as a child of the span in ctx and returns the context carrying it.
+	Start(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span)
+}
+
+// WithTracer traces requests with tracer: a request span with the standard HTTP attributes continuing
+// the propagated trace, and child spans around parsing, validation, token parsing and doFunc. The doFunc
+// context carries the request span so spans started by the business code join the trace.
+func WithTracer(tracer Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+// startTrace starts the request span and returns the function ending it.
+func (h *apiHandler[T]) startTrace(c *fiber.Ctx) func() {
+	tracer := h.options.tracer
+	if tracer == nil {
+		return func() {}
+	}
+
+	route := c.Route().Path
+	ctx := tracer.Extract(c.UserContext(), func(key string) string {
+		return c.Get(key)
+	})
+	ctx, span := tracer.Start(ctx, c.Method()+" "+route,
+		SpanAttribute{Key: "http.request.method", Value: c.Method()},
+		SpanAttribute{Key: "http.route", Value: route},
+		SpanAttribute{Key: "url.path", Value: c.Path()},
+		SpanAttribute{Key: "url.scheme", Value: c.Protocol()},
+		SpanAttribute{Key: "server.address", Value: c.Hostname()},
+		SpanAttribute{Key: "client.address", Value: c.IP()},
+		SpanAttribute{Key: "user_agent.original", Value: c.Get(fiber.HeaderUserAgent)},
+	)
+	c.SetUserContext(ctx)
+
+	return func() {
+		status := c.Response().StatusCode()
+		span.SetAttributes(SpanAttribute{Key: "http.response.status_code", Value: status})
+		if status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("%d %s", status, http.StatusText(status)))
+		}
+		span.End()
+	}
+}
+
+// trace runs fn in a child span named name, fn sees the span in the user context.
+func (h *apiHandler[T]) trace(c *fiber.Ctx, name string, fn func() error) error {
+	tracer := h.options.tracer
+	if tracer == nil {
+		return fn()
+	}
+
+	parent := c.UserContext()
+	ctx, span := tracer.Start(parent, name)
+	c.SetUserContext(ctx)
+	defer func() {
+		c.SetUserContext(parent)
+		span.End()
+	}()
+
+	err := fn()
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
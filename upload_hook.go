@@ -0,0 +1,104 @@
+package fiberhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"slices"
+
+	"github.com/prongbang/goerror"
+)
+
+// UploadHook inspects a file accepted by DoMultipart before doFunc runs, e.g. a virus scan, a checksum
+// or an image dimension check. It vetoes the upload with an UploadRejectedError, other errors are sent
+// as is.
+type UploadHook interface {
+	Inspect(ctx context.Context, fieldName string, file *multipart.FileHeader) error
+}
+
+// UploadHookFunc adapts a function to UploadHook.
+type UploadHookFunc func(ctx context.Context, fieldName string, file *multipart.FileHeader) error
+
+// Inspect implements UploadHook.
+func (f UploadHookFunc) Inspect(ctx context.Context, fieldName string, file *multipart.FileHeader) error {
+	return f(ctx, fieldName, file)
+}
+
+// UploadRejectedError is returned by an UploadHook to veto a file, it is sent as an UnprocessableError
+// naming the field and the file.
+type UploadRejectedError struct {
+	goerror.Body
+	Field    string `json:"-"`
+	Filename string `json:"-"`
+	Reason   string `json:"-"`
+}
+
+// Error implements error.
+func (c *UploadRejectedError) Error() string {
+	return c.Message
+}
+
+// Is reports whether target is ErrUnprocessable.
+func (c *UploadRejectedError) Is(target error) bool {
+	return target == ErrUnprocessable
+}
+
+// StatusCode implements StatusCoder.
+func (c *UploadRejectedError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// NewUploadRejectedError returns the error of a hook rejecting a file for reason, e.g. "infected".
+func NewUploadRejectedError(reason string) error {
+	return &UploadRejectedError{
+		Body: goerror.Body{
+			Code:    "CLE035",
+			Message: "File rejected: " + reason,
+		},
+		Reason: reason,
+	}
+}
+
+// WithUploadHooks appends hooks run in order on every file accepted by DoMultipart, bound or stored,
+// after the request is validated and authorized. The first error stops the request.
+func WithUploadHooks(hooks ...UploadHook) Option {
+	return func(o *options) {
+		o.uploadHooks = append(slices.Clip(o.uploadHooks), hooks...)
+	}
+}
+
+func (h *apiHandler[T]) inspectUploads(ctx context.Context, files map[string]*multipart.FileHeader) error {
+	if len(h.options.uploadHooks) == 0 {
+		return nil
+	}
+
+	// Inspect the fields in a stable order, so the same upload is rejected with the same error
+	fieldNames := make([]string, 0, len(files))
+	for fieldName := range files {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	slices.Sort(fieldNames)
+
+	for _, fieldName := range fieldNames {
+		file := files[fieldName]
+		for _, hook := range h.options.uploadHooks {
+			err := hook.Inspect(ctx, fieldName, file)
+			if err == nil {
+				continue
+			}
+
+			var rejected *UploadRejectedError
+			if errors.As(err, &rejected) {
+				rejected.Field = fieldName
+				rejected.Filename = file.Filename
+				rejected.Message = fmt.Sprintf("File '%s' in field '%s' rejected: %s", file.Filename, fieldName, rejected.Reason)
+				slog.Error("Rejected upload", slog.String("field", fieldName), slog.String("reason", rejected.Reason))
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package fiberhandler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderUploadID identifies an upload for progress reporting, the "uploadId" query parameter works too.
+const HeaderUploadID = "X-Upload-ID"
+
+const uploadProgressStep = 64 << 10
+
+// UploadProgress is reported while DoMultipart reads the request body.
+type UploadProgress struct {
+	UploadID string `json:"uploadId"`
+	Read     int64  `json:"read"`
+	Total    int64  `json:"total"`
+	Done     bool   `json:"done"`
+}
+
+// WithUploadProgress reports the progress of multipart uploads to fn, at most every 64KB read.
+// Total is -1 when the client sends no Content-Length. Progress is only meaningful with
+// fiber.Config.StreamRequestBody, otherwise the body has been received before the handler runs.
+func WithUploadProgress(fn func(progress UploadProgress)) Option {
+	return func(o *options) {
+		o.uploadProgress = fn
+	}
+}
+
+func uploadID(c *fiber.Ctx) string {
+	if id := c.Get(HeaderUploadID); id != "" {
+		return id
+	}
+	return c.Query("uploadId")
+}
+
+type progressReader struct {
+	reader   io.Reader
+	progress UploadProgress
+	reported int64
+	report   func(progress UploadProgress)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.progress.Read += int64(n)
+	if err == io.EOF {
+		r.progress.Done = true
+	}
+	if r.progress.Done || r.progress.Read-r.reported >= uploadProgressStep {
+		r.reported = r.progress.Read
+		r.report(r.progress)
+	}
+	return n, err
+}
+
+// UploadTracker keeps the latest progress per upload ID, pass its Report method to WithUploadProgress
+// and mount Handler as the companion endpoint the UI listens to.
+type UploadTracker struct {
+	mu        sync.RWMutex
+	uploads   map[string]trackedUpload
+	swept     time.Time
+	retention time.Duration
+	stale     time.Duration
+	interval  time.Duration
+}
+
+type trackedUpload struct {
+	progress UploadProgress
+	updated  time.Time
+}
+
+// Report records progress, finished uploads are forgotten after the retention and uploads not reporting
+// progress for ten minutes are evicted.
+func (t *UploadTracker) Report(progress UploadProgress) {
+	if progress.UploadID == "" {
+		return
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	t.uploads[progress.UploadID] = trackedUpload{progress: progress, updated: now}
+	if now.Sub(t.swept) >= t.retention {
+		t.swept = now
+		for id, upload := range t.uploads {
+			if now.Sub(upload.updated) >= t.stale {
+				delete(t.uploads, id)
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	if progress.Done {
+		time.AfterFunc(t.retention, func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if current, ok := t.uploads[progress.UploadID]; ok && current.progress.Done {
+				delete(t.uploads, progress.UploadID)
+			}
+		})
+	}
+}
+
+// Progress returns the latest progress of the upload.
+func (t *UploadTracker) Progress(uploadID string) (UploadProgress, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	upload, ok := t.uploads[uploadID]
+	if !ok || time.Since(upload.updated) >= t.stale {
+		return UploadProgress{}, false
+	}
+	return upload.progress, true
+}
+
+// Handler streams the progress of the upload named by the route parameter param as Server-Sent Events
+// until the upload is done, e.g. app.Get("/uploads/:id/progress", tracker.Handler("id")). A comment is
+// sent on the ticks without progress so a gone client is noticed, and the stream ends once the upload
+// has been unknown for the retention.
+func (t *UploadTracker) Handler(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params(param)
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			var last UploadProgress
+			ticker := time.NewTicker(t.interval)
+			defer ticker.Stop()
+
+			seen := time.Now()
+			for now := range ticker.C {
+				progress, ok := t.Progress(id)
+				if ok {
+					seen = now
+				} else if now.Sub(seen) >= t.retention {
+					return
+				}
+
+				if !ok || progress == last {
+					if _, err := w.WriteString(":\n\n"); err != nil {
+						return
+					}
+				} else {
+					last = progress
+					data, _ := json.Marshal(progress)
+					if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data); err != nil {
+						return
+					}
+				}
+				if err := w.Flush(); err != nil || last.Done {
+					return
+				}
+			}
+		})
+		return nil
+	}
+}
+
+func NewUploadTracker() *UploadTracker {
+	return &UploadTracker{
+		uploads:   map[string]trackedUpload{},
+		retention: time.Minute,
+		stale:     10 * time.Minute,
+		interval:  250 * time.Millisecond,
+	}
+}
@@ -0,0 +1,53 @@
+package fiberhandler
+
+import "net/http"
+
+// validationStatusError sends a DataInvalidError with the status chosen by WithValidationStatus, it
+// still matches ErrValidation and AsValidationError.
+type validationStatusError struct {
+	*DataInvalidError
+	status int
+}
+
+// StatusCode implements StatusCoder.
+func (e *validationStatusError) StatusCode() int {
+	return e.status
+}
+
+// Unwrap returns the DataInvalidError.
+func (e *validationStatusError) Unwrap() error {
+	return e.DataInvalidError
+}
+
+// WithValidationStatus sends validation errors with status instead of 400 Bad Request, e.g.
+// http.StatusUnprocessableEntity where API guidelines mandate 422, and with code instead of CLE029.
+func WithValidationStatus(status int, code ...string) Option {
+	return func(o *options) {
+		o.validationStatus = status
+		o.validationCode = ""
+		if len(code) > 0 {
+			o.validationCode = code[0]
+		}
+	}
+}
+
+// validationStatus applies the status and code of WithValidationStatus to a validation error.
+func (h *apiHandler[T]) validationStatus(err error) error {
+	status, code := h.options.validationStatus, h.options.validationCode
+	if status == 0 && code == "" {
+		return err
+	}
+	dataInvalid, ok := AsValidationError(err)
+	if !ok {
+		return err
+	}
+
+	copied := *dataInvalid
+	if code != "" {
+		copied.Code = code
+	}
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	return &validationStatusError{DataInvalidError: &copied, status: status}
+}
@@ -0,0 +1,109 @@
+package fiberhandler
+
+import (
+	"bytes"
+
+	"github.com/goccy/go-json"
+	"github.com/prongbang/gopkg/streamx"
+)
+
+const defaultWatermarkField = "_wm"
+
+type WatermarkConfig struct {
+	// Field is the JSON field added to object responses, default "_wm".
+	Field string
+
+	// Stream watermarks streamed documents (e.g. stamping a PDF or an image), streams are sent as is when nil.
+	Stream func(stream *streamx.Stream, mark string) (*streamx.Stream, error)
+}
+
+type watermark struct {
+	mark   func(claims any) string
+	config WatermarkConfig
+}
+
+// WithWatermark embeds a per-subject mark derived from the claims into successful responses,
+// so leaked exports can be traced back to the account that downloaded them.
+func WithWatermark[T any](mark func(claims *T) string, config ...WatermarkConfig) Option {
+	wm := &watermark{
+		mark: func(claims any) string {
+			c, _ := claims.(*T)
+			return mark(c)
+		},
+	}
+	if len(config) > 0 {
+		wm.config = config[0]
+	}
+	if wm.config.Field == "" {
+		wm.config.Field = defaultWatermarkField
+	}
+
+	return func(o *options) {
+		o.watermark = wm
+	}
+}
+
+// apply adds the mark to data, JSON objects are encoded with marshal to carry the field.
+func (w *watermark) apply(data any, claims any, marshal func(v any) ([]byte, error)) (any, error) {
+	mark := w.mark(claims)
+	if mark == "" || data == nil {
+		return data, nil
+	}
+
+	switch result := data.(type) {
+	case *Result:
+		payload, err := w.apply(result.Data, claims, marshal)
+		if err != nil {
+			return nil, err
+		}
+		marked := *result
+		marked.Data = payload
+		return &marked, nil
+	case *RawResult:
+		payload, err := w.apply(result.Data, claims, marshal)
+		return Raw(payload), err
+	case *RenderResult, *NDJSONResult, <-chan any:
+		return result, nil
+	case *streamx.Stream:
+		if w.config.Stream == nil {
+			return result, nil
+		}
+		return w.config.Stream(result, mark)
+	case *StreamResult:
+		if w.config.Stream == nil {
+			return result, nil
+		}
+		stream, err := w.config.Stream(result.Stream, mark)
+		if err != nil {
+			return nil, err
+		}
+		marked := *result
+		marked.Stream = stream
+		return &marked, nil
+	}
+
+	body, err := marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only JSON objects can carry the extra field, other payloads are left untouched
+	body = bytes.TrimSpace(body)
+	if len(body) < 2 || body[0] != '{' {
+		return data, nil
+	}
+
+	field, err := json.Marshal(map[string]string{w.config.Field: mark})
+	if err != nil {
+		return nil, err
+	}
+
+	marked := make([]byte, 0, len(body)+len(field))
+	marked = append(marked, field[:len(field)-1]...)
+	if rest := bytes.TrimSpace(body[1:]); len(rest) > 0 && rest[0] != '}' {
+		marked = append(marked, ',')
+	}
+	marked = append(marked, body[1:]...)
+
+	return json.RawMessage(marked), nil
+}
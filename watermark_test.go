@@ -0,0 +1,84 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+type export struct {
+	Rows []string `json:"rows"`
+}
+
+// recordingCodec encodes like encoding/json and records the values it encoded.
+type recordingCodec struct {
+	encoded []string
+}
+
+func (c *recordingCodec) Marshal(v any) ([]byte, error) {
+	body, err := fiberhandler.StdJSON.Marshal(v)
+	c.encoded = append(c.encoded, string(body))
+	return body, err
+}
+
+func (c *recordingCodec) Unmarshal(data []byte, v any) error {
+	return fiberhandler.StdJSON.Unmarshal(data, v)
+}
+
+func TestWatermark(t *testing.T) {
+	mark := func(claims *testClaims) string {
+		if claims == nil {
+			return ""
+		}
+		return "sub-" + claims.Sub
+	}
+
+	tests := []struct {
+		name string
+		data any
+		user string
+		want string
+	}{
+		{name: "object", data: export{Rows: []string{"a"}}, user: "42", want: `{"_wm":"sub-42","rows":["a"]}`},
+		{name: "empty object", data: export{}, user: "42", want: `{"_wm":"sub-42","rows":null}`},
+		{name: "result", data: fiberhandler.OK(export{Rows: []string{"a"}}), user: "42", want: `{"_wm":"sub-42","rows":["a"]}`},
+		{name: "array", data: []export{{Rows: []string{"a"}}}, user: "42", want: `[{"rows":["a"]}]`},
+		{name: "anonymous", data: export{Rows: []string{"a"}}, want: `{"rows":["a"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := &recordingCodec{}
+			handle := fiberhandler.NewWithComponents(
+				fiberhandler.Components[testClaims]{Authenticator: headerAuthenticator},
+				fiberhandler.WithJSONCodec(codec),
+				fiberhandler.WithWatermark(mark),
+			)
+			response := fiberhandlertest.Get("/export").Header("X-User", tt.user).
+				Run(t, func(c *fiber.Ctx) error {
+					return handle.Do(c, &struct{}{}, false, func(ctx context.Context) (any, error) {
+						return tt.data, nil
+					})
+				}).
+				Status(http.StatusOK)
+
+			var envelope struct {
+				Data any `json:"data"`
+			}
+			response.JSON(&envelope)
+			data, _ := fiberhandler.StdJSON.Marshal(envelope.Data)
+			if string(data) != tt.want {
+				t.Errorf("data = %s, want %s", data, tt.want)
+			}
+			// The document is encoded by the codec, whether it is marked or not
+			if len(codec.encoded) == 0 || !strings.Contains(codec.encoded[0], `"rows":`) {
+				t.Errorf("codec encoded %q, want the data", codec.encoded)
+			}
+		})
+	}
+}
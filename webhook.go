@@ -0,0 +1,272 @@
+package fiberhandler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	webhookPrefix           = "fiberhandler:webhook:"
+	defaultWebhookTolerance = 5 * time.Minute
+	defaultWebhookReplayTTL = 24 * time.Hour
+	defaultSignatureHeader  = "X-Signature"
+)
+
+// ErrInvalidSignature is returned by verifiers for a missing or mismatching signature.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+var errWebhookNotProcessed = errors.New("webhook not processed")
+
+// WebhookDelivery identifies a verified delivery. A delivery is accepted once per Signature, or per ID
+// when the verifier has no signature to report, and rejected once its Timestamp is older than the
+// tolerance. The ID should be covered by the verified signature when it is the replay key, an unsigned
+// header can be changed by anyone resending a captured delivery.
+type WebhookDelivery struct {
+	ID        string
+	Signature string
+	Timestamp time.Time
+}
+
+func (d WebhookDelivery) replayKey() string {
+	if d.Signature != "" {
+		return d.Signature
+	}
+	return d.ID
+}
+
+// WebhookVerifier verifies the signature of a webhook over its raw body.
+type WebhookVerifier interface {
+	Verify(c *fiber.Ctx, body []byte) (WebhookDelivery, error)
+}
+
+// WebhookVerifierFunc adapts a function to WebhookVerifier.
+type WebhookVerifierFunc func(c *fiber.Ctx, body []byte) (WebhookDelivery, error)
+
+// Verify implements WebhookVerifier.
+func (f WebhookVerifierFunc) Verify(c *fiber.Ctx, body []byte) (WebhookDelivery, error) {
+	return f(c, body)
+}
+
+// HMACConfig configures HMACVerifier.
+type HMACConfig struct {
+	Secret []byte
+
+	// Header holds the hex encoded signature, default X-Signature, after Prefix, e.g. "sha256=".
+	Header string
+	Prefix string
+
+	// Hash defaults to sha256.New.
+	Hash func() hash.Hash
+
+	// IDHeader holds the delivery ID, e.g. X-GitHub-Delivery, deliveries without it are rejected. The
+	// header is not signed, replays are detected by the signature.
+	IDHeader string
+
+	// TimestampHeader holds the Unix time of the delivery. When set, the signed payload is
+	// "timestamp.body".
+	TimestampHeader string
+}
+
+// HMACVerifier verifies a hex encoded HMAC of the raw body sent in a header.
+func HMACVerifier(config HMACConfig) WebhookVerifier {
+	if config.Header == "" {
+		config.Header = defaultSignatureHeader
+	}
+	if config.Hash == nil {
+		config.Hash = sha256.New
+	}
+
+	return WebhookVerifierFunc(func(c *fiber.Ctx, body []byte) (WebhookDelivery, error) {
+		signature, ok := strings.CutPrefix(c.Get(config.Header), config.Prefix)
+		if !ok || signature == "" {
+			return WebhookDelivery{}, ErrInvalidSignature
+		}
+
+		delivery := WebhookDelivery{ID: signature, Signature: signature}
+		if config.IDHeader != "" {
+			delivery.ID = c.Get(config.IDHeader)
+			if delivery.ID == "" {
+				return WebhookDelivery{}, ErrInvalidSignature
+			}
+		}
+		payload := body
+		if config.TimestampHeader != "" {
+			timestamp := c.Get(config.TimestampHeader)
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return WebhookDelivery{}, ErrInvalidSignature
+			}
+			delivery.Timestamp = time.Unix(seconds, 0)
+			payload = append([]byte(timestamp+"."), body...)
+		}
+
+		if !validHMAC(config.Hash, config.Secret, payload, signature) {
+			return WebhookDelivery{}, ErrInvalidSignature
+		}
+		return delivery, nil
+	})
+}
+
+// GitHubVerifier verifies the X-Hub-Signature-256 header of GitHub webhooks.
+func GitHubVerifier(secret []byte) WebhookVerifier {
+	return HMACVerifier(HMACConfig{
+		Secret:   secret,
+		Header:   "X-Hub-Signature-256",
+		Prefix:   "sha256=",
+		IDHeader: "X-GitHub-Delivery",
+	})
+}
+
+// StripeVerifier verifies the Stripe-Signature header of Stripe webhooks, "t=timestamp,v1=signature",
+// any of several v1 signatures may match.
+func StripeVerifier(secret []byte) WebhookVerifier {
+	return WebhookVerifierFunc(func(c *fiber.Ctx, body []byte) (WebhookDelivery, error) {
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(c.Get("Stripe-Signature"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return WebhookDelivery{}, ErrInvalidSignature
+		}
+		payload := append([]byte(timestamp+"."), body...)
+		for _, signature := range signatures {
+			if validHMAC(sha256.New, secret, payload, signature) {
+				return WebhookDelivery{ID: signature, Signature: signature, Timestamp: time.Unix(seconds, 0)}, nil
+			}
+		}
+		return WebhookDelivery{}, ErrInvalidSignature
+	})
+}
+
+func validHMAC(hashFunc func() hash.Hash, secret, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(hashFunc, secret)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// WebhookConfig configures NewWebhook.
+type WebhookConfig struct {
+	Verifier WebhookVerifier
+
+	// Tolerance is the maximum age of a delivery with a timestamp, default 5 minutes.
+	Tolerance time.Duration
+
+	// Store remembers the delivery signatures to reject replays, default an in-memory store. They are
+	// kept for twice the tolerance, or 24h for deliveries without timestamp.
+	Store Store
+}
+
+// Webhook is a webhook receiver for DoWebhook.
+type Webhook struct {
+	config WebhookConfig
+}
+
+// NewWebhook returns a webhook receiver verifying deliveries with config.Verifier.
+func NewWebhook(config WebhookConfig) *Webhook {
+	if config.Tolerance <= 0 {
+		config.Tolerance = defaultWebhookTolerance
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	return &Webhook{config: config}
+}
+
+// verify checks the signature, the age and the uniqueness of the delivery. The returned function is
+// called with the doFunc error and releases the delivery when it failed, so the provider can retry.
+// A delivery the verifier cannot identify is rejected, it could be replayed unnoticed.
+func (w *Webhook) verify(c *fiber.Ctx) (func(err error), error) {
+	delivery, err := w.config.Verifier.Verify(c, c.Body())
+	if err != nil {
+		return nil, NewUnauthorizedError("Invalid webhook signature")
+	}
+
+	ttl := defaultWebhookReplayTTL
+	if !delivery.Timestamp.IsZero() {
+		age := time.Since(delivery.Timestamp)
+		if age > w.config.Tolerance || age < -w.config.Tolerance {
+			return nil, NewUnauthorizedError("Webhook delivery expired")
+		}
+		ttl = 2 * w.config.Tolerance
+	}
+	if delivery.replayKey() == "" {
+		return nil, NewUnauthorizedError("Unidentified webhook delivery")
+	}
+
+	key := webhookPrefix + delivery.replayKey()
+	ctx := c.UserContext()
+	reserved, err := w.config.Store.CompareAndSwap(ctx, key, nil, []byte("1"), ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, NewConflictError("Webhook delivery already received")
+	}
+	return func(err error) {
+		if err == nil {
+			return
+		}
+		if deleteErr := w.config.Store.Delete(context.WithoutCancel(ctx), key); deleteErr != nil {
+			slog.Error("Failed to release webhook delivery", slog.String("error", deleteErr.Error()))
+		}
+	}, nil
+}
+
+// DoWebhook verifies the signature of the raw body with webhook before running the pipeline of Do.
+// Unsigned, expired and tampered deliveries are answered with 401, replayed deliveries with 409. A
+// delivery whose doFunc failed may be retried by the provider:
+//
+//	github := fiberhandler.NewWebhook(fiberhandler.WebhookConfig{Verifier: fiberhandler.GitHubVerifier(secret)})
+//	app.Post("/webhooks/github", func(c *fiber.Ctx) error {
+//		event := PushEvent{}
+//		return handle.DoWebhook(c, github, &event, true, func(ctx context.Context) (any, error) {
+//			return nil, builds.Trigger(ctx, event)
+//		})
+//	})
+func (h *apiHandler[T]) DoWebhook(c *fiber.Ctx, webhook *Webhook, requestPtr any, validateRequest bool, doFunc DoFunc) error {
+	if err := h.limitRate(c); err != nil {
+		return h.SendError(c, err)
+	}
+
+	done, err := webhook.verify(c)
+	if err != nil {
+		slog.Error("Rejected webhook", h.redactor().errorAttr(err))
+		return h.SendError(c, err)
+	}
+
+	processed := false
+	sendErr := h.Do(c, requestPtr, validateRequest, func(ctx context.Context) (any, error) {
+		data, err := doFunc(ctx)
+		processed = true
+		done(err)
+		return data, err
+	})
+	if !processed {
+		// Rejected before doFunc or panicked
+		done(errWebhookNotProcessed)
+	}
+	return sendErr
+}
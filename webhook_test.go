@@ -0,0 +1,158 @@
+package fiberhandler_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prongbang/fiberhandler"
+	"github.com/prongbang/fiberhandler/fiberhandlertest"
+)
+
+type testClaims struct {
+	Sub string `json:"sub"`
+}
+
+type pushEvent struct {
+	Ref string `json:"ref"`
+}
+
+var webhookSecret = []byte("webhook-secret")
+
+func sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookHandler(webhook *fiberhandler.Webhook, doFunc fiberhandler.DoFunc) fiber.Handler {
+	handle := fiberhandler.NewWithComponents(fiberhandler.Components[testClaims]{})
+	return func(c *fiber.Ctx) error {
+		return handle.DoWebhook(c, webhook, &pushEvent{}, true, doFunc)
+	}
+}
+
+func accept(context.Context) (any, error) { return nil, nil }
+
+func TestHMACVerifier(t *testing.T) {
+	body := `{"ref":"main"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name     string
+		verifier fiberhandler.WebhookVerifier
+		header   map[string]string
+		want     int
+	}{
+		{
+			name:     "valid",
+			verifier: fiberhandler.GitHubVerifier(webhookSecret),
+			header:   map[string]string{"X-Hub-Signature-256": "sha256=" + sign(webhookSecret, body), "X-GitHub-Delivery": "1"},
+			want:     http.StatusOK,
+		},
+		{
+			name:     "missing signature",
+			verifier: fiberhandler.GitHubVerifier(webhookSecret),
+			header:   map[string]string{"X-GitHub-Delivery": "1"},
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:     "wrong secret",
+			verifier: fiberhandler.GitHubVerifier(webhookSecret),
+			header:   map[string]string{"X-Hub-Signature-256": "sha256=" + sign([]byte("other"), body), "X-GitHub-Delivery": "1"},
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:     "missing prefix",
+			verifier: fiberhandler.GitHubVerifier(webhookSecret),
+			header:   map[string]string{"X-Hub-Signature-256": sign(webhookSecret, body), "X-GitHub-Delivery": "1"},
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:     "missing delivery id",
+			verifier: fiberhandler.GitHubVerifier(webhookSecret),
+			header:   map[string]string{"X-Hub-Signature-256": "sha256=" + sign(webhookSecret, body)},
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:     "timestamp",
+			verifier: fiberhandler.HMACVerifier(fiberhandler.HMACConfig{Secret: webhookSecret, TimestampHeader: "X-Timestamp"}),
+			header:   map[string]string{"X-Signature": sign(webhookSecret, now+"."+body), "X-Timestamp": now},
+			want:     http.StatusOK,
+		},
+		{
+			name:     "timestamp not signed",
+			verifier: fiberhandler.HMACVerifier(fiberhandler.HMACConfig{Secret: webhookSecret, TimestampHeader: "X-Timestamp"}),
+			header:   map[string]string{"X-Signature": sign(webhookSecret, body), "X-Timestamp": now},
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:     "expired",
+			verifier: fiberhandler.HMACVerifier(fiberhandler.HMACConfig{Secret: webhookSecret, TimestampHeader: "X-Timestamp"}),
+			header:   map[string]string{"X-Signature": sign(webhookSecret, old+"."+body), "X-Timestamp": old},
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:     "stripe",
+			verifier: fiberhandler.StripeVerifier(webhookSecret),
+			header:   map[string]string{"Stripe-Signature": "t=" + now + ",v1=" + sign([]byte("rotated"), now+"."+body) + ",v1=" + sign(webhookSecret, now+"."+body)},
+			want:     http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := fiberhandler.NewWebhook(fiberhandler.WebhookConfig{Verifier: tt.verifier})
+			request := fiberhandlertest.Post("/webhook").Body(fiber.MIMEApplicationJSON, []byte(body))
+			for key, value := range tt.header {
+				request.Header(key, value)
+			}
+			request.Run(t, webhookHandler(webhook, accept)).Status(tt.want)
+		})
+	}
+}
+
+func TestWebhookReplay(t *testing.T) {
+	body := []byte(`{"ref":"main"}`)
+	signature := "sha256=" + sign(webhookSecret, string(body))
+
+	tests := []struct {
+		name   string
+		doFunc fiberhandler.DoFunc
+		second string
+		want   int
+	}{
+		{name: "same delivery", doFunc: accept, second: "1", want: http.StatusConflict},
+		{name: "new delivery id", doFunc: accept, second: "2", want: http.StatusConflict},
+		{
+			name:   "retry after failure",
+			doFunc: func(context.Context) (any, error) { return nil, errors.New("unavailable") },
+			second: "1",
+			want:   http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := fiberhandler.NewWebhook(fiberhandler.WebhookConfig{Verifier: fiberhandler.GitHubVerifier(webhookSecret)})
+			deliver := func(id string, doFunc fiberhandler.DoFunc) *fiberhandlertest.Response {
+				return fiberhandlertest.Post("/webhook").
+					Body(fiber.MIMEApplicationJSON, body).
+					Header("X-Hub-Signature-256", signature).
+					Header("X-GitHub-Delivery", id).
+					Run(t, webhookHandler(webhook, doFunc))
+			}
+
+			deliver("1", tt.doFunc)
+			deliver(tt.second, accept).Status(tt.want)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DoWebSocket runs the request pipeline of Do, including token parsing and route authorization, before
+// upgrading the connection with upgrade and handing it to handler with the claims. upgrade is the
+// constructor of the websocket middleware, e.g. websocket.New from github.com/gofiber/contrib/websocket:
+//
+//	app.Get("/ws", func(c *fiber.Ctx) error {
+//		req := WsRequest{}
+//		return fiberhandler.DoWebSocket(handle, c, &req, true, websocket.New, func(conn *websocket.Conn, claims *Claims) {
+//			...
+//		})
+//	})
+//
+// Browsers cannot set headers on WebSocket requests, the token is also read from the "token" query parameter
+// (the Field of WithTokenLookup).
+func DoWebSocket[T any, Conn any, Config any](h ApiHandler, c *fiber.Ctx, requestPtr any, validateRequest bool, upgrade func(handler func(Conn), config ...Config) fiber.Handler, handler func(conn Conn, claims *T)) error {
+	api, ok := h.(*apiHandler[T])
+	if !ok {
+		return fmt.Errorf("DoWebSocket: the handler was not created with New[%T]", *new(T))
+	}
+
+	if err := api.limitRate(c); err != nil {
+		return api.SendError(c, err)
+	}
+
+	if requestPtr != nil {
+		if err := api.requestParserIfNeeded(c, requestPtr); err != nil {
+			return api.SendError(c, err)
+		}
+	}
+
+	claims, err := api.prepareRequest(c, requestPtr, validateRequest)
+	if err != nil {
+		return api.SendError(c, err)
+	}
+	if claims == nil {
+		// Lazy claims are parsed before the upgrade, the connection outlives the fiber.Ctx
+		if claims, err = resolveClaims[T](c.UserContext()); err != nil {
+			return api.SendError(c, err)
+		}
+	}
+
+	return upgrade(func(conn Conn) {
+		handler(conn, claims)
+	})(c)
+}
+
+func isWebSocketUpgrade(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket")
+}
@@ -0,0 +1,190 @@
+package fiberhandler
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"time"
+
+	"github.com/prongbang/gopkg/streamx"
+	"github.com/xuri/excelize/v2"
+)
+
+// defaultDateTimeFormat is the built-in Excel format of time.Time cells without a Format.
+const defaultDateTimeFormat = 22
+
+// XLSXColumn is a typed column of a sheet.
+type XLSXColumn[R any] struct {
+	Header string
+
+	// Value returns the cell of the row: a string, a number, a bool, a time.Time or nil.
+	Value func(row R) any
+
+	// Width is the width of the column in characters, Excel's default when zero.
+	Width float64
+
+	// Format is the Excel number format of the cells, e.g. "#,##0.00" or "yyyy-mm-dd".
+	Format string
+}
+
+// XLSXSheet is a sheet of an XLSX workbook, built with Sheet.
+type XLSXSheet struct {
+	name  string
+	write func(file *excelize.File, sheet string) error
+}
+
+// Sheet returns a sheet named name writing the rows of seq with columns. Without columns, the exported
+// fields of struct rows are the columns, named by their csv tag. A row that is an error ends the workbook.
+func Sheet[R any](name string, rows iter.Seq[R], columns ...XLSXColumn[R]) XLSXSheet {
+	if len(columns) == 0 {
+		for _, column := range csvColumnsOf(reflect.TypeFor[R]()) {
+			index := column.index
+			columns = append(columns, XLSXColumn[R]{
+				Header: column.name,
+				Value: func(row R) any {
+					v := reflect.ValueOf(row)
+					for v.Kind() == reflect.Pointer && !v.IsNil() {
+						v = v.Elem()
+					}
+					field, err := v.FieldByIndexErr(index)
+					if err != nil {
+						return nil
+					}
+					return xlsxValue(field)
+				},
+			})
+		}
+	}
+
+	return XLSXSheet{
+		name: name,
+		write: func(file *excelize.File, sheet string) error {
+			return writeSheet(file, sheet, rows, columns)
+		},
+	}
+}
+
+// XLSX streams the sheets as an XLSX attachment named filename. Rows are written to the sheets as they
+// are produced, excelize spills them to temporary files past a few MB instead of keeping the workbook in
+// memory:
+//
+//	return fiberhandler.XLSX("report.xlsx",
+//		fiberhandler.Sheet("Orders", orders.All(ctx),
+//			fiberhandler.XLSXColumn[Order]{Header: "ID", Value: func(o Order) any { return o.ID }},
+//			fiberhandler.XLSXColumn[Order]{Header: "Total", Value: func(o Order) any { return o.Total }, Format: "#,##0.00"},
+//		),
+//		fiberhandler.Sheet("Refunds", refunds.All(ctx)),
+//	), nil
+func XLSX(filename string, sheets ...XLSXSheet) *StreamResult {
+	reader := &pipeReader{write: func(w io.Writer) error {
+		return writeWorkbook(w, sheets)
+	}}
+	return Attachment(&streamx.Stream{Data: reader, ContentType: streamx.ContentTypeXlsx, Filename: filename})
+}
+
+func writeWorkbook(w io.Writer, sheets []XLSXSheet) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	defaultSheet := file.GetSheetName(0)
+	for i, sheet := range sheets {
+		if i == 0 {
+			if err := file.SetSheetName(defaultSheet, sheet.name); err != nil {
+				return err
+			}
+		} else if _, err := file.NewSheet(sheet.name); err != nil {
+			return err
+		}
+		if err := sheet.write(file, sheet.name); err != nil {
+			return fmt.Errorf("sheet %s: %w", sheet.name, err)
+		}
+	}
+	return file.Write(w)
+}
+
+func writeSheet[R any](file *excelize.File, sheet string, rows iter.Seq[R], columns []XLSXColumn[R]) error {
+	writer, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	dateStyle, err := file.NewStyle(&excelize.Style{NumFmt: defaultDateTimeFormat})
+	if err != nil {
+		return err
+	}
+	styles := make([]int, len(columns))
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		if column.Width > 0 {
+			if err := writer.SetColWidth(i+1, i+1, column.Width); err != nil {
+				return err
+			}
+		}
+		if column.Format != "" {
+			format := column.Format
+			if styles[i], err = file.NewStyle(&excelize.Style{CustomNumFmt: &format}); err != nil {
+				return err
+			}
+		}
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: column.Header}
+	}
+	if err := writer.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	line := 2
+	for row := range rows {
+		if err, ok := any(row).(error); ok {
+			return err
+		}
+		cells := make([]any, len(columns))
+		for i, column := range columns {
+			value := column.Value(row)
+			style := styles[i]
+			if _, ok := value.(time.Time); ok && style == 0 {
+				style = dateStyle
+			}
+			cells[i] = excelize.Cell{StyleID: style, Value: value}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, line)
+		if err != nil {
+			return err
+		}
+		if err := writer.SetRow(cell, cells); err != nil {
+			return err
+		}
+		line++
+	}
+	return writer.Flush()
+}
+
+// xlsxValue returns the cell of a struct field, strings for the types excelize does not know.
+func xlsxValue(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t
+	}
+	return csvValue(v)
+}
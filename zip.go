@@ -0,0 +1,124 @@
+package fiberhandler
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prongbang/gopkg/streamx"
+)
+
+// MIMEApplicationZip is the media type of zip archives.
+const MIMEApplicationZip = "application/zip"
+
+// ZipEntry is a file of a zip archive, opened when the archive reaches it.
+type ZipEntry struct {
+	// Name is the path of the file in the archive, made relative and unique.
+	Name string
+
+	// Open returns the content of the file, closed once copied.
+	Open func() (io.ReadCloser, error)
+
+	// Modified defaults to the time the archive is written.
+	Modified time.Time
+
+	// Store writes the file uncompressed, for already compressed files such as images or videos.
+	Store bool
+}
+
+// ZipStream returns an entry of the archive with the content and filename of stream.
+func ZipStream(stream *streamx.Stream) ZipEntry {
+	return ZipEntry{
+		Name: stream.Filename,
+		Open: func() (io.ReadCloser, error) {
+			if closer, ok := stream.Data.(io.ReadCloser); ok {
+				return closer, nil
+			}
+			return io.NopCloser(stream.Data), nil
+		},
+	}
+}
+
+// Zip streams the entries as a zip attachment named filename, compressed on the fly without temporary
+// files. An entry failing to open ends the archive, the client receives a truncated file.
+//
+//	entries := make([]fiberhandler.ZipEntry, 0, len(attachments))
+//	for _, a := range attachments {
+//		entries = append(entries, fiberhandler.ZipEntry{Name: a.Name, Open: func() (io.ReadCloser, error) {
+//			return bucket.Open(ctx, a.Key)
+//		}})
+//	}
+//	return fiberhandler.Zip("attachments.zip", entries...), nil
+func Zip(filename string, entries ...ZipEntry) *StreamResult {
+	return ZipSeq(filename, slices.Values(entries))
+}
+
+// ZipSeq streams the entries of seq as a zip attachment named filename.
+func ZipSeq(filename string, entries iter.Seq[ZipEntry]) *StreamResult {
+	reader := &pipeReader{write: func(w io.Writer) error {
+		return writeZip(w, entries)
+	}}
+	return Attachment(&streamx.Stream{Data: reader, ContentType: MIMEApplicationZip, Filename: filename})
+}
+
+func writeZip(w io.Writer, entries iter.Seq[ZipEntry]) error {
+	archive := zip.NewWriter(w)
+	names := map[string]bool{}
+	now := time.Now()
+	for entry := range entries {
+		name := uniqueZipName(zipName(entry.Name), names)
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: entry.Modified}
+		if entry.Store {
+			header.Method = zip.Store
+		}
+		if header.Modified.IsZero() {
+			header.Modified = now
+		}
+
+		if err := writeZipEntry(archive, header, entry); err != nil {
+			return fmt.Errorf("zip entry %s: %w", name, err)
+		}
+	}
+	return archive.Close()
+}
+
+func writeZipEntry(archive *zip.Writer, header *zip.FileHeader, entry ZipEntry) error {
+	content, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	writer, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, content)
+	return err
+}
+
+// zipName keeps the entry inside the archive, without absolute paths nor "..".
+func zipName(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || name == "." {
+		return "file"
+	}
+	return name
+}
+
+// uniqueZipName suffixes a name already in the archive, "report.pdf" becomes "report (1).pdf".
+func uniqueZipName(name string, names map[string]bool) string {
+	unique := name
+	ext := path.Ext(name)
+	for i := 1; names[unique]; i++ {
+		unique = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	names[unique] = true
+	return unique
+}